@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// --- Authentication Strategies ---
+//
+// Providers never add credentials themselves: buildSearchURL and
+// buildSearchRequest produce credential-free requests, and the searcher's
+// AuthStrategy adds the token just before sending. This keeps tokens out of
+// every URL that gets logged or returned in a plan.
+
+// AuthStrategy attaches a token to an outgoing request.
+type AuthStrategy interface {
+	Apply(req *http.Request, token string) error
+}
+
+// HeaderAuth sends the token in a header, e.g. "Authorization: Bearer <token>".
+type HeaderAuth struct {
+	Header string
+	Prefix string // Prepended to the token, e.g. "Bearer "
+}
+
+// Apply implements AuthStrategy.
+func (a HeaderAuth) Apply(req *http.Request, token string) error {
+	req.Header.Set(a.Header, a.Prefix+token)
+	return nil
+}
+
+// QueryParamAuth sends the token as a URL query parameter.
+type QueryParamAuth struct {
+	Param string
+}
+
+// Apply implements AuthStrategy.
+func (a QueryParamAuth) Apply(req *http.Request, token string) error {
+	q := req.URL.Query()
+	q.Set(a.Param, token)
+	req.URL.RawQuery = q.Encode()
+	return nil
+}
+
+// BasicAuth sends a "username:password" token as HTTP basic credentials.
+type BasicAuth struct{}
+
+// Apply implements AuthStrategy.
+func (BasicAuth) Apply(req *http.Request, token string) error {
+	user, pass, ok := strings.Cut(token, ":")
+	if !ok {
+		return fmt.Errorf("invalid token format; expected 'username:password'")
+	}
+	req.SetBasicAuth(user, pass)
+	return nil
+}
+
+// authorize adds the searcher's credentials to req. Without a token or a
+// strategy, the request is sent anonymously.
+func (s *BaseRepoSearcher) authorize(req *http.Request) error {
+	if s.Token == "" || s.Auth == nil {
+		return nil
+	}
+	if err := s.Auth.Apply(req, s.Token); err != nil {
+		return fmt.Errorf("failed to authenticate request: %w", err)
+	}
+	return nil
+}
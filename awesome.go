@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// --- Awesome-List Drift ---
+//
+// Curated "awesome" lists rot: projects get archived, renamed or deleted,
+// and better ones appear. `rexplorer awesome <list.md>` re-checks every
+// repository the list links to and, given a query, suggests popular
+// repositories the list is missing.
+
+// awesomeLinkPattern matches repository links; the owner and name are the
+// first two path segments.
+var awesomeLinkPattern = regexp.MustCompile(`https?://([\w.-]+)/([\w.-]+)/([\w.-]+)`)
+
+// awesomeReservedOwners are first path segments that are site pages, not
+// owners (github.com/topics/..., github.com/sponsors/...).
+var awesomeReservedOwners = map[string]bool{
+	"topics": true, "sponsors": true, "orgs": true, "marketplace": true,
+	"features": true, "settings": true, "collections": true, "apps": true,
+}
+
+// awesomeLink is a repository linked from the list.
+type awesomeLink struct {
+	Line     int
+	Service  string
+	FullName string
+}
+
+// awesomeStatus is what became of a linked repository.
+type awesomeStatus struct {
+	awesomeLink
+	Dead     bool
+	Archived bool
+	// RenamedTo is the repository's current name, if it moved.
+	RenamedTo string
+	Summary   RepositorySummary
+	Err       error
+}
+
+// parseAwesomeList extracts the distinct repository links on known
+// providers, in order of appearance.
+func parseAwesomeList(r io.Reader) ([]awesomeLink, error) {
+	var links []awesomeLink
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		for _, m := range awesomeLinkPattern.FindAllStringSubmatch(scanner.Text(), -1) {
+			p, ok := providerForHost(m[1])
+			if !ok || awesomeReservedOwners[strings.ToLower(m[2])] {
+				continue
+			}
+			fullName := m[2] + "/" + strings.TrimSuffix(m[3], ".git")
+			key := p.Name + ":" + strings.ToLower(fullName)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			links = append(links, awesomeLink{Line: line, Service: p.Name, FullName: fullName})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read list: %w", err)
+	}
+	return links, nil
+}
+
+// checkAwesomeLinks looks up every link, with up to workers lookups at a
+// time. Results are in the order of links.
+func checkAwesomeLinks(ctx context.Context, links []awesomeLink, searcherFor func(service string) (*BaseRepoSearcher, error), workers int) []awesomeStatus {
+	statuses := make([]awesomeStatus, len(links))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range max(workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				statuses[i] = checkAwesomeLink(ctx, links[i], searcherFor)
+			}
+		}()
+	}
+	for i := range links {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return statuses
+}
+
+// checkAwesomeLink looks up one linked repository.
+func checkAwesomeLink(ctx context.Context, link awesomeLink, searcherFor func(service string) (*BaseRepoSearcher, error)) awesomeStatus {
+	status := awesomeStatus{awesomeLink: link}
+	s, err := searcherFor(link.Service)
+	if err != nil {
+		status.Err = err
+		return status
+	}
+	detail, err := s.fetchRepo(ctx, link.FullName, "", "")
+	switch {
+	case isNotFound(err):
+		status.Dead = true
+	case err != nil:
+		status.Err = err
+	default:
+		items := []RepositorySummary{detail.Summary}
+		markSuccessors(items)
+		status.Summary = items[0]
+		status.Archived = detail.Summary.IsArchived
+		if detail.Summary.PreviousFullName != "" {
+			status.RenamedTo = detail.Summary.FullName
+			noteRename(link.Service, link.FullName, detail.Summary, time.Now().UTC())
+		}
+	}
+	return status
+}
+
+// awesomeCandidates returns the found repositories the list doesn't have,
+// most starred first.
+func awesomeCandidates(found []RepositorySummary, statuses []awesomeStatus, top int) []RepositorySummary {
+	listed := make(map[string]bool)
+	for _, st := range statuses {
+		listed[strings.ToLower(st.FullName)] = true
+		if st.RenamedTo != "" {
+			listed[strings.ToLower(st.RenamedTo)] = true
+		}
+	}
+	var out []RepositorySummary
+	for _, item := range found {
+		key := strings.ToLower(item.FullName)
+		if listed[key] || item.IsArchived || item.IsFork {
+			continue
+		}
+		listed[key] = true
+		out = append(out, item)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Stars > out[j].Stars })
+	if top > 0 && len(out) > top {
+		out = out[:top]
+	}
+	return out
+}
+
+// runAwesome implements `rexplorer awesome <list.md>`.
+func runAwesome(args []string) error {
+	fs := flag.NewFlagSet("awesome", flag.ExitOnError)
+	query := fs.String("query", "", "Also search for this query and suggest popular repositories missing from the list")
+	across := fs.String("across", "github", "Service(s) to search for candidates: a name, a comma-separated list, or all")
+	pages := fs.Int("pages", 1, "Pages to fetch per candidate search")
+	top := fs.Int("top", 10, "Number of candidates to suggest")
+	workers := fs.Int("workers", 8, "Concurrent repository lookups")
+	timeout := fs.Duration("timeout", 10*time.Minute, "Timeout for all lookups and searches")
+	rest, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return fmt.Errorf("usage: rexplorer awesome [-query q] [-across list] <list.md>")
+	}
+
+	f, err := os.Open(rest[0])
+	if err != nil {
+		return fmt.Errorf("failed to open list: %w", err)
+	}
+	links, err := parseAwesomeList(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	log.Printf("Checking %d repositories linked from %s...", len(links), rest[0])
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	client := &http.Client{Timeout: 30 * time.Second}
+	var mu sync.Mutex
+	searchers := make(map[string]*BaseRepoSearcher)
+	searcherFor := func(service string) (*BaseRepoSearcher, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if b, ok := searchers[service]; ok {
+			return b, nil
+		}
+		searcher, err := newSearcher(service, client, true)
+		if err != nil {
+			return nil, err
+		}
+		b, _ := baseOf(searcher)
+		searchers[service] = b
+		return b, nil
+	}
+	statuses := checkAwesomeLinks(ctx, links, searcherFor, *workers)
+
+	var found []RepositorySummary
+	if *query != "" {
+		for _, name := range resolveServices(*across) {
+			searcher, err := newSearcher(name, client, false)
+			if err != nil {
+				log.Printf("Warning: skipping %s: %v", name, err)
+				continue
+			}
+			result, err := searcher.Search(ctx, *query, *pages)
+			if err != nil {
+				log.Printf("Warning: %s search failed: %v", name, err)
+				continue
+			}
+			found = append(found, result.Items...)
+		}
+	}
+	return writeAwesomeReport(os.Stdout, statuses, awesomeCandidates(found, statuses, *top), *query)
+}
+
+// writeAwesomeReport prints the entries needing attention and the
+// candidates.
+func writeAwesomeReport(out io.Writer, statuses []awesomeStatus, candidates []RepositorySummary, query string) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	problems := 0
+	fmt.Fprintln(w, "LINE\tREPOSITORY\tSERVICE\tPROBLEM")
+	for _, st := range statuses {
+		var problem string
+		switch {
+		case st.Err != nil:
+			problem = "lookup failed: " + st.Err.Error()
+		case st.Dead:
+			problem = "dead (not found)"
+		case st.RenamedTo != "" && st.Archived:
+			problem = "renamed to " + st.RenamedTo + ", archived"
+		case st.RenamedTo != "":
+			problem = "renamed to " + st.RenamedTo
+		case st.Archived:
+			problem = "archived"
+			if st.Summary.SuccessorURL != "" {
+				problem += ", continued at " + st.Summary.SuccessorURL
+			}
+		default:
+			continue
+		}
+		problems++
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", st.Line, st.FullName, st.Service, problem)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "\n%d of %d linked repositories need attention.\n", problems, len(statuses))
+
+	if query == "" {
+		return nil
+	}
+	fmt.Fprintf(out, "\nPopular repositories for %q not on the list:\n", query)
+	w = tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "REPOSITORY\tPROVIDER\tSTARS\tDESCRIPTION")
+	for _, c := range candidates {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", c.FullName, c.Provider, formatCount(c.Stars, false), truncate(c.Description, 60))
+	}
+	return w.Flush()
+}
+
+// truncate shortens s to at most n runes, marking the cut.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// --- Backstage Export ---
+//
+// The backstage sink (-also-write backstage:catalog-info.yaml) writes a
+// Backstage Component entity for every repository, with its description,
+// links, source location and tags from its topics and language. Register
+// the file as a catalog location to import them all at once.
+
+// backstageNameLimit is the longest entity name or tag Backstage accepts.
+const backstageNameLimit = 63
+
+// backstageInvalid matches runs of characters entity names may not contain.
+var backstageInvalid = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// backstageSeparators matches separators that aren't between two letters
+// or digits, as entity names require.
+var backstageSeparators = regexp.MustCompile(`[._-]{2,}`)
+
+// backstageTagInvalid matches runs of characters tags may not contain.
+var backstageTagInvalid = regexp.MustCompile(`[^a-z0-9:+#]+`)
+
+// backstageName makes s a valid entity name: letters and digits, separated
+// by single dashes, underscores or dots.
+func backstageName(s string) string {
+	name := backstageSeparators.ReplaceAllString(backstageInvalid.ReplaceAllString(s, "-"), "-")
+	if len(name) > backstageNameLimit {
+		name = name[:backstageNameLimit]
+	}
+	name = strings.Trim(name, "-_.")
+	if name == "" {
+		return "unnamed"
+	}
+	return name
+}
+
+// backstageTag makes s a valid tag: lowercase words joined by dashes.
+func backstageTag(s string) string {
+	tag := strings.Trim(backstageTagInvalid.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	if len(tag) > backstageNameLimit {
+		tag = strings.TrimRight(tag[:backstageNameLimit], "-")
+	}
+	return tag
+}
+
+// backstageSlugAnnotations are the annotations the providers' Backstage
+// plugins find a repository by, for repositories on their public hosts.
+var backstageSlugAnnotations = map[string]string{
+	"github.com":    "github.com/project-slug",
+	"gitlab.com":    "gitlab.com/project-slug",
+	"bitbucket.org": "bitbucket.org/repo-slug",
+}
+
+// writeBackstage writes the results as Backstage catalog entities, one
+// YAML document each.
+func writeBackstage(w io.Writer, items []RepositorySummary, source string) error {
+	// Repositories with the same name in different namespaces are told
+	// apart by the namespace.
+	counts := make(map[string]int)
+	for _, s := range items {
+		_, name, _ := cutLast(s.FullName, "/")
+		counts[strings.ToLower(backstageName(name))]++
+	}
+	used := make(map[string]bool)
+
+	fmt.Fprintf(w, "# Backstage catalog entities for %d repositories from %s.\n", len(items), source)
+	for _, s := range items {
+		owner, repo, _ := cutLast(s.FullName, "/")
+		name := backstageName(repo)
+		if counts[strings.ToLower(name)] > 1 {
+			name = backstageName(strings.ReplaceAll(s.FullName, "/", "-"))
+		}
+		base := name
+		for i := 2; used[strings.ToLower(name)]; i++ {
+			suffix := fmt.Sprintf("-%d", i)
+			name = strings.TrimRight(base[:min(len(base), backstageNameLimit-len(suffix))], "-_.") + suffix
+		}
+		used[strings.ToLower(name)] = true
+
+		var tags []string
+		for _, label := range append(slices.Clone(s.Topics), s.Language) {
+			if tag := backstageTag(label); tag != "" && tag != "unknown" && !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		lifecycle := "production"
+		if s.IsArchived {
+			lifecycle = "deprecated"
+		}
+
+		fmt.Fprintln(w, "---")
+		fmt.Fprintln(w, "apiVersion: backstage.io/v1alpha1")
+		fmt.Fprintln(w, "kind: Component")
+		fmt.Fprintln(w, "metadata:")
+		fmt.Fprintf(w, "  name: %s\n", yamlString(name))
+		fmt.Fprintf(w, "  title: %s\n", yamlString(s.FullName))
+		if s.Description != "" {
+			fmt.Fprintf(w, "  description: %s\n", yamlString(s.Description))
+		}
+		fmt.Fprintln(w, "  annotations:")
+		fmt.Fprintf(w, "    backstage.io/source-location: %s\n", yamlString("url:"+strings.TrimSuffix(s.URL, "/")+"/"))
+		if u, err := url.Parse(s.URL); err == nil {
+			if key, ok := backstageSlugAnnotations[strings.TrimPrefix(strings.ToLower(u.Host), "www.")]; ok {
+				fmt.Fprintf(w, "    %s: %s\n", key, yamlString(s.FullName))
+			}
+		}
+		if len(tags) > 0 {
+			fmt.Fprintln(w, "  tags:")
+			for _, tag := range tags {
+				fmt.Fprintf(w, "    - %s\n", yamlString(tag))
+			}
+		}
+		fmt.Fprintln(w, "  links:")
+		fmt.Fprintf(w, "    - url: %s\n      title: Repository\n", yamlString(s.URL))
+		if s.Homepage != "" {
+			fmt.Fprintf(w, "    - url: %s\n      title: Homepage\n", yamlString(s.Homepage))
+		}
+		if s.DocsURL != "" && s.DocsURL != s.Homepage {
+			fmt.Fprintf(w, "    - url: %s\n      title: Documentation\n", yamlString(s.DocsURL))
+		}
+		fmt.Fprintln(w, "spec:")
+		fmt.Fprintln(w, "  type: service")
+		fmt.Fprintf(w, "  lifecycle: %s\n", lifecycle)
+		fmt.Fprintf(w, "  owner: %s\n", yamlString(backstageOwner(owner)))
+	}
+	return nil
+}
+
+// backstageOwner names the group owning a repository's component after its
+// namespace, or "unknown" for repositories without one.
+func backstageOwner(namespace string) string {
+	if namespace == "" {
+		return "unknown"
+	}
+	return backstageName(strings.ReplaceAll(namespace, "/", "-"))
+}
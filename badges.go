@@ -0,0 +1,232 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// --- Badges ---
+//
+// Watched repositories can be shown as shields.io-style SVG badges in
+// wikis and READMEs: stars, last activity and a health score, from what the
+// last `watch refresh` saw. `rexplorer watch badges -out dir/` writes them
+// as dir/<service>/<full_name>/<kind>.svg, and serve mode serves the same
+// paths under /badges/ without an API key, since image links can't carry
+// one; only watched repositories have badges.
+
+// badgeKinds are the badges made for each watched repository.
+var badgeKinds = []string{"stars", "activity", "health"}
+
+// Badge colors, shields.io's palette.
+const (
+	badgeGreen       = "#4c1"
+	badgeYellowGreen = "#a4a61d"
+	badgeYellow      = "#dfb317"
+	badgeOrange      = "#fe7d37"
+	badgeRed         = "#e05d44"
+	badgeBlue        = "#007ec6"
+	badgeGrey        = "#9f9f9f"
+)
+
+// badge is one label and value pair.
+type badge struct {
+	Label string
+	Value string
+	Color string
+}
+
+// healthScore rates a repository from 0 to 100: recent activity counts for
+// 50 points, a known license for 20, a description or topics for 10, and a
+// small open issue load relative to its stars for 20. Archived
+// repositories score 0.
+func healthScore(s RepositorySummary, now time.Time) int {
+	if s.IsArchived {
+		return 0
+	}
+	score := 0.0
+	if updated, ok := parseTimestamp(s.UpdatedAt); ok {
+		// Full marks within a month, none after two years.
+		days := now.Sub(updated).Hours() / 24
+		score += 50 * math.Max(0, math.Min(1, (730-days)/(730-30)))
+	}
+	if spdxLicense(s.License) != "NOASSERTION" {
+		score += 20
+	}
+	if s.Description != "" || len(s.Topics) > 0 {
+		score += 10
+	}
+	// Full marks up to one open issue per 20 stars, none from one per 2.
+	ratio := float64(s.OpenIssuesCount) / float64(max(s.Stars, 1))
+	score += 20 * math.Max(0, math.Min(1, (0.5-ratio)/(0.5-0.05)))
+	return int(math.Round(score))
+}
+
+// makeBadge builds the badge of the given kind for a repository.
+func makeBadge(kind string, s RepositorySummary, now time.Time) (badge, error) {
+	switch kind {
+	case "stars":
+		return badge{"stars", formatCount(s.Stars, true), badgeBlue}, nil
+	case "activity":
+		if s.IsArchived {
+			return badge{"last activity", "archived", badgeGrey}, nil
+		}
+		updated, ok := parseTimestamp(s.UpdatedAt)
+		if !ok {
+			return badge{"last activity", "unknown", badgeGrey}, nil
+		}
+		age := now.Sub(updated)
+		value := formatAge(age) + " ago"
+		if age < 24*time.Hour {
+			value = "today"
+		}
+		return badge{"last activity", value, ageColor(age)}, nil
+	case "health":
+		score := healthScore(s, now)
+		return badge{"health", fmt.Sprintf("%d%%", score), scoreColor(score)}, nil
+	default:
+		return badge{}, fmt.Errorf("unknown badge %q; must be one of %s", kind, strings.Join(badgeKinds, ", "))
+	}
+}
+
+// ageColor colors a last activity badge.
+func ageColor(age time.Duration) string {
+	days := age.Hours() / 24
+	switch {
+	case days < 30:
+		return badgeGreen
+	case days < 90:
+		return badgeYellowGreen
+	case days < 365:
+		return badgeYellow
+	case days < 730:
+		return badgeOrange
+	}
+	return badgeRed
+}
+
+// scoreColor colors a health badge.
+func scoreColor(score int) string {
+	switch {
+	case score >= 80:
+		return badgeGreen
+	case score >= 60:
+		return badgeYellowGreen
+	case score >= 40:
+		return badgeYellow
+	case score >= 20:
+		return badgeOrange
+	}
+	return badgeRed
+}
+
+// textWidth estimates the width of text in 11px Verdana, as shields.io
+// lays it out.
+func textWidth(s string) int {
+	return utf8.RuneCountInString(s)*7 + 10
+}
+
+// svg renders the badge in the flat shields.io style.
+func (b badge) svg() []byte {
+	lw, vw := textWidth(b.Label), textWidth(b.Value)
+	label, value := html.EscapeString(b.Label), html.EscapeString(b.Value)
+	return fmt.Appendf(nil, `<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[3]s: %[4]s">
+<title>%[3]s: %[4]s</title>
+<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)"><rect width="%[2]d" height="20" fill="#555"/><rect x="%[2]d" width="%[5]d" height="20" fill="%[6]s"/><rect width="%[1]d" height="20" fill="url(#s)"/></g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="%[7]d" y="15" fill="#010101" fill-opacity=".3">%[3]s</text><text x="%[7]d" y="14">%[3]s</text>
+<text x="%[8]d" y="15" fill="#010101" fill-opacity=".3">%[4]s</text><text x="%[8]d" y="14">%[4]s</text>
+</g>
+</svg>
+`, lw+vw, lw, label, value, vw, b.Color, lw/2, lw+vw/2)
+}
+
+// badgePath is where a badge is kept, relative to the badge directory or
+// /badges/.
+func badgePath(service, fullName, kind string) string {
+	return strings.ToLower(service) + "/" + fullName + "/" + kind + ".svg"
+}
+
+// writeBadges writes the badges of every watched repository under dir, and
+// returns how many it wrote. Repositories not refreshed yet are skipped.
+func writeBadges(dir string, entries []watchEntry, now time.Time) (int, error) {
+	written := 0
+	for _, e := range entries {
+		if e.Summary == nil {
+			continue
+		}
+		for _, kind := range badgeKinds {
+			b, err := makeBadge(kind, *e.Summary, now)
+			if err != nil {
+				return written, err
+			}
+			path := filepath.Join(dir, filepath.FromSlash(badgePath(e.Service, e.FullName, kind)))
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return written, fmt.Errorf("failed to create badge directory: %w", err)
+			}
+			if err := os.WriteFile(path, b.svg(), 0644); err != nil {
+				return written, fmt.Errorf("failed to write %s: %w", path, err)
+			}
+			written++
+		}
+	}
+	return written, nil
+}
+
+// runWatchBadges implements `rexplorer watch badges`.
+func runWatchBadges(args []string, entries []watchEntry) error {
+	fs := flag.NewFlagSet("watch badges", flag.ExitOnError)
+	out := fs.String("out", "badges", "Directory to write the badges to")
+	fs.Parse(args)
+	n, err := writeBadges(*out, entries, time.Now())
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("no watched repository has been refreshed yet; run rexplorer watch refresh first")
+	}
+	fmt.Printf("Wrote %d badges to %s\n", n, *out)
+	return nil
+}
+
+// handleBadge serves GET /badges/<service>/<full_name>/<kind>.svg for
+// watched repositories.
+func (s *server) handleBadge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "use GET", http.StatusMethodNotAllowed)
+		return
+	}
+	rest := strings.TrimPrefix(r.URL.Path, "/badges/")
+	slash := strings.LastIndex(rest, "/")
+	kind, ok := strings.CutSuffix(rest[slash+1:], ".svg")
+	service, fullName, _ := strings.Cut(rest[:max(slash, 0)], "/")
+	if !ok || !slices.Contains(badgeKinds, kind) || fullName == "" {
+		http.NotFound(w, r)
+		return
+	}
+	entries, err := loadWatchList()
+	if err != nil {
+		http.Error(w, "watch list unavailable", http.StatusInternalServerError)
+		return
+	}
+	i := findWatch(entries, service, fullName)
+	if i < 0 || entries[i].Summary == nil {
+		http.NotFound(w, r)
+		return
+	}
+	b, _ := makeBadge(kind, *entries[i].Summary, time.Now())
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Cache-Control", "max-age=3600")
+	w.Write(b.svg())
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// --- Blocklist ---
+//
+// The blocklist file holds one pattern per line; blank lines and lines
+// starting with '#' are ignored. A pattern is one of:
+//
+//	owner          every repository of that owner
+//	owner/repo     that repository
+//	/regex/        any repository whose full name matches the regex
+//
+// Matching is case-insensitive.
+
+// configDir returns the directory holding rexplorer's configuration files.
+func configDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "rexplorer"), nil
+}
+
+// blocklistPath returns the location of the blocklist file.
+func blocklistPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "blocklist"), nil
+}
+
+// blocklist is a parsed set of block patterns.
+type blocklist struct {
+	owners  map[string]bool
+	repos   map[string]bool
+	regexes []*regexp.Regexp
+}
+
+// add parses a single pattern into b.
+func (b *blocklist) add(pattern string) error {
+	switch {
+	case len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/"):
+		re, err := regexp.Compile("(?i)" + pattern[1:len(pattern)-1])
+		if err != nil {
+			return fmt.Errorf("invalid regex %s: %w", pattern, err)
+		}
+		b.regexes = append(b.regexes, re)
+	case strings.Contains(pattern, "/"):
+		b.repos[strings.ToLower(pattern)] = true
+	default:
+		b.owners[strings.ToLower(pattern)] = true
+	}
+	return nil
+}
+
+// Blocks reports whether the repository is on the blocklist.
+func (b *blocklist) Blocks(s RepositorySummary) bool {
+	name := strings.ToLower(s.FullName)
+	if b.repos[name] {
+		return true
+	}
+	if owner, _, ok := strings.Cut(name, "/"); ok && b.owners[owner] {
+		return true
+	}
+	for _, re := range b.regexes {
+		if re.MatchString(s.FullName) {
+			return true
+		}
+	}
+	return false
+}
+
+// readBlockPatterns returns the raw patterns in the blocklist file.
+// A missing file is an empty blocklist.
+func readBlockPatterns(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open blocklist: %w", err)
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, scanner.Err()
+}
+
+// loadBlocklist reads and parses the blocklist file.
+func loadBlocklist() (*blocklist, error) {
+	path, err := blocklistPath()
+	if err != nil {
+		return nil, err
+	}
+	patterns, err := readBlockPatterns(path)
+	if err != nil {
+		return nil, err
+	}
+	known, err := loadRenames()
+	if err != nil {
+		return nil, err
+	}
+	b := &blocklist{owners: map[string]bool{}, repos: map[string]bool{}}
+	for _, p := range patterns {
+		if err := b.add(p); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		// A blocked repository stays blocked after it is renamed.
+		for _, renamed := range known.targets(p) {
+			b.repos[strings.ToLower(renamed)] = true
+		}
+	}
+	return b, nil
+}
+
+// runBlock implements `rexplorer block add|remove|list`.
+func runBlock(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: rexplorer block <add|remove|list> [pattern]")
+	}
+	path, err := blocklistPath()
+	if err != nil {
+		return err
+	}
+	patterns, err := readBlockPatterns(path)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "list":
+		for _, p := range patterns {
+			fmt.Println(p)
+		}
+		return nil
+	case "add":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: rexplorer block add <owner|owner/repo|/regex/>")
+		}
+		// Validate before saving, so a bad regex never lands in the file.
+		probe := &blocklist{owners: map[string]bool{}, repos: map[string]bool{}}
+		if err := probe.add(args[1]); err != nil {
+			return err
+		}
+		for _, p := range patterns {
+			if p == args[1] {
+				return nil // Already blocked
+			}
+		}
+		patterns = append(patterns, args[1])
+	case "remove":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: rexplorer block remove <pattern>")
+		}
+		kept := patterns[:0]
+		for _, p := range patterns {
+			if p != args[1] {
+				kept = append(kept, p)
+			}
+		}
+		if len(kept) == len(patterns) {
+			return fmt.Errorf("%q is not in the blocklist", args[1])
+		}
+		patterns = kept
+	default:
+		return fmt.Errorf("unknown block command %q; must be add, remove or list", args[0])
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	content := "# rexplorer blocklist: owner, owner/repo or /regex/ per line\n" + strings.Join(patterns, "\n") + "\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write blocklist: %w", err)
+	}
+	return nil
+}
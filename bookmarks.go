@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// --- Bookmarks ---
+
+// bookmarksFile is the store document holding all bookmarks.
+const bookmarksFile = "bookmarks.json"
+
+// Bookmark is a shortlisted repository with the user's notes.
+type Bookmark struct {
+	FullName string    `json:"full_name"`
+	Service  string    `json:"service,omitempty"`
+	Note     string    `json:"note,omitempty"`
+	Tags     []string  `json:"tags,omitempty"`
+	AddedAt  time.Time `json:"added_at"`
+}
+
+// loadBookmarks reads all bookmarks from the local store.
+func loadBookmarks() ([]Bookmark, error) {
+	var bookmarks []Bookmark
+	if err := readStoreJSON(bookmarksFile, &bookmarks); err != nil {
+		return nil, err
+	}
+	known, err := loadRenames()
+	if err != nil {
+		return nil, err
+	}
+	for i, b := range bookmarks {
+		if b.Service != "" {
+			bookmarks[i].FullName = known.resolve(b.Service, b.FullName)
+		}
+	}
+	return bookmarks, nil
+}
+
+// runBookmark implements `rexplorer bookmark add|remove|list|export`.
+func runBookmark(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: rexplorer bookmark <add|remove|list|export> [args]")
+	}
+	bookmarks, err := loadBookmarks()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "add":
+		fs := flag.NewFlagSet("bookmark add", flag.ExitOnError)
+		note := fs.String("note", "", "Free-form note")
+		tags := fs.String("tags", "", "Comma-separated tags")
+		service := fs.String("service", "", "Service the repository lives on (e.g. github)")
+		rest, err := parseInterspersed(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		if len(rest) != 1 {
+			return fmt.Errorf("usage: rexplorer bookmark add <full_name> [-note text] [-tags a,b] [-service name]")
+		}
+		b := Bookmark{FullName: rest[0], Service: *service, Note: *note, Tags: splitList(*tags), AddedAt: time.Now().UTC()}
+		// Re-adding a bookmark updates it in place.
+		replaced := false
+		for i := range bookmarks {
+			if strings.EqualFold(bookmarks[i].FullName, b.FullName) {
+				b.AddedAt = bookmarks[i].AddedAt
+				bookmarks[i] = b
+				replaced = true
+			}
+		}
+		if !replaced {
+			bookmarks = append(bookmarks, b)
+		}
+		return writeStoreJSON(bookmarksFile, bookmarks)
+
+	case "remove":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: rexplorer bookmark remove <full_name>")
+		}
+		kept := bookmarks[:0]
+		for _, b := range bookmarks {
+			if !strings.EqualFold(b.FullName, args[1]) {
+				kept = append(kept, b)
+			}
+		}
+		if len(kept) == len(bookmarks) {
+			return fmt.Errorf("%s is not bookmarked", args[1])
+		}
+		return writeStoreJSON(bookmarksFile, kept)
+
+	case "list":
+		fs := flag.NewFlagSet("bookmark list", flag.ExitOnError)
+		tag := fs.String("tag", "", "Only list bookmarks with this tag")
+		fs.Parse(args[1:])
+		for _, b := range bookmarks {
+			if *tag != "" && !containsFold(b.Tags, *tag) {
+				continue
+			}
+			fmt.Printf("%s", b.FullName)
+			if len(b.Tags) > 0 {
+				fmt.Printf(" [%s]", strings.Join(b.Tags, ", "))
+			}
+			fmt.Println()
+			if b.Note != "" {
+				fmt.Printf("   %s\n", b.Note)
+			}
+		}
+		return nil
+
+	case "export":
+		fs := flag.NewFlagSet("bookmark export", flag.ExitOnError)
+		format := fs.String("format", "json", "Export format (json, markdown or cyclonedx)")
+		out := fs.String("o", "", "Output file (default: stdout)")
+		fs.Parse(args[1:])
+		w := io.Writer(os.Stdout)
+		if *out != "" {
+			f, err := os.Create(*out)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", *out, err)
+			}
+			defer f.Close()
+			w = f
+		}
+		return exportBookmarks(w, bookmarks, *format)
+
+	default:
+		return fmt.Errorf("unknown bookmark command %q; must be add, remove, list or export", args[0])
+	}
+}
+
+// exportBookmarks writes bookmarks as JSON, a markdown list or a CycloneDX
+// BOM.
+func exportBookmarks(w io.Writer, bookmarks []Bookmark, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(bookmarks)
+	case "markdown", "md":
+		fmt.Fprintln(w, "# Bookmarks")
+		fmt.Fprintln(w)
+		for _, b := range bookmarks {
+			line := "- **" + b.FullName + "**"
+			if len(b.Tags) > 0 {
+				line += " `" + strings.Join(b.Tags, "` `") + "`"
+			}
+			if b.Note != "" {
+				line += ": " + b.Note
+			}
+			fmt.Fprintln(w, line)
+		}
+		return nil
+	case "cyclonedx":
+		return writeBookmarksCycloneDX(w, bookmarks)
+	default:
+		return fmt.Errorf("unknown export format %q; must be json, markdown or cyclonedx", format)
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httputil"
+	"sync"
+	"time"
+)
+
+// --- Response Cache ---
+
+// ResponseCache is an in-memory LRU cache of successful GET responses with a
+// time-to-live. One cache can be shared by several searchers (see
+// Middleware) and is safe for concurrent use.
+type ResponseCache struct {
+	size int
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	order   *list.List // Most recently used at the front
+	entries map[string]*list.Element
+	hits    int
+	misses  int
+}
+
+// cacheEntry is one cached response, stored in wire format so every hit
+// gets its own readable body.
+type cacheEntry struct {
+	key     string
+	raw     []byte
+	expires time.Time
+}
+
+// NewResponseCache creates a cache holding at most size responses, each for
+// at most ttl.
+func NewResponseCache(size int, ttl time.Duration) *ResponseCache {
+	return &ResponseCache{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// cacheKey identifies a request. Credentials are part of the key (hashed,
+// never stored) so a response is only reused for the same identity.
+func cacheKey(req *http.Request) string {
+	h := sha256.New()
+	for _, name := range []string{"Authorization", "Private-Token", "Accept"} {
+		h.Write([]byte(name + ": " + req.Header.Get(name) + "\n"))
+	}
+	return req.URL.String() + "\n" + hex.EncodeToString(h.Sum(nil))
+}
+
+// get returns a fresh copy of a cached response, if one is still valid.
+func (c *ResponseCache) get(key string, req *http.Request, now time.Time) (*http.Response, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	entry := el.Value.(*cacheEntry)
+	if now.After(entry.expires) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		c.misses++
+		return nil, false
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(entry.raw)), req)
+	if err != nil {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	c.hits++
+	return resp, true
+}
+
+// put stores a response, evicting the least recently used entries beyond
+// the size limit.
+func (c *ResponseCache) put(key string, raw []byte, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		el.Value = &cacheEntry{key: key, raw: raw, expires: now.Add(c.ttl)}
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, raw: raw, expires: now.Add(c.ttl)})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// Stats returns the number of cache hits and misses so far.
+func (c *ResponseCache) Stats() (hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// Middleware returns middleware that answers repeated GET requests from the
+// cache. Only 200 responses are stored; everything else goes through. A
+// request with "Cache-Control: no-cache" skips the lookup but still
+// refreshes the entry.
+func (c *ResponseCache) Middleware() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Method != http.MethodGet || c.size <= 0 {
+				return next.RoundTrip(req)
+			}
+			key := cacheKey(req)
+			if req.Header.Get("Cache-Control") != "no-cache" {
+				if resp, ok := c.get(key, req, time.Now()); ok {
+					return resp, nil
+				}
+			}
+
+			resp, err := next.RoundTrip(req)
+			if err != nil || resp.StatusCode != http.StatusOK {
+				return resp, err
+			}
+			raw, err := httputil.DumpResponse(resp, true)
+			if err != nil {
+				return nil, err
+			}
+			c.put(key, raw, time.Now())
+			return resp, nil // DumpResponse leaves the body readable
+		})
+	}
+}
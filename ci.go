@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+// --- CI Detection ---
+//
+// Whether a project runs automated tests is a useful quality filter, and the
+// configuration files give it away. The ci enricher lists the repository's
+// root (and the few directories CI configs live in) instead of probing each
+// file, to keep the request count down.
+
+// ciConfigs maps each CI system to the path that reveals it. A directory
+// path must exist; its contents aren't checked.
+var ciConfigs = []struct {
+	Name, Path string
+}{
+	{"GitHub Actions", ".github/workflows"},
+	{"GitLab CI", ".gitlab-ci.yml"},
+	{"Bitbucket Pipelines", "bitbucket-pipelines.yml"},
+	{"Gitea Actions", ".gitea/workflows"},
+	{"Forgejo Actions", ".forgejo/workflows"},
+	{"Travis CI", ".travis.yml"},
+	{"CircleCI", ".circleci/config.yml"},
+	{"Azure Pipelines", "azure-pipelines.yml"},
+	{"Jenkins", "Jenkinsfile"},
+}
+
+// contentsLister is implemented by providers that can list a directory of a
+// repository. dir is "" for the root.
+type contentsLister interface {
+	contentsURL(fullName, branch, dir string) string
+	// parseContents returns the names (not paths) of the directory entries.
+	parseContents(body io.Reader) ([]string, error)
+}
+
+// enrichCI fills in CISystems.
+func enrichCI(ctx context.Context, s *BaseRepoSearcher, item *RepositorySummary) error {
+	cl, ok := s.implementation.(contentsLister)
+	if !ok {
+		return errUnsupported
+	}
+	branch := item.DefaultBranch
+	if branch == "" {
+		branch = "HEAD"
+	}
+	listings := make(map[string]map[string]bool)
+	list := func(dir string) (map[string]bool, error) {
+		if names, ok := listings[dir]; ok {
+			return names, nil
+		}
+		names := make(map[string]bool)
+		body, err := s.fetchDetail(ctx, cl.contentsURL(item.FullName, branch, dir))
+		if err != nil && !isNotFound(err) {
+			return nil, err
+		}
+		if err == nil {
+			entries, err := cl.parseContents(body)
+			body.Close()
+			if err != nil {
+				return nil, err
+			}
+			for _, name := range entries {
+				names[name] = true
+			}
+		}
+		listings[dir] = names
+		return names, nil
+	}
+
+	// has reports whether p exists, listing each parent directory only if
+	// its own parent has it.
+	var has func(p string) (bool, error)
+	has = func(p string) (bool, error) {
+		dir, name := path.Split(p)
+		dir = strings.TrimSuffix(dir, "/")
+		if dir != "" {
+			if ok, err := has(dir); !ok || err != nil {
+				return false, err
+			}
+		}
+		names, err := list(dir)
+		return names[name], err
+	}
+
+	var systems []string
+	for _, c := range ciConfigs {
+		ok, err := has(c.Path)
+		if err != nil {
+			return err
+		}
+		if ok {
+			systems = append(systems, c.Name)
+		}
+	}
+	sort.Strings(systems)
+	item.CISystems = systems
+	return nil
+}
+
+// contentsURL implements contentsLister.
+func (g *GitHubSearcher) contentsURL(fullName, branch, dir string) string {
+	return g.BaseURL + "/repos/" + fullName + "/contents/" + dir + "?ref=" + url.QueryEscape(branch)
+}
+
+// parseContents implements contentsLister.
+func (g *GitHubSearcher) parseContents(body io.Reader) ([]string, error) {
+	return parseContentsArray(body, "GitHub")
+}
+
+// contentsURL implements contentsLister. Gitee mirrors GitHub's API.
+func (g *GiteeSearcher) contentsURL(fullName, branch, dir string) string {
+	return g.BaseURL + "/repos/" + fullName + "/contents/" + dir + "?ref=" + url.QueryEscape(branch)
+}
+
+// parseContents implements contentsLister.
+func (g *GiteeSearcher) parseContents(body io.Reader) ([]string, error) {
+	return parseContentsArray(body, "Gitee")
+}
+
+// contentsURL implements contentsLister. GitCode mirrors GitHub's API.
+func (g *GitCodeSearcher) contentsURL(fullName, branch, dir string) string {
+	return g.BaseURL + "/repos/" + fullName + "/contents/" + dir + "?ref=" + url.QueryEscape(branch)
+}
+
+// parseContents implements contentsLister.
+func (g *GitCodeSearcher) parseContents(body io.Reader) ([]string, error) {
+	return parseContentsArray(body, "GitCode")
+}
+
+// parseContentsArray decodes a GitHub-style directory listing.
+func parseContentsArray(body io.Reader, source string) ([]string, error) {
+	var entries []struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s contents: %w", source, err)
+	}
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Name
+	}
+	return names, nil
+}
+
+// contentsURL implements contentsLister using the repository tree.
+func (g *GitLabSearcher) contentsURL(fullName, branch, dir string) string {
+	q := url.Values{"ref": {branch}, "per_page": {"100"}}
+	if dir != "" {
+		q.Set("path", dir)
+	}
+	return g.BaseURL + "/projects/" + url.PathEscape(fullName) + "/repository/tree?" + q.Encode()
+}
+
+// parseContents implements contentsLister.
+func (g *GitLabSearcher) parseContents(body io.Reader) ([]string, error) {
+	return parseContentsArray(body, "GitLab")
+}
+
+// contentsURL implements contentsLister. Bitbucket lists a directory when
+// the source path ends in a slash.
+func (b *BitbucketSearcher) contentsURL(fullName, branch, dir string) string {
+	if dir != "" {
+		dir += "/"
+	}
+	return b.BaseURL + "/repositories/" + fullName + "/src/" + url.PathEscape(branch) + "/" + dir + "?pagelen=100"
+}
+
+// parseContents implements contentsLister.
+func (b *BitbucketSearcher) parseContents(body io.Reader) ([]string, error) {
+	var resp struct {
+		Values []struct {
+			Path string `json:"path"`
+		} `json:"values"`
+	}
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal Bitbucket contents: %w", err)
+	}
+	names := make([]string, len(resp.Values))
+	for i, v := range resp.Values {
+		names[i] = path.Base(v.Path)
+	}
+	return names, nil
+}
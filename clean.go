@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// --- Description Cleaning ---
+//
+// Descriptions often carry emoji, HTML or markdown that break the console
+// layout and CSV consumers. With -clean-descriptions (or
+// "clean_descriptions" in the config file) the human-readable outputs and
+// CSV show cleaned descriptions; the JSON output keeps the originals.
+
+// cleaningKinds are the accepted -clean-descriptions values, besides all
+// and none.
+var cleaningKinds = []string{"emoji", "html", "markdown"}
+
+// descriptionCleaner strips the selected kinds of markup from descriptions.
+// The zero value leaves them alone.
+type descriptionCleaner struct {
+	Emoji, HTML, Markdown bool
+}
+
+// parseCleaning resolves a list of cleaningKinds, or all or none.
+func parseCleaning(kinds []string) (descriptionCleaner, error) {
+	var c descriptionCleaner
+	for _, kind := range kinds {
+		switch strings.ToLower(kind) {
+		case "all":
+			c = descriptionCleaner{Emoji: true, HTML: true, Markdown: true}
+		case "none":
+			c = descriptionCleaner{}
+		case "emoji":
+			c.Emoji = true
+		case "html":
+			c.HTML = true
+		case "markdown", "md":
+			c.Markdown = true
+		default:
+			return c, fmt.Errorf("unknown cleaning %q; must be all, none or a list of %s", kind, strings.Join(cleaningKinds, ", "))
+		}
+	}
+	return c, nil
+}
+
+var (
+	htmlTag         = regexp.MustCompile(`<[^>]*>`)
+	markdownImage   = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	markdownLink    = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`)
+	markdownMarks   = regexp.MustCompile("\\*\\*|__|~~|`")
+	markdownEmph    = regexp.MustCompile(`(^|[^\w*])[*_]([^*_\s](?:[^*_]*[^*_\s])?)[*_]([^\w*]|$)`)
+	markdownHeading = regexp.MustCompile(`(?m)^\s*#{1,6}\s+`)
+	emojiShortcode  = regexp.MustCompile(`:[a-z0-9_+-]+:`)
+)
+
+// enabled reports whether any cleaning is selected.
+func (c descriptionCleaner) enabled() bool {
+	return c.Emoji || c.HTML || c.Markdown
+}
+
+// clean returns s with the selected markup removed and whitespace collapsed.
+func (c descriptionCleaner) clean(s string) string {
+	if !c.enabled() {
+		return s
+	}
+	if c.HTML {
+		s = html.UnescapeString(htmlTag.ReplaceAllString(s, " "))
+	}
+	if c.Markdown {
+		s = markdownImage.ReplaceAllString(s, "$1")
+		s = markdownLink.ReplaceAllString(s, "$1")
+		s = markdownHeading.ReplaceAllString(s, "")
+		s = markdownMarks.ReplaceAllString(s, "")
+		s = markdownEmph.ReplaceAllString(s, "$1$2$3")
+	}
+	if c.Emoji {
+		s = emojiShortcode.ReplaceAllString(s, "")
+		s = strings.Map(func(r rune) rune {
+			if isEmoji(r) {
+				return -1
+			}
+			return r
+		}, s)
+	}
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// isEmoji reports whether r is an emoji, or a joiner, selector or tag
+// character used to build one.
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF: // Pictographs, emoticons, flags, ...
+		return true
+	case r >= 0x2600 && r <= 0x27BF: // Miscellaneous symbols, dingbats
+		return true
+	case r >= 0x2B00 && r <= 0x2BFF: // Stars, arrows
+		return true
+	case r >= 0xE0020 && r <= 0xE007F: // Tag sequences
+		return true
+	case r == 0x200D, r == 0xFE0F, r == 0x20E3: // ZWJ, emoji presentation, keycap
+		return true
+	}
+	return false
+}
+
+// apply returns a copy of items with cleaned descriptions, or items itself
+// if no cleaning is selected.
+func (c descriptionCleaner) apply(items []RepositorySummary) []RepositorySummary {
+	if !c.enabled() {
+		return items
+	}
+	cleaned := slices.Clone(items)
+	for i := range cleaned {
+		cleaned[i].Description = c.clean(cleaned[i].Description)
+	}
+	return cleaned
+}
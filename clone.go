@@ -0,0 +1,171 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// --- Clone Tool Exports ---
+//
+// Discovery usually ends in cloning or backing up what was found. The
+// ghorg and gickup sinks (-also-write ghorg:reclone.yaml) write the results
+// as configuration for those tools, one entry per organization or user,
+// limited to the repositories found:
+//
+//	ghorg reclone --reclone-path reclone.yaml
+//	gickup gickup.yml
+//
+// Only the services the tools support are exported; repositories on others
+// are skipped with a warning.
+
+// cloneOwner is an organization or user with the repositories found in it.
+type cloneOwner struct {
+	SCM     string // The tools' name for the service
+	BaseURL string // For self-hosted instances; empty for the public services
+	Owner   string // Nested GitLab groups include their parents
+	Repos   []string
+}
+
+// cloneSCMs maps built-in services to the name ghorg and gickup use.
+var cloneSCMs = map[string]string{
+	"github":    "github",
+	"gitlab":    "gitlab",
+	"bitbucket": "bitbucket",
+}
+
+// cloneOwners groups the results by service and owner, in a stable order.
+func cloneOwners(items []RepositorySummary) []*cloneOwner {
+	var owners []*cloneOwner
+	skipped := make(map[string]int)
+	for _, item := range items {
+		u, err := url.Parse(item.URL)
+		if err != nil || u.Host == "" {
+			skipped["(no URL)"]++
+			continue
+		}
+		p, ok := providerForHost(u.Hostname())
+		scm := cloneSCMs[strings.ToLower(cmp.Or(p.Runs, p.Name))]
+		owner, name, _ := cutLast(item.FullName, "/")
+		if !ok || scm == "" || owner == "" {
+			skipped[u.Host]++
+			continue
+		}
+		baseURL := ""
+		if p.Runs != "" {
+			baseURL = u.Scheme + "://" + u.Host
+		}
+		i := slices.IndexFunc(owners, func(o *cloneOwner) bool {
+			return o.SCM == scm && o.BaseURL == baseURL && strings.EqualFold(o.Owner, owner)
+		})
+		if i < 0 {
+			owners = append(owners, &cloneOwner{SCM: scm, BaseURL: baseURL, Owner: owner})
+			i = len(owners) - 1
+		}
+		if !slices.Contains(owners[i].Repos, name) {
+			owners[i].Repos = append(owners[i].Repos, name)
+		}
+	}
+	for host, n := range skipped {
+		log.Printf("Warning: %d repositories on %s can't be exported for ghorg or gickup; the service isn't supported", n, host)
+	}
+	for _, o := range owners {
+		slices.Sort(o.Repos)
+	}
+	slices.SortFunc(owners, func(a, b *cloneOwner) int {
+		return cmp.Or(strings.Compare(a.SCM, b.SCM), strings.Compare(a.BaseURL, b.BaseURL), strings.Compare(strings.ToLower(a.Owner), strings.ToLower(b.Owner)))
+	})
+	return owners
+}
+
+// cutLast splits s around the last instance of sep.
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return "", s, false
+}
+
+// yamlString quotes s for YAML. A JSON string is a valid YAML
+// double-quoted scalar, so encoding/json does the escaping.
+func yamlString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// writeGhorg writes a ghorg reclone file: one clone command per owner,
+// matching just the repositories found.
+func writeGhorg(w io.Writer, items []RepositorySummary, source string) error {
+	owners := cloneOwners(items)
+	if len(owners) == 0 {
+		return fmt.Errorf("no repositories on a service ghorg supports")
+	}
+	fmt.Fprintf(w, "# ghorg reclone file for %d repositories from %s.\n", len(items), source)
+	fmt.Fprintln(w, "# Run with: ghorg reclone --reclone-path <this file>")
+	fmt.Fprintln(w, "# Owners that are users, not organizations, need --clone-type=user.")
+	names := make(map[string]bool)
+	for _, o := range owners {
+		quoted := make([]string, len(o.Repos))
+		for i, name := range o.Repos {
+			quoted[i] = regexp.QuoteMeta(name)
+		}
+		cmd := fmt.Sprintf("ghorg clone %s --scm=%s --clone-type=org", shellQuote(o.Owner), o.SCM)
+		if o.BaseURL != "" {
+			cmd += " --base-url=" + shellQuote(o.BaseURL)
+		}
+		if strings.Contains(o.Owner, "/") {
+			cmd += " --preserve-dir"
+		}
+		cmd += " --match-regex=" + shellQuote("^("+strings.Join(quoted, "|")+")$")
+
+		// Entry names must be unique; the same owner may be on several hosts.
+		name := siteSlug(o.SCM + "-" + o.Owner)
+		for i := 2; names[name]; i++ {
+			name = fmt.Sprintf("%s-%d", siteSlug(o.SCM+"-"+o.Owner), i)
+		}
+		names[name] = true
+		fmt.Fprintf(w, "%s:\n  cmd: %s\n  description: %s\n", name, yamlString(cmd),
+			yamlString(fmt.Sprintf("%d repositories found in %s", len(o.Repos), o.Owner)))
+	}
+	return nil
+}
+
+// writeGickup writes a gickup configuration with a source per owner,
+// including just the repositories found, and a local destination to edit.
+func writeGickup(w io.Writer, items []RepositorySummary, source string) error {
+	owners := cloneOwners(items)
+	if len(owners) == 0 {
+		return fmt.Errorf("no repositories on a service gickup supports")
+	}
+	fmt.Fprintf(w, "# gickup configuration for %d repositories from %s.\n", len(items), source)
+	fmt.Fprintln(w, "# Add tokens for private repositories, and set the destination.")
+	fmt.Fprintln(w, "source:")
+	for i, o := range owners {
+		if i == 0 || o.SCM != owners[i-1].SCM {
+			fmt.Fprintf(w, "  %s:\n", o.SCM)
+		}
+		fmt.Fprintf(w, "    - user: %s\n", yamlString(o.Owner))
+		if o.BaseURL != "" {
+			fmt.Fprintf(w, "      url: %s\n", yamlString(o.BaseURL))
+		}
+		fmt.Fprintln(w, "      include:")
+		for _, name := range o.Repos {
+			fmt.Fprintf(w, "        - %s\n", yamlString(name))
+		}
+	}
+	fmt.Fprintln(w, "destination:")
+	fmt.Fprintln(w, "  local:")
+	fmt.Fprintln(w, "    - path: ./backup")
+	return nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"sort"
+	"strings"
+)
+
+// --- Subcommands ---
+
+// subcommands maps the first command-line argument to its handler. Any other
+// first argument is treated as the start of a normal search invocation.
+var subcommands = map[string]func(args []string) error{
+	"awesome":    runAwesome,
+	"block":      runBlock,
+	"bookmark":   runBookmark,
+	"import":     runImport,
+	"ping":       runPing,
+	"provenance": runProvenance,
+	"prune":      runPrune,
+	"report":     runReport,
+	"watch":      runWatch,
+	"refine":     runRefine,
+	"reparse":    runReparse,
+	"serve":      runServe,
+	"similar":    runSimilar,
+	"site":       runSite,
+}
+
+// subcommandNames returns the subcommand names, sorted, for usage messages.
+func subcommandNames() []string {
+	names := make([]string, 0, len(subcommands))
+	for name := range subcommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseInterspersed parses args with fs, allowing flags to appear after
+// positional arguments (e.g. `bookmark add owner/repo -note x`), which the
+// flag package alone stops at. It returns the positional arguments.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// flagSet reports whether the named top-level flag was given explicitly,
+// for flags whose default must not override the config file.
+func flagSet(name string) bool {
+	found := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			found = true
+		}
+	})
+	return found
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries.
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// containsFold reports whether list contains s, ignoring case.
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
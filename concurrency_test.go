@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// These tests exercise the concurrent paths of serve and multi-provider
+// searches; run them with go test -race.
+
+// fakeGitHub is a GitHub search API that answers every page with two
+// repositories named after the server and query, and records how many
+// requests it handled at once.
+type fakeGitHub struct {
+	*httptest.Server
+	name     string
+	requests atomic.Int32
+	inFlight atomic.Int32
+	maxSeen  atomic.Int32
+}
+
+func newFakeGitHub(t *testing.T, name string) *fakeGitHub {
+	f := &fakeGitHub{name: name}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.requests.Add(1)
+		n := f.inFlight.Add(1)
+		defer f.inFlight.Add(-1)
+		for {
+			seen := f.maxSeen.Load()
+			if n <= seen || f.maxSeen.CompareAndSwap(seen, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond) // Long enough for requests to overlap
+		q := r.URL.Query().Get("q")
+		fmt.Fprintf(w, `{"total_count": 2, "items": [
+			{"full_name": "%[1]s/%[2]s-1", "html_url": "https://github.com/%[1]s/%[2]s-1"},
+			{"full_name": "%[1]s/%[2]s-2", "html_url": "https://github.com/%[1]s/%[2]s-2"}]}`, f.name, q)
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// newTestSearcher returns a GitHub searcher for srv, labelled source.
+func newTestSearcher(srv *fakeGitHub, source string) *GitHubSearcher {
+	s := NewGitHubSearcher("", srv.Client())
+	s.BaseURL = srv.URL
+	s.Source = source
+	s.RetryDelay = time.Millisecond
+	s.PageDelay = 0
+	return s
+}
+
+func TestSearchAllSerializesSharedHosts(t *testing.T) {
+	a, b := newFakeGitHub(t, "a"), newFakeGitHub(t, "b")
+	searchers := []searcherTemplate{
+		newTestSearcher(a, "a1"), newTestSearcher(a, "a2"), newTestSearcher(a, "a3"),
+		newTestSearcher(b, "b1"), newTestSearcher(b, "b2"),
+	}
+
+	result, err := searchAll(context.Background(), searchers, "go", 1, false, len(searchers))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Items) != 2*len(searchers) {
+		t.Errorf("got %d items, want %d", len(result.Items), 2*len(searchers))
+	}
+	for _, f := range []*fakeGitHub{a, b} {
+		if got := f.maxSeen.Load(); got != 1 {
+			t.Errorf("host %s served %d requests at once, want 1", f.name, got)
+		}
+	}
+	// Results stay in searcher order whatever order the crawls finish in.
+	for i, want := range []string{"a1", "a2", "a3", "b1", "b2"} {
+		if got := result.Items[2*i].Provider; got != want {
+			t.Errorf("item %d is from %s, want %s", 2*i, got, want)
+		}
+	}
+}
+
+func TestSharedMiddlewareUnderParallelProviders(t *testing.T) {
+	a, b := newFakeGitHub(t, "a"), newFakeGitHub(t, "b")
+	cache := NewResponseCache(100, time.Hour)
+	var requests requestCounter
+	var searchers []searcherTemplate
+	for _, s := range []*GitHubSearcher{newTestSearcher(a, "a"), newTestSearcher(b, "b")} {
+		s.Use(cache.Middleware(), requests.Middleware(s.Source))
+		searchers = append(searchers, s)
+	}
+
+	// Several multi-provider searches at once, each query twice.
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := searchAll(context.Background(), searchers, fmt.Sprintf("q%d", i%4), 1, false, 2); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	counts := requests.snapshot()
+	for _, f := range []*fakeGitHub{a, b} {
+		if got, sent := counts[f.name], int(f.requests.Load()); got != sent {
+			t.Errorf("counted %d requests to %s, but it served %d", got, f.name, sent)
+		}
+	}
+	hits, misses := cache.Stats()
+	if hits+misses != 16 {
+		t.Errorf("cache saw %d lookups, want 16", hits+misses)
+	}
+	if int(a.requests.Load()+b.requests.Load()) != misses {
+		t.Errorf("%d requests reached the servers, want one per cache miss (%d)", a.requests.Load()+b.requests.Load(), misses)
+	}
+}
+
+func TestSearcherSharedAcrossGoroutines(t *testing.T) {
+	srv := newFakeGitHub(t, "a")
+	s := newTestSearcher(srv, "a")
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			query := fmt.Sprintf("q%d", i)
+			result, err := s.Search(context.Background(), query, 1)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if result.Query != query || len(result.Items) != 2 || result.Items[0].FullName != "a/"+query+"-1" {
+				t.Errorf("search for %s got %s with %v", query, result.Query, result.Items)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// --- Configuration File ---
+
+// Config is the optional JSON configuration file, by default
+// ~/.config/rexplorer/config.json.
+type Config struct {
+	// TagRules add tags to matching results.
+	TagRules []TagRule `json:"tag_rules,omitempty"`
+	// Providers holds per-provider overrides, keyed by service name.
+	Providers map[string]ProviderConfig `json:"providers,omitempty"`
+	// LanguageAliases maps provider language labels to canonical names, on
+	// top of the built-in table (e.g. {"golang": "Go"}).
+	LanguageAliases map[string]string `json:"language_aliases,omitempty"`
+	// TopicAliases maps topics to canonical topics, on top of the built-in
+	// table (e.g. {"k8s": "kubernetes"}).
+	TopicAliases map[string]string `json:"topic_aliases,omitempty"`
+	// CleanDescriptions strips these kinds of markup (emoji, html,
+	// markdown, or all) from descriptions in the console and CSV outputs
+	// (see -clean-descriptions).
+	CleanDescriptions []string `json:"clean_descriptions,omitempty"`
+	// ProviderGroups names sets of services for -service=@name, e.g.
+	// {"china": ["gitee", "gitcode"]}.
+	ProviderGroups map[string][]string `json:"provider_groups,omitempty"`
+	// FieldMappings map fields of each service's raw results into summary
+	// fields or Extra, as target field to JSONPath (see mapping.go), e.g.
+	// {"gitlab-internal": {"extra.team": "$.namespace.owner"}}.
+	FieldMappings map[string]map[string]string `json:"field_mappings,omitempty"`
+	// Instances names self-hosted instances of a provider as services of
+	// their own, each with its URL and token (see instances.go).
+	Instances map[string]InstanceConfig `json:"instances,omitempty"`
+	// ConfirmAbove is the estimated request count above which a run asks
+	// for confirmation (see -confirm-above); negative disables the check.
+	ConfirmAbove int `json:"confirm_above,omitempty"`
+	// Retention is how long run history and -out-dir run folders are kept.
+	Retention RetentionConfig `json:"retention,omitempty"`
+	// Store selects where history, the watch list and bookmarks live.
+	Store StoreConfig `json:"store,omitempty"`
+	// Tenants are the users and teams allowed to use serve mode.
+	Tenants []TenantConfig `json:"tenants,omitempty"`
+	// Transport tunes the HTTP connection pool.
+	Transport TransportConfig `json:"transport,omitempty"`
+
+	// mappings are the compiled FieldMappings, keyed by lowercased service.
+	mappings map[string][]fieldMapping
+}
+
+// ProviderConfig overrides a provider's request settings, so a slow or
+// flaky provider can't use up the time meant for the others.
+type ProviderConfig struct {
+	// Timeout bounds the whole search on this provider, e.g. "45s".
+	Timeout string `json:"timeout,omitempty"`
+	// Retries is the number of attempts per request.
+	Retries int `json:"retries,omitempty"`
+	// PageSize is the number of results requested per page.
+	PageSize int `json:"page_size,omitempty"`
+	// RateLimit is the minimum pause between page requests, e.g. "500ms".
+	RateLimit string `json:"rate_limit,omitempty"`
+}
+
+// pageSizeLimiter is implemented by providers that cap the page size; a
+// larger page_size is lowered to the cap.
+type pageSizeLimiter interface {
+	maxPerPage() int
+}
+
+// apply copies the overrides onto a searcher. The durations have already
+// been checked by Config.validate.
+func (p ProviderConfig) apply(s *BaseRepoSearcher) {
+	if d, err := time.ParseDuration(p.Timeout); err == nil {
+		s.Timeout = d
+	}
+	if d, err := time.ParseDuration(p.RateLimit); err == nil {
+		s.PageDelay = d
+	}
+	if p.Retries > 0 {
+		s.MaxRetries = p.Retries
+	}
+	if p.PageSize > 0 {
+		s.PerPage = p.PageSize
+		if l, ok := s.implementation.(pageSizeLimiter); ok {
+			s.PerPage = min(s.PerPage, l.maxPerPage())
+		}
+	}
+}
+
+// configPath returns the default configuration file location.
+func configPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.json"), nil
+}
+
+// loadConfig reads the configuration from path, or from the default location
+// if path is empty. A missing default file yields an empty configuration;
+// a missing explicit file is an error.
+func loadConfig(path string) (*Config, error) {
+	explicit := path != ""
+	if !explicit {
+		p, err := configPath()
+		if err != nil {
+			return nil, err
+		}
+		path = p
+	}
+
+	cfg := &Config{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !explicit {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// validate checks the parts of the configuration that can be wrong in ways
+// JSON decoding doesn't catch.
+func (c *Config) validate() error {
+	if err := validateInstances(c.Instances); err != nil {
+		return err
+	}
+	// Instances are services like the built-in ones, so they are in use
+	// before anything below looks up a service.
+	useInstances(c.Instances)
+	for i := range c.TagRules {
+		if err := c.TagRules[i].compile(); err != nil {
+			return fmt.Errorf("tag rule %d (%q): %w", i+1, c.TagRules[i].Tag, err)
+		}
+	}
+	normalized := make(map[string]ProviderConfig, len(c.Providers))
+	for name, p := range c.Providers {
+		if _, ok := lookupProvider(name); !ok {
+			return fmt.Errorf("providers: unknown service %q", name)
+		}
+		normalized[strings.ToLower(name)] = p
+		for field, value := range map[string]string{"timeout": p.Timeout, "rate_limit": p.RateLimit} {
+			if value == "" {
+				continue
+			}
+			if _, err := time.ParseDuration(value); err != nil {
+				return fmt.Errorf("providers.%s.%s: %w", name, field, err)
+			}
+		}
+		if p.Retries < 0 || p.PageSize < 0 {
+			return fmt.Errorf("providers.%s: retries and page_size must not be negative", name)
+		}
+	}
+	c.Providers = normalized
+	c.mappings = make(map[string][]fieldMapping, len(c.FieldMappings))
+	for name, m := range c.FieldMappings {
+		if _, ok := lookupProvider(name); !ok {
+			return fmt.Errorf("field_mappings: unknown service %q", name)
+		}
+		mappings, err := compileFieldMappings(m)
+		if err != nil {
+			return fmt.Errorf("field_mappings.%s: %w", name, err)
+		}
+		c.mappings[strings.ToLower(name)] = mappings
+	}
+	if _, err := parseCleaning(c.CleanDescriptions); err != nil {
+		return fmt.Errorf("clean_descriptions: %w", err)
+	}
+	if err := validateProviderGroups(c.ProviderGroups); err != nil {
+		return err
+	}
+	// The groups are in use from here on, so -service values and the
+	// tenants' searches below can refer to them.
+	useProviderGroups(c.ProviderGroups)
+	if err := c.Retention.validate(); err != nil {
+		return err
+	}
+	if err := c.Store.validate(); err != nil {
+		return err
+	}
+	if err := validateTenants(c.Tenants); err != nil {
+		return err
+	}
+	return c.Transport.validate()
+}
+
+// --- Durations and Timestamps ---
+
+// ageUnits are the suffixes accepted by parseAge. Note that "m" is months,
+// not minutes: ages are about repository activity, not timeouts.
+var ageUnits = map[byte]time.Duration{
+	'h': time.Hour,
+	'd': 24 * time.Hour,
+	'w': 7 * 24 * time.Hour,
+	'm': 30 * 24 * time.Hour,
+	'y': 365 * 24 * time.Hour,
+}
+
+// parseAge parses an age such as "90d", "12m" or "2y".
+func parseAge(s string) (time.Duration, error) {
+	if len(s) < 2 {
+		return 0, fmt.Errorf("invalid age %q: expected a number and a unit (h, d, w, m, y)", s)
+	}
+	unit, ok := ageUnits[s[len(s)-1]]
+	if !ok {
+		return 0, fmt.Errorf("invalid age %q: unknown unit %q (use h, d, w, m or y)", s, s[len(s)-1:])
+	}
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid age %q: %q is not a non-negative number", s, s[:len(s)-1])
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// timestampLayouts are the formats providers use for dates.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999-0700", // Bitbucket and some Gitee fields
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// parseTimestamp parses a provider timestamp, reporting whether it succeeded.
+func parseTimestamp(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// --- Container Images ---
+//
+// When hunting for a deployable tool rather than a library, a published
+// image matters more than the source. The images enricher looks for the
+// repository's packages on the provider's own registry (GHCR for GitHub) and
+// on Docker Hub, where an image only counts if its description links back to
+// the repository.
+
+// dockerHubCandidates is how many Docker Hub search results are checked for
+// a link back to the repository.
+const dockerHubCandidates = 3
+
+// ContainerImage is a published container image built from a repository.
+type ContainerImage struct {
+	Registry string `json:"registry"`
+	Name     string `json:"name"`
+	// Pulls is reported by Docker Hub only.
+	Pulls int `json:"pulls,omitempty"`
+}
+
+// imagesLister is implemented by providers with a container registry.
+type imagesLister interface {
+	// containerPackagesURLs returns the listings to try, in order, for an
+	// owner's container packages; a 404 moves on to the next.
+	containerPackagesURLs(owner string) []string
+	parseContainerPackages(body io.Reader, fullName string) ([]ContainerImage, error)
+}
+
+// enrichImages fills in Images.
+func enrichImages(ctx context.Context, s *BaseRepoSearcher, item *RepositorySummary) error {
+	var images []ContainerImage
+	if il, ok := s.implementation.(imagesLister); ok && s.Token != "" {
+		owner, _, _ := strings.Cut(item.FullName, "/")
+		for _, u := range il.containerPackagesURLs(owner) {
+			body, err := s.fetchDetail(ctx, u)
+			if isNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			found, err := il.parseContainerPackages(body, item.FullName)
+			body.Close()
+			if err != nil {
+				return err
+			}
+			images = append(images, found...)
+			break
+		}
+	}
+	hub, err := dockerHubImages(ctx, s, item)
+	if err != nil {
+		return err
+	}
+	item.Images = append(images, hub...)
+	return nil
+}
+
+// dockerHubImages searches Docker Hub for the repository's name and keeps
+// the images whose description links to the repository.
+func dockerHubImages(ctx context.Context, s *BaseRepoSearcher, item *RepositorySummary) ([]ContainerImage, error) {
+	var search struct {
+		Results []struct {
+			RepoName  string `json:"repo_name"`
+			PullCount int    `json:"pull_count"`
+		} `json:"results"`
+	}
+	q := url.Values{"query": {item.Name}, "page_size": {fmt.Sprint(dockerHubCandidates)}}
+	if err := fetchRegistryJSON(ctx, s, "https://hub.docker.com/v2/search/repositories/?"+q.Encode(), &search); err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var images []ContainerImage
+	for _, r := range search.Results {
+		name := r.RepoName
+		if !strings.Contains(name, "/") {
+			name = "library/" + name // Official images
+		}
+		var detail struct {
+			FullDescription string `json:"full_description"`
+		}
+		if err := fetchRegistryJSON(ctx, s, "https://hub.docker.com/v2/repositories/"+name+"/", &detail); err != nil {
+			if isNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if strings.Contains(strings.ToLower(detail.FullDescription), strings.ToLower(repoPath(item))) {
+			images = append(images, ContainerImage{Registry: "Docker Hub", Name: "docker.io/" + r.RepoName, Pulls: r.PullCount})
+		}
+	}
+	return images, nil
+}
+
+// containerPackagesURLs implements imagesLister. Listing packages needs a
+// token with the read:packages scope; organizations and users have
+// separate endpoints.
+func (g *GitHubSearcher) containerPackagesURLs(owner string) []string {
+	return []string{
+		g.BaseURL + "/orgs/" + owner + "/packages?package_type=container&per_page=100",
+		g.BaseURL + "/users/" + owner + "/packages?package_type=container&per_page=100",
+	}
+}
+
+// parseContainerPackages implements imagesLister.
+func (g *GitHubSearcher) parseContainerPackages(body io.Reader, fullName string) ([]ContainerImage, error) {
+	var packages []struct {
+		Name       string   `json:"name"`
+		Repository *repoRef `json:"repository"`
+	}
+	if err := json.NewDecoder(body).Decode(&packages); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal GitHub packages: %w", err)
+	}
+	owner, _, _ := strings.Cut(fullName, "/")
+	var images []ContainerImage
+	for _, p := range packages {
+		if strings.EqualFold(p.Repository.name(), fullName) {
+			images = append(images, ContainerImage{Registry: "GHCR", Name: strings.ToLower("ghcr.io/" + owner + "/" + p.Name)})
+		}
+	}
+	return images, nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// --- Contributor-Friendly Issues ---
+//
+// Would-be contributors look for projects that label approachable work. The
+// contrib enricher counts open issues labeled good-first-issue and
+// help-wanted.
+
+// Label spellings in common use. Providers that can't OR labels in one
+// request use the first.
+var (
+	goodFirstIssueLabels = []string{"good first issue", "good-first-issue"}
+	helpWantedLabels     = []string{"help wanted", "help-wanted"}
+)
+
+// contribCounter is implemented by providers that can count open issues
+// carrying any of a set of labels.
+type contribCounter interface {
+	labeledIssuesURL(fullName string, labels []string) string
+	parseLabeledIssues(body io.Reader) (int, error)
+}
+
+// enrichContrib fills in GoodFirstIssues and HelpWantedIssues.
+func enrichContrib(ctx context.Context, s *BaseRepoSearcher, item *RepositorySummary) error {
+	cc, ok := s.implementation.(contribCounter)
+	if !ok {
+		return errUnsupported
+	}
+	count := func(labels []string) (int, error) {
+		body, err := s.fetchDetail(ctx, cc.labeledIssuesURL(item.FullName, labels))
+		if err != nil {
+			return 0, err
+		}
+		defer body.Close()
+		return cc.parseLabeledIssues(body)
+	}
+	var err error
+	if item.GoodFirstIssues, err = count(goodFirstIssueLabels); err != nil {
+		return err
+	}
+	item.HelpWantedIssues, err = count(helpWantedLabels)
+	return err
+}
+
+// labeledIssuesURL implements contribCounter with the issue search, which
+// ORs comma-separated label values.
+func (g *GitHubSearcher) labeledIssuesURL(fullName string, labels []string) string {
+	quoted := make([]string, len(labels))
+	for i, l := range labels {
+		quoted[i] = strconv.Quote(l)
+	}
+	q := url.Values{
+		"q":        {"repo:" + fullName + " is:issue is:open label:" + strings.Join(quoted, ",")},
+		"per_page": {"1"},
+	}
+	return g.BaseURL + "/search/issues?" + q.Encode()
+}
+
+// parseLabeledIssues implements contribCounter.
+func (g *GitHubSearcher) parseLabeledIssues(body io.Reader) (int, error) {
+	var resp struct {
+		TotalCount int `json:"total_count"`
+	}
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal GitHub issue search: %w", err)
+	}
+	return resp.TotalCount, nil
+}
+
+// labeledIssuesURL implements contribCounter with the issue statistics,
+// which count without listing.
+func (g *GitLabSearcher) labeledIssuesURL(fullName string, labels []string) string {
+	q := url.Values{"labels": {labels[0]}, "state": {"opened"}}
+	return g.BaseURL + "/projects/" + url.PathEscape(fullName) + "/issues_statistics?" + q.Encode()
+}
+
+// parseLabeledIssues implements contribCounter.
+func (g *GitLabSearcher) parseLabeledIssues(body io.Reader) (int, error) {
+	var resp struct {
+		Statistics struct {
+			Counts struct {
+				Opened int `json:"opened"`
+			} `json:"counts"`
+		} `json:"statistics"`
+	}
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal GitLab issue statistics: %w", err)
+	}
+	return resp.Statistics.Counts.Opened, nil
+}
+
+// labeledIssuesURL implements contribCounter. Gitee has no count endpoint,
+// so this lists up to 100 issues.
+func (g *GiteeSearcher) labeledIssuesURL(fullName string, labels []string) string {
+	q := url.Values{"labels": {labels[0]}, "state": {"open"}, "per_page": {"100"}}
+	return g.BaseURL + "/repos/" + fullName + "/issues?" + q.Encode()
+}
+
+// parseLabeledIssues implements contribCounter.
+func (g *GiteeSearcher) parseLabeledIssues(body io.Reader) (int, error) {
+	var issues []json.RawMessage
+	if err := json.NewDecoder(body).Decode(&issues); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal Gitee issues: %w", err)
+	}
+	return len(issues), nil
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"cmp"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// --- CycloneDX Export ---
+//
+// The cyclonedx sink (-also-write cyclonedx:candidates.cdx.json) and
+// `rexplorer bookmark export -format cyclonedx` write the repositories as
+// CycloneDX components with VCS external references, so security tooling
+// that tracks components can follow candidates from the discovery stage.
+// Repositories with a known package (-enrich=packages) get its purl and
+// version; the rest get a github or bitbucket purl where there is one.
+
+// cyclonedxSpecVersion is the CycloneDX version written.
+const cyclonedxSpecVersion = "1.5"
+
+// cyclonedxBOM is a CycloneDX document, with the fields rexplorer fills in.
+type cyclonedxBOM struct {
+	BOMFormat    string               `json:"bomFormat"`
+	SpecVersion  string               `json:"specVersion"`
+	SerialNumber string               `json:"serialNumber"`
+	Version      int                  `json:"version"`
+	Metadata     cyclonedxMetadata    `json:"metadata"`
+	Components   []cyclonedxComponent `json:"components"`
+}
+
+type cyclonedxMetadata struct {
+	Timestamp string `json:"timestamp"`
+	Tools     struct {
+		Components []cyclonedxComponent `json:"components"`
+	} `json:"tools"`
+	Properties []cyclonedxProperty `json:"properties,omitempty"`
+}
+
+type cyclonedxComponent struct {
+	Type               string              `json:"type"`
+	BOMRef             string              `json:"bom-ref,omitempty"`
+	Group              string              `json:"group,omitempty"`
+	Name               string              `json:"name"`
+	Version            string              `json:"version,omitempty"`
+	Description        string              `json:"description,omitempty"`
+	Licenses           []cyclonedxLicense  `json:"licenses,omitempty"`
+	PURL               string              `json:"purl,omitempty"`
+	ExternalReferences []cyclonedxRef      `json:"externalReferences,omitempty"`
+	Properties         []cyclonedxProperty `json:"properties,omitempty"`
+}
+
+type cyclonedxLicense struct {
+	License struct {
+		ID   string `json:"id,omitempty"`
+		Name string `json:"name,omitempty"`
+	} `json:"license"`
+}
+
+type cyclonedxRef struct {
+	Type    string `json:"type"`
+	URL     string `json:"url"`
+	Comment string `json:"comment,omitempty"`
+}
+
+type cyclonedxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// cyclonedxPURLTypes maps built-in services to their package URL type.
+// GitLab has none.
+var cyclonedxPURLTypes = map[string]string{
+	"github":    "github",
+	"bitbucket": "bitbucket",
+}
+
+// cyclonedxRegistryPURLTypes maps package registries to their package URL
+// type.
+var cyclonedxRegistryPURLTypes = map[string]string{
+	"Go":   "golang",
+	"npm":  "npm",
+	"PyPI": "pypi",
+}
+
+// purlPath escapes each segment of a package URL namespace and name.
+func purlPath(name string) string {
+	segments := strings.Split(name, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// cyclonedxPURL returns the package URL of a repository: its package's if
+// known, or the repository's own on services with a purl type.
+func cyclonedxPURL(s RepositorySummary, provider string) string {
+	if s.Package != nil {
+		if t, ok := cyclonedxRegistryPURLTypes[s.Package.Registry]; ok {
+			name := s.Package.Name
+			if t == "pypi" {
+				// PyPI names are case-insensitive and normalized to dashes.
+				name = strings.ToLower(strings.NewReplacer("_", "-", ".", "-").Replace(name))
+			}
+			purl := "pkg:" + t + "/" + purlPath(name)
+			if s.Package.Version != "" {
+				purl += "@" + url.PathEscape(s.Package.Version)
+			}
+			return purl
+		}
+	}
+	p, ok := lookupProvider(provider)
+	if !ok || p.Runs != "" {
+		return ""
+	}
+	if t, ok := cyclonedxPURLTypes[strings.ToLower(p.Name)]; ok {
+		return "pkg:" + t + "/" + purlPath(strings.ToLower(s.FullName))
+	}
+	return ""
+}
+
+// cyclonedxLicenses returns the license of a repository, by SPDX ID if it
+// has one.
+func cyclonedxLicenses(name string) []cyclonedxLicense {
+	id := spdxLicense(name)
+	if id == "NOASSERTION" {
+		return nil
+	}
+	var l cyclonedxLicense
+	if isSPDXID(id) {
+		l.License.ID = id
+	} else {
+		l.License.Name = id
+	}
+	return []cyclonedxLicense{l}
+}
+
+// isSPDXID reports whether id is one of the SPDX identifiers rexplorer maps
+// provider license names to.
+func isSPDXID(id string) bool {
+	for _, known := range spdxLicenses {
+		if known == id {
+			return true
+		}
+	}
+	return false
+}
+
+// newSerialNumber returns a random URN for a BOM, as CycloneDX requires.
+func newSerialNumber() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// cyclonedxComponentFor describes one repository as a library component,
+// with its statistics as properties if it has them.
+func cyclonedxComponentFor(s RepositorySummary, provider string, stats bool) cyclonedxComponent {
+	owner, name, _ := cutLast(s.FullName, "/")
+	c := cyclonedxComponent{
+		Type:        "library",
+		BOMRef:      strings.TrimPrefix(strings.ToLower(provider)+"/"+s.FullName, "/"),
+		Group:       owner,
+		Name:        name,
+		Description: s.Description,
+		Licenses:    cyclonedxLicenses(s.License),
+		PURL:        cyclonedxPURL(s, provider),
+	}
+	if s.Package != nil {
+		c.Version = s.Package.Version
+	}
+
+	if s.URL != "" {
+		ref := cyclonedxRef{Type: "vcs", URL: s.URL}
+		if s.DefaultBranch != "" {
+			ref.Comment = "default branch " + s.DefaultBranch
+		}
+		c.ExternalReferences = append(c.ExternalReferences, ref)
+	}
+	if s.Homepage != "" {
+		c.ExternalReferences = append(c.ExternalReferences, cyclonedxRef{Type: "website", URL: s.Homepage})
+	}
+	if s.DocsURL != "" && s.DocsURL != s.Homepage {
+		c.ExternalReferences = append(c.ExternalReferences, cyclonedxRef{Type: "documentation", URL: s.DocsURL})
+	}
+	if s.Package != nil && s.Package.URL != "" {
+		c.ExternalReferences = append(c.ExternalReferences,
+			cyclonedxRef{Type: "distribution", URL: s.Package.URL, Comment: s.Package.Registry})
+	}
+
+	property := func(name, value string) {
+		if value != "" {
+			c.Properties = append(c.Properties, cyclonedxProperty{"rexplorer:" + name, value})
+		}
+	}
+	property("provider", strings.ToLower(provider))
+	if stats {
+		property("stars", strconv.Itoa(s.Stars))
+		property("forks", strconv.Itoa(s.Forks))
+		property("archived", strconv.FormatBool(s.IsArchived))
+		property("fork", strconv.FormatBool(s.IsFork))
+	}
+	property("language", s.Language)
+	property("updated_at", s.UpdatedAt)
+	property("topics", strings.Join(s.Topics, ","))
+	property("tags", strings.Join(s.Tags, ","))
+	return c
+}
+
+// writeCycloneDX writes the results as a CycloneDX BOM of library
+// components, one per repository.
+func writeCycloneDX(w io.Writer, items []RepositorySummary, source string) error {
+	components := make([]cyclonedxComponent, len(items))
+	for i, s := range items {
+		components[i] = cyclonedxComponentFor(s, cmp.Or(s.Provider, source), true)
+	}
+	return writeCycloneDXBOM(w, components, source)
+}
+
+// writeCycloneDXBOM writes a CycloneDX BOM holding components, dropping
+// repeated ones since bom-ref values must be unique.
+func writeCycloneDXBOM(w io.Writer, components []cyclonedxComponent, source string) error {
+	version, _ := toolVersion()
+	bom := cyclonedxBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  cyclonedxSpecVersion,
+		SerialNumber: newSerialNumber(),
+		Version:      1,
+		Components:   []cyclonedxComponent{},
+	}
+	bom.Metadata.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	bom.Metadata.Tools.Components = []cyclonedxComponent{{Type: "application", Name: "rexplorer", Version: version}}
+	bom.Metadata.Properties = []cyclonedxProperty{{"rexplorer:source", source}}
+
+	seen := make(map[string]bool)
+	for _, c := range components {
+		if seen[c.BOMRef] {
+			continue
+		}
+		seen[c.BOMRef] = true
+		bom.Components = append(bom.Components, c)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(bom); err != nil {
+		return fmt.Errorf("failed to encode CycloneDX BOM: %w", err)
+	}
+	return nil
+}
+
+// writeBookmarksCycloneDX writes bookmarks as a CycloneDX BOM. Bookmarks
+// carry no statistics, and their URL is only known when the service is.
+func writeBookmarksCycloneDX(w io.Writer, bookmarks []Bookmark) error {
+	components := make([]cyclonedxComponent, len(bookmarks))
+	for i, b := range bookmarks {
+		s := RepositorySummary{FullName: b.FullName, Provider: b.Service, Description: b.Note, Tags: b.Tags}
+		if p, ok := lookupProvider(b.Service); ok && p.WebHost != "" {
+			s.URL = "https://" + p.WebHost + "/" + b.FullName
+		}
+		components[i] = cyclonedxComponentFor(s, b.Service, false)
+	}
+	return writeCycloneDXBOM(w, components, "bookmarks")
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// --- Dependents ---
+//
+// How many other repositories depend on a library is a far stronger
+// adoption signal than stars. GitHub only publishes it on the dependency
+// graph's web page, so the dependents enricher reads that page.
+
+// dependentsFetcher is implemented by providers that publish dependents.
+type dependentsFetcher interface {
+	dependentsURL(fullName string) string
+	parseDependents(body io.Reader) (int, error)
+}
+
+// enrichDependents fills in Dependents.
+func enrichDependents(ctx context.Context, s *BaseRepoSearcher, item *RepositorySummary) error {
+	df, ok := s.implementation.(dependentsFetcher)
+	if !ok {
+		return errUnsupported
+	}
+	body, err := s.fetchWebPage(ctx, df.dependentsURL(item.FullName))
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	n, err := df.parseDependents(body)
+	if err != nil {
+		return err
+	}
+	item.Dependents = n
+	return nil
+}
+
+// fetchWebPage GETs a provider web (not API) page. Credentials are not sent:
+// API tokens mean nothing to the web frontend. Middleware still applies.
+func (s *BaseRepoSearcher) fetchWebPage(ctx context.Context, url string) (io.ReadCloser, error) {
+	return s.fetchPublic(ctx, url, "text/html")
+}
+
+// fetchPublic GETs url without credentials, once. It is for pages and
+// third-party APIs (like package registries) that need no provider token.
+func (s *BaseRepoSearcher) fetchPublic(ctx context.Context, url, accept string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", accept)
+	req.Header.Set("User-Agent", "go-repo-searcher/1.0")
+	resp, err := s.client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", redactError(err))
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, &apiError{Status: resp.StatusCode}
+	}
+	return resp.Body, nil
+}
+
+// gitHubDependentsCount matches the "1,234 Repositories" tab on GitHub's
+// dependents page.
+var gitHubDependentsCount = regexp.MustCompile(`(?s)dependent_type=REPOSITORY"[^>]*>.*?([\d,]+)\s+Repositor(?:y|ies)`)
+
+// dependentsURL implements dependentsFetcher.
+func (g *GitHubSearcher) dependentsURL(fullName string) string {
+	return "https://github.com/" + fullName + "/network/dependents"
+}
+
+// parseDependents implements dependentsFetcher. A page without the count
+// means the repository has no dependency graph (not a package).
+func (g *GitHubSearcher) parseDependents(body io.Reader) (int, error) {
+	page, err := io.ReadAll(io.LimitReader(body, 4<<20))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read dependents page: %w", err)
+	}
+	m := gitHubDependentsCount.FindSubmatch(page)
+	if m == nil {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(strings.ReplaceAll(string(m[1]), ",", ""))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse dependents count %q: %w", m[1], err)
+	}
+	return n, nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// --- Documentation Sites ---
+//
+// A project with a documentation site is usually further along than one with
+// only a README. markDocsSites judges from what the search returned; the docs
+// enricher visits homepages (and GitLab Pages) to check the rest.
+
+// docsHostSuffixes are hosts that serve little besides documentation.
+var docsHostSuffixes = []string{".readthedocs.io", ".readthedocs.org", ".gitbook.io", ".github.io", ".gitlab.io", "pkg.go.dev", "docs.rs"}
+
+// docsPageMarkers match documentation generators and titles in a page.
+var docsPageMarkers = regexp.MustCompile(`(?i)<meta[^>]+name="generator"[^>]+(?:mkdocs|sphinx|docusaurus|hugo|vuepress|vitepress|docsify|jekyll|mdbook|gitbook)|<title>[^<]*\b(?:docs|documentation|user guide|manual)\b`)
+
+// looksLikeDocs reports whether a URL is a documentation site by its name.
+func looksLikeDocs(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if strings.HasPrefix(host, "docs.") || strings.HasPrefix(host, "doc.") || strings.HasPrefix(host, "wiki.") {
+		return true
+	}
+	for _, suffix := range docsHostSuffixes {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	p := strings.ToLower(u.Path)
+	return strings.HasPrefix(p, "/docs") || strings.HasPrefix(p, "/doc/") || strings.HasPrefix(p, "/documentation")
+}
+
+// markDocsSites sets HasDocsSite for items with a Pages site or a homepage
+// that is plainly documentation. It sends no requests.
+func markDocsSites(items []RepositorySummary) {
+	for i := range items {
+		item := &items[i]
+		if looksLikeDocs(item.Homepage) {
+			item.HasDocsSite, item.DocsURL = true, item.Homepage
+		} else if item.HasPages {
+			item.HasDocsSite = true
+		}
+	}
+}
+
+// pagesProber is implemented by providers whose Pages sites are found at a
+// predictable address but not reported in search results.
+type pagesProber interface {
+	pagesURL(fullName string) string
+}
+
+// enrichDocs visits the homepage, or the Pages address, of items that
+// markDocsSites couldn't decide on.
+func enrichDocs(ctx context.Context, s *BaseRepoSearcher, item *RepositorySummary) error {
+	if item.HasDocsSite {
+		return nil
+	}
+	if item.Homepage != "" {
+		ok, err := isDocsPage(ctx, s, item.Homepage)
+		if err != nil {
+			return err
+		}
+		if ok {
+			item.HasDocsSite, item.DocsURL = true, item.Homepage
+		}
+		return nil
+	}
+	if pp, ok := s.implementation.(pagesProber); ok {
+		link := pp.pagesURL(item.FullName)
+		body, err := s.fetchWebPage(ctx, link)
+		if err != nil {
+			return ctx.Err() // No site deployed, or not reachable: either way, none to recommend
+		}
+		body.Close()
+		item.HasPages, item.HasDocsSite, item.DocsURL = true, true, link
+	}
+	return nil
+}
+
+// isDocsPage fetches a page and looks for documentation markers. Sites that
+// fail to load are not counted, but aren't errors either.
+func isDocsPage(ctx context.Context, s *BaseRepoSearcher, link string) (bool, error) {
+	body, err := s.fetchWebPage(ctx, link)
+	if err != nil {
+		return false, ctx.Err()
+	}
+	defer body.Close()
+	page, err := io.ReadAll(io.LimitReader(body, 256<<10))
+	if err != nil {
+		return false, nil
+	}
+	return docsPageMarkers.Match(page), nil
+}
+
+// pagesURL implements pagesProber: GitLab serves a project's Pages site
+// under its top-level group's gitlab.io domain.
+func (g *GitLabSearcher) pagesURL(fullName string) string {
+	group, rest, _ := strings.Cut(strings.ToLower(fullName), "/")
+	return "https://" + group + ".gitlab.io/" + rest + "/"
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// --- Schema Drift ---
+//
+// A provider that renames or drops a field doesn't make its responses fail
+// to parse: the field just decodes as empty, and every result silently
+// loses its stars or its license. So the first page of each search is
+// decoded a second time, leniently, and compared with the fields the parser
+// expects. Expected fields missing from every result are reported, along
+// with any new field that looks like their replacement.
+
+// schemaSource is implemented by providers that can check their responses
+// against the fields their parser reads.
+type schemaSource interface {
+	// itemSchema returns a zero value of the type each result is decoded
+	// into, or nil if the current search mode isn't checked, and the fields
+	// a response may legitimately leave out.
+	itemSchema() (item any, optional []string)
+}
+
+// genericTokens are field name parts too common to suggest a rename.
+var genericTokens = []string{"count", "url", "at", "on", "id", "is", "has", "num"}
+
+// schemaDrift compares a page's results with the fields the provider's
+// parser expects, and describes what has changed in one warning. Pages
+// without results say nothing about the schema.
+func schemaDrift(source string, impl RepoSearcher, body []byte) []string {
+	schema, ok1 := impl.(schemaSource)
+	paths, ok2 := impl.(rawItemSource)
+	if !ok1 || !ok2 {
+		return nil
+	}
+	item, optional := schema.itemSchema()
+	if item == nil {
+		return nil
+	}
+	itemsExpr, _ := paths.rawItemPaths()
+	itemsPath, err := compileJSONPath(itemsExpr)
+	if err != nil {
+		return nil
+	}
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil
+	}
+	var objects []map[string]any
+	for _, v := range itemsPath.eval(doc) {
+		if obj, ok := v.(map[string]any); ok {
+			objects = append(objects, obj)
+		}
+	}
+	if len(objects) == 0 {
+		return nil
+	}
+
+	expected := jsonFieldNames(reflect.TypeOf(item))
+	var missing []string
+	for _, name := range expected {
+		if slices.Contains(optional, name) {
+			continue
+		}
+		found := false
+		for _, obj := range objects {
+			if _, ok := obj[name]; ok {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	var added []string
+	for _, obj := range objects {
+		for key := range obj {
+			if !slices.Contains(expected, key) && !slices.Contains(added, key) {
+				added = append(added, key)
+			}
+		}
+	}
+	slices.Sort(added)
+	fields := make([]string, len(missing))
+	for i, name := range missing {
+		fields[i] = fmt.Sprintf("%q", name)
+		if renamed := likelyRenames(name, added); len(renamed) > 0 {
+			fields[i] += fmt.Sprintf(" (possibly renamed to %s)", strings.Join(renamed, ", "))
+		}
+	}
+	return []string{fmt.Sprintf("%s: results no longer include %s; the API may have changed, and these fields will be empty",
+		source, strings.Join(fields, ", "))}
+}
+
+// jsonFieldNames returns the JSON names of a struct type's fields.
+func jsonFieldNames(typ reflect.Type) []string {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	var names []string
+	for i := 0; i < typ.NumField(); i++ {
+		tag, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if tag != "" && tag != "-" {
+			names = append(names, tag)
+		}
+	}
+	return names
+}
+
+// likelyRenames returns the new fields sharing a distinctive name part, or
+// its first four letters, with a missing one: stars_count for
+// stargazers_count, say.
+func likelyRenames(missing string, added []string) []string {
+	var renamed []string
+	for _, candidate := range added {
+		if sharesNamePart(missing, candidate) {
+			renamed = append(renamed, candidate)
+		}
+	}
+	return renamed
+}
+
+// sharesNamePart reports whether two field names have a distinctive part
+// in common.
+func sharesNamePart(a, b string) bool {
+	for _, x := range nameParts(a) {
+		for _, y := range nameParts(b) {
+			n := min(len(x), len(y), 4)
+			if x == y || n == 4 && x[:n] == y[:n] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nameParts splits snake_case and camelCase field names into lowercase
+// parts, leaving out the generic ones.
+func nameParts(name string) []string {
+	var parts []string
+	var b strings.Builder
+	flush := func() {
+		if part := b.String(); part != "" && !slices.Contains(genericTokens, part) {
+			parts = append(parts, part)
+		}
+		b.Reset()
+	}
+	for _, r := range name {
+		switch {
+		case r == '_' || r == '-':
+			flush()
+		case unicode.IsUpper(r):
+			flush()
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	flush()
+	return parts
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// --- Dry-Run Planning ---
+
+// PlannedRequest describes a single HTTP request a search would issue.
+type PlannedRequest struct {
+	Page    int
+	Method  string
+	URL     string
+	Headers http.Header
+}
+
+// SearchPlan is what a search would do, without doing it.
+type SearchPlan struct {
+	Source   string
+	Query    string
+	Requests []PlannedRequest
+	// MaxAttempts is the worst-case number of HTTP calls, counting retries.
+	MaxAttempts int
+}
+
+// Plan builds the requests a call to Search would make for the given query,
+// without sending any of them. Tokens are redacted from the returned headers
+// and URLs, so the plan is safe to print.
+func (s *BaseRepoSearcher) Plan(query string, maxPages int) (*SearchPlan, error) {
+	if query == "" {
+		return nil, fmt.Errorf("query cannot be empty")
+	}
+	if maxPages <= 0 {
+		return nil, fmt.Errorf("maxPages must be greater than 0")
+	}
+
+	plan := &SearchPlan{Source: s.Source, Query: query}
+	keyset, isKeyset := s.implementation.(cursorPaginator)
+	for page := 1; page <= maxPages; page++ {
+		var u string
+		var err error
+		if !isKeyset {
+			u, err = s.implementation.buildSearchURL(query, page, s.PerPage)
+		} else if page == 1 {
+			u, err = keyset.buildCursorURL(query, "", s.PerPage)
+		} else {
+			// The cursor is only known once the previous page has been read.
+			u, err = keyset.buildCursorURL(query, fmt.Sprintf("<end of page %d>", page-1), s.PerPage)
+		}
+		if err == nil {
+			u, err = s.withExtraParams(u)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to build URL for page %d: %w", page, err)
+		}
+		req, err := s.implementation.buildSearchRequest(context.Background(), u)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build request for page %d: %w", page, err)
+		}
+		// Attach credentials so the plan shows where they go; they're redacted below.
+		if err := s.authorize(req); err != nil {
+			return nil, err
+		}
+		plan.Requests = append(plan.Requests, PlannedRequest{
+			Page:    page,
+			Method:  req.Method,
+			URL:     redactURL(req.URL.String()),
+			Headers: redactHeaders(req.Header),
+		})
+	}
+	plan.MaxAttempts = len(plan.Requests) * s.MaxRetries
+	return plan, nil
+}
+
+// PrintPlan writes a human-readable rendering of the plan.
+func PrintPlan(w io.Writer, plan *SearchPlan) {
+	fmt.Fprintf(w, "Dry run for %s, query %q:\n\n", plan.Source, plan.Query)
+	for _, r := range plan.Requests {
+		fmt.Fprintf(w, "Page %d: %s %s\n", r.Page, r.Method, r.URL)
+		names := make([]string, 0, len(r.Headers))
+		for name := range r.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(w, "   %s: %s\n", name, strings.Join(r.Headers[name], ", "))
+		}
+	}
+	fmt.Fprintf(w, "\nAt most %d requests (%d with retries); fewer if results run out early.\n",
+		len(plan.Requests), plan.MaxAttempts)
+}
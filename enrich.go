@@ -0,0 +1,307 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// --- Enrichment ---
+//
+// Search results carry what a provider's search endpoint returns. Enrichers
+// fill in more (languages, releases, ...) with one or more extra requests per
+// repository, through the searcher that found it, so authentication, retries,
+// middleware and the response cache all apply.
+
+// errUnsupported is returned for lookups (enrichers, repository details)
+// that a provider doesn't offer.
+var errUnsupported = errors.New("not supported by this provider")
+
+// enricher adds one kind of data to a search result.
+type enricher struct {
+	// Name is the value accepted by -enrich.
+	Name        string
+	Description string
+	Enrich      func(ctx context.Context, s *BaseRepoSearcher, item *RepositorySummary) error
+}
+
+// enrichers lists every available enricher.
+var enrichers = []enricher{
+	{Name: "languages", Description: "language breakdown by share of code", Enrich: enrichLanguages},
+	{Name: "parent", Description: "the repository a fork was forked from", Enrich: enrichParent},
+	{Name: "successor", Description: "where archived projects moved, from their README", Enrich: enrichSuccessor},
+	{Name: "governance", Description: "default branch protection and signed recent commits (GitHub)", Enrich: enrichGovernance},
+	{Name: "sbom", Description: "SPDX SBOM saved under " + sbomDir + "/, with a dependency summary (GitHub)", Enrich: enrichSBOM},
+	{Name: "ci", Description: "CI systems configured in the repository", Enrich: enrichCI},
+	{Name: "packages", Description: "Go module proxy, npm or PyPI package published from it", Enrich: enrichPackages},
+	{Name: "images", Description: "container images on GHCR (needs a read:packages token) and Docker Hub", Enrich: enrichImages},
+	{Name: "docs", Description: "whether the homepage or Pages site serves documentation", Enrich: enrichDocs},
+	{Name: "contrib", Description: "open good-first-issue and help-wanted issues", Enrich: enrichContrib},
+	{Name: "releases", Description: "latest release date and release cadence", Enrich: enrichReleases},
+	{Name: "dependents", Description: "number of repositories depending on it (GitHub)", Enrich: enrichDependents},
+}
+
+// enricherNames returns the enricher names for use in messages.
+func enricherNames() []string {
+	names := make([]string, len(enrichers))
+	for i, e := range enrichers {
+		names[i] = e.Name
+	}
+	return names
+}
+
+// resolveEnrichers looks up a comma-separated -enrich value.
+func resolveEnrichers(value string) ([]enricher, error) {
+	var selected []enricher
+	for _, name := range splitList(value) {
+		found := false
+		for _, e := range enrichers {
+			if strings.EqualFold(e.Name, name) {
+				selected = append(selected, e)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown enricher %q; available: %s", name, strings.Join(enricherNames(), ", "))
+		}
+	}
+	return selected, nil
+}
+
+// enrichJob is one item, to run every selected enricher on.
+type enrichJob struct {
+	item     *RepositorySummary
+	searcher *BaseRepoSearcher
+	throttle *throttle
+}
+
+// enrichAll runs the enrichers over items with a shared pool of workers,
+// which enrich up to workers items at once. The enrichers of one item run
+// one after the other, in the order of the enrichers list, as some read
+// what others fill in (ci reads the default branch governance finds,
+// packages the language). Each provider gets its own adaptive throttle
+// (see throttle), taken for every enricher call, so one provider running
+// out of quota doesn't hold up the others. Failures are counted and
+// reported, never fatal.
+func enrichAll(ctx context.Context, searchers []searcherTemplate, items []RepositorySummary, selected []enricher, workers int) {
+	if len(selected) == 0 || len(items) == 0 {
+		return
+	}
+	selected = slices.Clone(selected)
+	slices.SortStableFunc(selected, func(a, b enricher) int {
+		return enricherRank(a.Name) - enricherRank(b.Name)
+	})
+	bySource := make(map[string]*BaseRepoSearcher)
+	throttles := make(map[string]*throttle)
+	for _, searcher := range searchers {
+		if b, ok := baseOf(searcher); ok {
+			t := newThrottle(workers)
+			b.Use(t.Middleware())
+			bySource[b.Source] = b
+			throttles[b.Source] = t
+		}
+	}
+
+	jobs := make(chan enrichJob)
+	var mu sync.Mutex
+	failed := make(map[string]int)
+	unsupported := make(map[string]int)
+	var wg sync.WaitGroup
+	for range max(workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				for _, e := range selected {
+					err := job.throttle.acquire(ctx)
+					if err == nil {
+						err = e.Enrich(ctx, job.searcher, job.item)
+						job.throttle.release()
+					}
+					if err == nil {
+						continue
+					}
+					mu.Lock()
+					if errors.Is(err, errUnsupported) {
+						unsupported[job.searcher.Source+" "+e.Name]++
+					} else {
+						failed[e.Name]++
+						if failed[e.Name] <= 3 {
+							log.Printf("Warning: %s enrichment failed for %s: %v", e.Name, job.item.FullName, err)
+						}
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	log.Printf("Enriching %d repositories with %s...", len(items), strings.Join(namesOf(selected), ", "))
+feed:
+	for i := range items {
+		searcher, ok := bySource[items[i].Provider]
+		if !ok {
+			continue
+		}
+		select {
+		case jobs <- enrichJob{item: &items[i], searcher: searcher, throttle: throttles[items[i].Provider]}:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, key := range sortedKeys(unsupported) {
+		log.Printf("Skipped %s enrichment on %d repositories: %v.", key, unsupported[key], errUnsupported)
+	}
+	for _, name := range sortedKeys(failed) {
+		log.Printf("Warning: %s enrichment failed for %d repositories.", name, failed[name])
+	}
+}
+
+// enricherRank returns the position of the named enricher in the enrichers
+// list; others, such as the -resolve-forks upstream resolver, come last.
+func enricherRank(name string) int {
+	if i := slices.IndexFunc(enrichers, func(e enricher) bool { return e.Name == name }); i >= 0 {
+		return i
+	}
+	return len(enrichers)
+}
+
+// namesOf returns the names of the given enrichers.
+func namesOf(selected []enricher) []string {
+	names := make([]string, len(selected))
+	for i, e := range selected {
+		names[i] = e.Name
+	}
+	return names
+}
+
+// sortedKeys returns the keys of m in order, for stable log output.
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// fetchDetail GETs a provider API URL other than a search page, with the
+// searcher's authentication, retries and middleware.
+func (s *BaseRepoSearcher) fetchDetail(ctx context.Context, url string) (io.ReadCloser, error) {
+	return s.fetchWithRetries(ctx, url, 0)
+}
+
+// --- Languages ---
+
+// languagesFetcher is implemented by providers with a per-repository
+// language breakdown endpoint.
+type languagesFetcher interface {
+	languagesURL(fullName string) string
+	// parseLanguages returns each language's share of the code, in percent.
+	parseLanguages(body io.Reader) (map[string]float64, error)
+}
+
+// enrichLanguages fills in Languages, and Language if the search didn't
+// report one.
+func enrichLanguages(ctx context.Context, s *BaseRepoSearcher, item *RepositorySummary) error {
+	if len(item.Languages) > 0 {
+		return nil // Already reported by the search
+	}
+	lf, ok := s.implementation.(languagesFetcher)
+	if !ok {
+		return errUnsupported
+	}
+	body, err := s.fetchDetail(ctx, lf.languagesURL(item.FullName))
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	languages, err := lf.parseLanguages(body)
+	if err != nil {
+		return err
+	}
+
+	item.Languages = languages
+	if item.Language == "Unknown" || item.Language == "" {
+		top, share := "", 0.0
+		for lang, pct := range languages {
+			if pct > share || (pct == share && lang < top) {
+				top, share = lang, pct
+			}
+		}
+		if top != "" {
+			item.Language = intern(top)
+		}
+	}
+	return nil
+}
+
+// languageShares converts byte counts to percentages.
+func languageShares(bytes map[string]float64) map[string]float64 {
+	total := 0.0
+	for _, n := range bytes {
+		total += n
+	}
+	shares := make(map[string]float64, len(bytes))
+	for lang, n := range bytes {
+		if total > 0 {
+			shares[intern(lang)] = n * 100 / total
+		}
+	}
+	return shares
+}
+
+// languagesURL implements languagesFetcher. GitHub reports bytes of code.
+func (g *GitHubSearcher) languagesURL(fullName string) string {
+	return g.BaseURL + "/repos/" + fullName + "/languages"
+}
+
+// parseLanguages implements languagesFetcher.
+func (g *GitHubSearcher) parseLanguages(body io.Reader) (map[string]float64, error) {
+	var counts map[string]float64
+	if err := json.NewDecoder(body).Decode(&counts); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal GitHub languages: %w", err)
+	}
+	return languageShares(counts), nil
+}
+
+// languagesURL implements languagesFetcher. GitLab addresses projects by
+// their URL-encoded path and reports percentages directly.
+func (g *GitLabSearcher) languagesURL(fullName string) string {
+	return g.BaseURL + "/projects/" + url.PathEscape(fullName) + "/languages"
+}
+
+// parseLanguages implements languagesFetcher.
+func (g *GitLabSearcher) parseLanguages(body io.Reader) (map[string]float64, error) {
+	var shares map[string]float64
+	if err := json.NewDecoder(body).Decode(&shares); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal GitLab languages: %w", err)
+	}
+	return languageShares(shares), nil
+}
+
+// languagesURL implements languagesFetcher. Gitee mirrors GitHub's API.
+func (g *GiteeSearcher) languagesURL(fullName string) string {
+	return g.BaseURL + "/repos/" + fullName + "/languages"
+}
+
+// parseLanguages implements languagesFetcher.
+func (g *GiteeSearcher) parseLanguages(body io.Reader) (map[string]float64, error) {
+	var counts map[string]float64
+	if err := json.NewDecoder(body).Decode(&counts); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal Gitee languages: %w", err)
+	}
+	return languageShares(counts), nil
+}
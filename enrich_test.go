@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestEnrichAllRunsEnrichersInListOrder(t *testing.T) {
+	s := NewGitHubSearcher("", nil)
+	items := make([]RepositorySummary, 50)
+	for i := range items {
+		items[i] = RepositorySummary{Provider: s.Source, FullName: fmt.Sprintf("o/r%d", i)}
+	}
+	// Asked for in the wrong order: ci reads the branch governance finds.
+	selected := []enricher{
+		{Name: "ci", Enrich: func(_ context.Context, _ *BaseRepoSearcher, item *RepositorySummary) error {
+			item.Homepage = "ci saw " + item.DefaultBranch
+			return nil
+		}},
+		{Name: "governance", Enrich: func(_ context.Context, _ *BaseRepoSearcher, item *RepositorySummary) error {
+			item.DefaultBranch = "main"
+			return nil
+		}},
+	}
+
+	enrichAll(context.Background(), []searcherTemplate{s}, items, selected, 8)
+	for _, item := range items {
+		if item.Homepage != "ci saw main" {
+			t.Fatalf("%s: %q, want ci to run after governance", item.FullName, item.Homepage)
+		}
+	}
+}
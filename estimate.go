@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// --- Run Size Estimates ---
+//
+// A broad query with a high -pages and a few enrichers can send thousands of
+// requests and burn through a rate limit. Before such a run, one page-1
+// request per provider asks how many results there are, and the user is
+// asked to confirm if the run would go over a threshold.
+
+// defaultConfirmAbove is the request count above which a run needs
+// confirmation, unless the flag or the config file say otherwise.
+const defaultConfirmAbove = 100
+
+// runEstimate is the expected size of a search on one provider.
+type runEstimate struct {
+	Source string
+	// Total is the provider's reported number of results, or -1 if unknown.
+	Total int
+	// Pages and Items are what the run would fetch, given -pages.
+	Pages, Items int
+	// Requests counts search pages plus one request per item per enricher.
+	Requests int
+}
+
+// estimateRun sizes a search without running it, once per query (one for
+// each -matrix combination). It returns no estimates when even the worst
+// case stays within threshold, so small runs don't pay for the extra
+// requests.
+func estimateRun(ctx context.Context, searchers []searcherTemplate, queries []string, pages, enrichers, threshold int) ([]runEstimate, error) {
+	worst := 0
+	for _, searcher := range searchers {
+		if b, ok := baseOf(searcher); ok {
+			worst += pages * (1 + b.PerPage*enrichers)
+		}
+	}
+	if worst*len(queries) <= threshold {
+		return nil, nil
+	}
+
+	var estimates []runEstimate
+	for _, query := range queries {
+		for _, searcher := range searchers {
+			b, ok := baseOf(searcher)
+			if !ok {
+				continue
+			}
+			total, err := b.Estimate(ctx, query)
+			if err != nil {
+				return nil, fmt.Errorf("failed to estimate %s results: %w", b.Source, err)
+			}
+			e := runEstimate{Source: b.Source, Total: total, Pages: pages, Items: pages * b.PerPage}
+			if len(queries) > 1 {
+				e.Source += fmt.Sprintf(" (%q)", query)
+			}
+			if total >= 0 {
+				e.Pages = min(pages, max(1, (total+b.PerPage-1)/b.PerPage))
+				e.Items = min(total, e.Pages*b.PerPage)
+			}
+			e.Requests = e.Pages + e.Items*enrichers
+			estimates = append(estimates, e)
+		}
+	}
+	return estimates, nil
+}
+
+// printEstimates writes one line per provider and the total request count,
+// which it returns.
+func printEstimates(w io.Writer, estimates []runEstimate) int {
+	requests := 0
+	fmt.Fprintln(w, "Estimated run size:")
+	for _, e := range estimates {
+		total := "unknown"
+		if e.Total >= 0 {
+			total = formatCount(e.Total, false)
+		}
+		fmt.Fprintf(w, "- %s: %s results; %d pages, up to %d items, about %d requests\n", e.Source, total, e.Pages, e.Items, e.Requests)
+		requests += e.Requests
+	}
+	return requests
+}
+
+// confirmRun asks on in whether to go ahead. Anything but yes declines.
+func confirmRun(in io.Reader, out io.Writer, requests int) bool {
+	fmt.Fprintf(out, "This run would send about %d requests. Continue? [y/N] ", requests)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// checkRunSize estimates the run and, if it goes over threshold, asks for
+// confirmation unless assumeYes is set. Without a terminal to ask on, an
+// oversized run is refused. A threshold of 0 or less disables the check.
+func checkRunSize(ctx context.Context, searchers []searcherTemplate, queries []string, pages, enrichers, threshold int, assumeYes bool) error {
+	if threshold <= 0 {
+		return nil
+	}
+	estimates, err := estimateRun(ctx, searchers, queries, pages, enrichers, threshold)
+	if err != nil {
+		log.Printf("Warning: %v. Skipping the run size check.", err)
+		return nil
+	}
+	if len(estimates) == 0 {
+		return nil
+	}
+	requests := printEstimates(os.Stderr, estimates)
+	if requests <= threshold || assumeYes {
+		return nil
+	}
+	if !isTerminal(os.Stdin) {
+		return fmt.Errorf("run would send about %d requests, over the confirmation threshold of %d; pass -yes to proceed", requests, threshold)
+	}
+	if !confirmRun(os.Stdin, os.Stderr, requests) {
+		return fmt.Errorf("cancelled")
+	}
+	return nil
+}
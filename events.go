@@ -0,0 +1,55 @@
+package main
+
+import (
+	"time"
+)
+
+// --- Progress Events ---
+
+// EventKind identifies what happened during a search.
+type EventKind string
+
+const (
+	EventPageStarted      EventKind = "page-started"
+	EventPageCompleted    EventKind = "page-completed"
+	EventRetry            EventKind = "retry"
+	EventRateLimited      EventKind = "rate-limited"
+	EventProviderFinished EventKind = "provider-finished"
+)
+
+// SearchEvent reports search progress to an OnEvent callback, so GUIs and
+// other front ends can render progress without scraping the log.
+type SearchEvent struct {
+	Kind   EventKind
+	Source string
+	Page   int
+	// Items is the number of repositories on the page (page-completed) or
+	// retrieved in total (provider-finished).
+	Items int
+	// Duplicates counts the page's results already seen on earlier pages,
+	// which are not in Items, and Retrieved the repositories kept so far
+	// (page-completed).
+	Duplicates, Retrieved int
+	// RateRemaining is the provider's count of requests left in its rate
+	// limit window after the page, or -1 if it didn't say (page-completed).
+	RateRemaining int
+	// TotalCount is the provider's reported total, or -1 if unknown.
+	TotalCount int
+	// Attempt and Status describe a failed request (retry, rate-limited).
+	Attempt int
+	Status  int
+	// Delay is how long the searcher will wait before the next attempt.
+	Delay time.Duration
+	// Elapsed is the time since the page (or the whole search) started.
+	Elapsed time.Duration
+	Err     error
+}
+
+// emit delivers an event to the OnEvent callback, if one is set.
+func (s *BaseRepoSearcher) emit(e SearchEvent) {
+	if s.OnEvent == nil {
+		return
+	}
+	e.Source = s.Source
+	s.OnEvent(e)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// --- Console Columns ---
+//
+// `-fields name,stars,updated,url` replaces the multi-line console listing
+// with one aligned row per repository showing just those columns, so narrow
+// terminals and quick reviews get denser output.
+
+// descriptionWidth is where descriptions are cut in the column layout.
+const descriptionWidth = 60
+
+// displayField is a column the console printer can show.
+type displayField struct {
+	Name  string
+	value func(s RepositorySummary, opts renderOptions) string
+}
+
+// displayFields lists the -fields columns, in the order of the help text.
+var displayFields = []displayField{
+	{"name", func(s RepositorySummary, _ renderOptions) string { return s.FullName }},
+	{"url", func(s RepositorySummary, _ renderOptions) string { return s.URL }},
+	{"description", func(s RepositorySummary, _ renderOptions) string {
+		return truncate(strings.Join(strings.Fields(s.Description), " "), descriptionWidth)
+	}},
+	{"language", func(s RepositorySummary, _ renderOptions) string { return s.Language }},
+	{"stars", func(s RepositorySummary, opts renderOptions) string { return formatCount(s.Stars, opts.CompactNumbers) }},
+	{"forks", func(s RepositorySummary, opts renderOptions) string { return formatCount(s.Forks, opts.CompactNumbers) }},
+	{"issues", func(s RepositorySummary, opts renderOptions) string {
+		return formatCount(s.OpenIssuesCount, opts.CompactNumbers)
+	}},
+	{"created", func(s RepositorySummary, _ renderOptions) string { return shortDate(s.CreatedAt) }},
+	{"updated", func(s RepositorySummary, _ renderOptions) string { return shortDate(s.UpdatedAt) }},
+	{"license", func(s RepositorySummary, _ renderOptions) string { return s.License }},
+	{"topics", func(s RepositorySummary, _ renderOptions) string { return strings.Join(s.Topics, ",") }},
+	{"provider", func(s RepositorySummary, _ renderOptions) string { return s.Provider }},
+	{"tags", func(s RepositorySummary, _ renderOptions) string { return strings.Join(s.Tags, ",") }},
+	{"archived", func(s RepositorySummary, _ renderOptions) string { return strconv.FormatBool(s.IsArchived) }},
+	{"fork", func(s RepositorySummary, _ renderOptions) string { return strconv.FormatBool(s.IsFork) }},
+}
+
+// displayFieldNames returns the accepted -fields values.
+func displayFieldNames() []string {
+	names := make([]string, len(displayFields))
+	for i, f := range displayFields {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// parseFields resolves a comma-separated -fields value. "repo" and
+// "full_name" are accepted for name.
+func parseFields(value string) ([]displayField, error) {
+	var fields []displayField
+	for _, name := range splitList(value) {
+		name = strings.ToLower(name)
+		if name == "repo" || name == "full_name" {
+			name = "name"
+		}
+		i := -1
+		for j, f := range displayFields {
+			if f.Name == name {
+				i = j
+			}
+		}
+		if i < 0 {
+			return nil, fmt.Errorf("unknown field %q; must be one of %s", name, strings.Join(displayFieldNames(), ", "))
+		}
+		fields = append(fields, displayFields[i])
+	}
+	return fields, nil
+}
+
+// shortDate keeps the date part of a timestamp.
+func shortDate(ts string) string {
+	if t, ok := parseTimestamp(ts); ok {
+		return t.Format("2006-01-02")
+	}
+	return ts
+}
+
+// writeColumns writes items as aligned rows of the selected fields under a
+// header line.
+func writeColumns(w io.Writer, items []RepositorySummary, opts renderOptions) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	header := make([]string, len(opts.Fields))
+	for i, f := range opts.Fields {
+		header[i] = strings.ToUpper(f.Name)
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	row := make([]string, len(opts.Fields))
+	for _, s := range items {
+		for i, f := range opts.Fields {
+			row[i] = strings.ReplaceAll(f.value(s, opts), "\t", " ")
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// --- Result Filtering and Sorting ---
+
+// filterSummaries returns the items for which keep returns true.
+// The input slice is not modified.
+func filterSummaries(items []RepositorySummary, keep func(RepositorySummary) bool) []RepositorySummary {
+	out := make([]RepositorySummary, 0, len(items))
+	for _, item := range items {
+		if keep(item) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// markNameCollisions sets NameCollisions on every item whose short name is
+// shared with other, different repositories in the list, and returns the
+// number of distinct names that collide.
+func markNameCollisions(items []RepositorySummary) int {
+	owners := make(map[string]map[string]bool) // name -> distinct full names
+	for _, item := range items {
+		name := strings.ToLower(item.Name)
+		if owners[name] == nil {
+			owners[name] = make(map[string]bool)
+		}
+		owners[name][strings.ToLower(item.FullName)] = true
+	}
+	collisions := 0
+	for _, fullNames := range owners {
+		if len(fullNames) > 1 {
+			collisions++
+		}
+	}
+	for i := range items {
+		items[i].NameCollisions = len(owners[strings.ToLower(items[i].Name)]) - 1
+	}
+	return collisions
+}
+
+// uniqueByName keeps only the highest-ranked item for each short name, the
+// first of equals, where a ranks higher than b if better(a, b). The items
+// kept stay in place.
+func uniqueByName(items []RepositorySummary, better func(a, b RepositorySummary) bool) []RepositorySummary {
+	best := make(map[string]int) // Name to index of its best item
+	for i, item := range items {
+		name := strings.ToLower(item.Name)
+		if j, ok := best[name]; !ok || better(item, items[j]) {
+			best[name] = i
+		}
+	}
+	out := make([]RepositorySummary, 0, len(best))
+	for i, item := range items {
+		if best[strings.ToLower(item.Name)] == i {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// sortKeys maps each -sort key to a "less" function ordering items
+// best-first (e.g. most stars first, most recently updated first).
+var sortKeys = map[string]func(a, b RepositorySummary) bool{
+	"stars":   func(a, b RepositorySummary) bool { return a.Stars > b.Stars },
+	"forks":   func(a, b RepositorySummary) bool { return a.Forks > b.Forks },
+	"issues":  func(a, b RepositorySummary) bool { return a.OpenIssuesCount > b.OpenIssuesCount },
+	"updated": func(a, b RepositorySummary) bool { return a.UpdatedAt > b.UpdatedAt },
+	"created": func(a, b RepositorySummary) bool { return a.CreatedAt > b.CreatedAt },
+	"name":    func(a, b RepositorySummary) bool { return strings.ToLower(a.FullName) < strings.ToLower(b.FullName) },
+	// Ties, such as results without query words in common, keep stars order.
+	"relevance": func(a, b RepositorySummary) bool {
+		if a.Relevance != b.Relevance {
+			return a.Relevance > b.Relevance
+		}
+		return a.Stars > b.Stars
+	},
+}
+
+// sortKeyNames returns the valid sort keys for use in messages.
+func sortKeyNames() []string {
+	names := make([]string, 0, len(sortKeys))
+	for name := range sortKeys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sortSummaries sorts items in place by key. With reverse, the natural
+// order is inverted (e.g. fewest stars first). Items the key doesn't tell
+// apart are ordered by identity (see identityLess), so the order doesn't
+// depend on the order they came in.
+func sortSummaries(items []RepositorySummary, key string, reverse bool) error {
+	less, ok := sortKeys[key]
+	if !ok {
+		return fmt.Errorf("unknown sort key %q; must be one of %s", key, strings.Join(sortKeyNames(), ", "))
+	}
+	if reverse {
+		less = func(a, b RepositorySummary) bool { return sortKeys[key](b, a) }
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		switch a, b := items[i], items[j]; {
+		case less(a, b):
+			return true
+		case less(b, a):
+			return false
+		default:
+			return identityLess(a, b)
+		}
+	})
+	return nil
+}
+
+// identityLess orders repositories by provider, then full name, then URL:
+// the tiebreakers that make every ordering deterministic.
+func identityLess(a, b RepositorySummary) bool {
+	return cmp.Or(
+		cmp.Compare(a.Provider, b.Provider),
+		cmp.Compare(strings.ToLower(a.FullName), strings.ToLower(b.FullName)),
+		cmp.Compare(a.URL, b.URL),
+	) < 0
+}
+
+// stableOrder returns items in the order artifacts are written in: by the
+// -sort key if there is one, otherwise by identity, so that files from two
+// runs differ only where the results do.
+func stableOrder(items []RepositorySummary, key string) []RepositorySummary {
+	sorted := slices.Clone(items)
+	if key == "" {
+		sort.SliceStable(sorted, func(i, j int) bool { return identityLess(sorted[i], sorted[j]) })
+		return sorted
+	}
+	if err := sortSummaries(sorted, key, false); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	return sorted
+}
+
+// loadSummaries reads a JSON file written by writeJSONOutput.
+func loadSummaries(path string) ([]RepositorySummary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var items []RepositorySummary
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return items, nil
+}
+
+// marshalSummaries encodes items in the JSON output format. The encoding
+// is canonical: object keys are sorted and label lists (topics, tags, CI
+// systems) are in alphabetical order, whatever order the provider used.
+func marshalSummaries(items []RepositorySummary) ([]byte, error) {
+	canonical := slices.Clone(items)
+	for i := range canonical {
+		s := &canonical[i]
+		s.Topics, s.Tags, s.CISystems = sortedLabels(s.Topics), sortedLabels(s.Tags), sortedLabels(s.CISystems)
+	}
+	jsonData, err := json.Marshal(canonical)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal results to JSON: %w", err)
+	}
+	// Decoding into generic values and encoding again sorts the keys, as
+	// encoding/json writes map keys in order; UseNumber keeps numbers as
+	// they were written.
+	dec := json.NewDecoder(bytes.NewReader(jsonData))
+	dec.UseNumber()
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		return nil, fmt.Errorf("failed to canonicalize JSON: %w", err)
+	}
+	if jsonData, err = json.MarshalIndent(generic, "", "  "); err != nil {
+		return nil, fmt.Errorf("failed to marshal results to JSON: %w", err)
+	}
+	return jsonData, nil
+}
+
+// sortedLabels returns a sorted copy of labels.
+func sortedLabels(labels []string) []string {
+	if len(labels) == 0 {
+		return labels
+	}
+	return slices.Sorted(slices.Values(labels))
+}
+
+// writeSummaries writes items to path as indented JSON, in the same format
+// as writeJSONOutput.
+func writeSummaries(path string, items []RepositorySummary) error {
+	jsonData, err := marshalSummaries(items)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, jsonData, 0644); err != nil {
+		return fmt.Errorf("failed to write JSON to file %s: %w", path, err)
+	}
+	return nil
+}
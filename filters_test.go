@@ -0,0 +1,20 @@
+package main
+
+import "testing"
+
+func TestUniqueByNameKeepsBestInPlace(t *testing.T) {
+	items := []RepositorySummary{
+		{Name: "cli", FullName: "a/cli", Stars: 5},
+		{Name: "tui", FullName: "a/tui", Stars: 1},
+		{Name: "CLI", FullName: "b/CLI", Stars: 50},
+		{Name: "cli", FullName: "c/cli", Stars: 50},
+	}
+	got := uniqueByName(items, sortKeys["stars"])
+	if len(got) != 2 || got[0].FullName != "a/tui" || got[1].FullName != "b/CLI" {
+		t.Errorf("by stars: got %v, want a/tui then b/CLI", got)
+	}
+	got = uniqueByName(items, sortKeys["name"])
+	if len(got) != 2 || got[0].FullName != "a/cli" {
+		t.Errorf("by name: got %v, want a/cli first", got)
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// --- Forks ---
+
+// repoRef is a reference to another repository in a provider response.
+type repoRef struct {
+	FullName string `json:"full_name"`
+}
+
+// name returns the referenced full name, or "" for a nil reference.
+func (r *repoRef) name() string {
+	if r == nil {
+		return ""
+	}
+	return r.FullName
+}
+
+// mapString reads a string field from an untyped JSON object.
+func mapString(m *map[string]any, key string) string {
+	if m == nil {
+		return ""
+	}
+	s, _ := (*m)[key].(string)
+	return s
+}
+
+// enrichParent fills in ParentFullName for forks whose search result didn't
+// include it (GitHub only reports the parent for single repositories).
+func enrichParent(ctx context.Context, s *BaseRepoSearcher, item *RepositorySummary) error {
+	if !item.IsFork || item.ParentFullName != "" {
+		return nil
+	}
+	detail, err := s.fetchRepo(ctx, item.FullName, "", "")
+	if err != nil {
+		return err
+	}
+	item.ParentFullName = detail.Summary.ParentFullName
+	return nil
+}
+
+// --- Fork Graph ---
+
+// forkEdge links a fork to its parent.
+type forkEdge struct {
+	Fork, Parent string // Node IDs, "<provider>:<full_name>"
+}
+
+// forkGraph is the fork families found in a result set.
+type forkGraph struct {
+	Nodes map[string]RepositorySummary // Parents outside the results have only FullName and Provider
+	Edges []forkEdge
+}
+
+// nodeID identifies a repository across providers.
+func nodeID(provider, fullName string) string {
+	return provider + ":" + strings.ToLower(fullName)
+}
+
+// buildForkGraph collects every fork with a known parent, and the parents.
+// Repositories without fork relationships are left out.
+func buildForkGraph(items []RepositorySummary) forkGraph {
+	byID := make(map[string]RepositorySummary, len(items))
+	for _, item := range items {
+		byID[nodeID(item.Provider, item.FullName)] = item
+	}
+	g := forkGraph{Nodes: make(map[string]RepositorySummary)}
+	for _, item := range items {
+		if item.ParentFullName == "" {
+			continue
+		}
+		fork, parent := nodeID(item.Provider, item.FullName), nodeID(item.Provider, item.ParentFullName)
+		g.Nodes[fork] = item
+		if p, ok := byID[parent]; ok {
+			g.Nodes[parent] = p
+		} else if _, ok := g.Nodes[parent]; !ok {
+			g.Nodes[parent] = RepositorySummary{FullName: item.ParentFullName, Provider: item.Provider}
+		}
+		g.Edges = append(g.Edges, forkEdge{Fork: fork, Parent: parent})
+	}
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].Parent != g.Edges[j].Parent {
+			return g.Edges[i].Parent < g.Edges[j].Parent
+		}
+		return g.Edges[i].Fork < g.Edges[j].Fork
+	})
+	return g
+}
+
+// sortedIDs returns the node IDs in order, for stable output.
+func (g forkGraph) sortedIDs() []string {
+	ids := make([]string, 0, len(g.Nodes))
+	for id := range g.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// writeDOT writes the graph in Graphviz DOT format. Repositories that are
+// nobody's fork (the upstreams) are drawn bold.
+func (g forkGraph) writeDOT(w io.Writer) error {
+	forks := make(map[string]bool)
+	for _, e := range g.Edges {
+		forks[e.Fork] = true
+	}
+	var b strings.Builder
+	b.WriteString("digraph forks {\n  rankdir=RL;\n  node [shape=box];\n")
+	for _, id := range g.sortedIDs() {
+		n := g.Nodes[id]
+		label := n.FullName
+		if n.Stars > 0 {
+			label += "\n★ " + strconv.Itoa(n.Stars)
+		}
+		style := ""
+		if !forks[id] {
+			style = `, style=bold`
+		}
+		fmt.Fprintf(&b, "  %s [label=%s%s];\n", dotQuote(id), dotQuote(label), style)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s;\n", dotQuote(e.Fork), dotQuote(e.Parent))
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dotQuote quotes a DOT ID or label. Unlike Go quoting, non-ASCII text is
+// kept as is and newlines become DOT line breaks.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + strings.ReplaceAll(s, "\n", `\n`) + `"`
+}
+
+// writeGraphML writes the graph in GraphML, for tools like Gephi and yEd.
+func (g forkGraph) writeGraphML(w io.Writer) error {
+	type data struct {
+		Key   string `xml:"key,attr"`
+		Value string `xml:",chardata"`
+	}
+	type node struct {
+		ID   string `xml:"id,attr"`
+		Data []data `xml:"data"`
+	}
+	type edge struct {
+		Source string `xml:"source,attr"`
+		Target string `xml:"target,attr"`
+	}
+	type key struct {
+		ID   string `xml:"id,attr"`
+		For  string `xml:"for,attr"`
+		Name string `xml:"attr.name,attr"`
+		Type string `xml:"attr.type,attr"`
+	}
+	doc := struct {
+		XMLName xml.Name `xml:"graphml"`
+		XMLNS   string   `xml:"xmlns,attr"`
+		Keys    []key    `xml:"key"`
+		Graph   struct {
+			EdgeDefault string `xml:"edgedefault,attr"`
+			Nodes       []node `xml:"node"`
+			Edges       []edge `xml:"edge"`
+		} `xml:"graph"`
+	}{XMLNS: "http://graphml.graphdrawing.org/xmlns"}
+	doc.Keys = []key{
+		{ID: "name", For: "node", Name: "full_name", Type: "string"},
+		{ID: "provider", For: "node", Name: "provider", Type: "string"},
+		{ID: "stars", For: "node", Name: "stars", Type: "int"},
+		{ID: "url", For: "node", Name: "url", Type: "string"},
+	}
+	doc.Graph.EdgeDefault = "directed"
+	for _, id := range g.sortedIDs() {
+		n := g.Nodes[id]
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node{ID: id, Data: []data{
+			{Key: "name", Value: n.FullName},
+			{Key: "provider", Value: n.Provider},
+			{Key: "stars", Value: strconv.Itoa(n.Stars)},
+			{Key: "url", Value: n.URL},
+		}})
+	}
+	for _, e := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, edge{Source: e.Fork, Target: e.Parent})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// writeForkGraph writes the fork graph of items to path, as GraphML if the
+// file name ends in .graphml and as DOT otherwise. It returns the number of
+// edges and the path written, which differs from path when encrypting.
+func writeForkGraph(path string, items []RepositorySummary, enc *encrypter) (int, string, error) {
+	g := buildForkGraph(items)
+	var buf bytes.Buffer
+	var err error
+	if strings.EqualFold(filepath.Ext(path), ".graphml") {
+		err = g.writeGraphML(&buf)
+	} else {
+		err = g.writeDOT(&buf)
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to write fork graph: %w", err)
+	}
+	written, err := writeArtifact(path, buf.Bytes(), enc)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to write fork graph: %w", err)
+	}
+	return len(g.Edges), written, nil
+}
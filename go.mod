@@ -0,0 +1,3 @@
+module github.com/suntong/rexplorer
+
+go 1.24
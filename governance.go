@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+)
+
+// --- Governance ---
+//
+// Security teams vetting a dependency want to know whether its default
+// branch is protected and whether its commits are signed. Neither shows up
+// in search results, so the governance enricher asks for them.
+
+// governanceCommits is how many recent commits are checked for signatures.
+const governanceCommits = 20
+
+// Governance holds supply-chain hygiene signals for a repository.
+type Governance struct {
+	DefaultBranch   string `json:"default_branch"`
+	BranchProtected bool   `json:"branch_protected"`
+	// SignedCommits of the CheckedCommits most recent ones carry a verified signature.
+	SignedCommits  int `json:"signed_commits"`
+	CheckedCommits int `json:"checked_commits"`
+}
+
+// governanceFetcher is implemented by providers that report branch
+// protection and commit signature verification.
+type governanceFetcher interface {
+	branchURL(fullName, branch string) string
+	parseBranchProtected(body io.Reader) (bool, error)
+	commitsURL(fullName, branch string, n int) string
+	parseSignedCommits(body io.Reader) (signed, total int, err error)
+}
+
+// enrichGovernance fills in Governance. The default branch comes from the
+// search result, or from the repository itself if the search omitted it.
+func enrichGovernance(ctx context.Context, s *BaseRepoSearcher, item *RepositorySummary) error {
+	gf, ok := s.implementation.(governanceFetcher)
+	if !ok {
+		return errUnsupported
+	}
+	if item.DefaultBranch == "" {
+		detail, err := s.fetchRepo(ctx, item.FullName, "", "")
+		if err != nil {
+			return err
+		}
+		if detail.Summary.DefaultBranch == "" {
+			return fmt.Errorf("no default branch reported for %s", item.FullName)
+		}
+		item.DefaultBranch = detail.Summary.DefaultBranch
+	}
+	g := &Governance{DefaultBranch: item.DefaultBranch}
+
+	body, err := s.fetchDetail(ctx, gf.branchURL(item.FullName, g.DefaultBranch))
+	if err != nil {
+		return err
+	}
+	g.BranchProtected, err = gf.parseBranchProtected(body)
+	body.Close()
+	if err != nil {
+		return err
+	}
+
+	body, err = s.fetchDetail(ctx, gf.commitsURL(item.FullName, g.DefaultBranch, governanceCommits))
+	if err != nil {
+		return err
+	}
+	g.SignedCommits, g.CheckedCommits, err = gf.parseSignedCommits(body)
+	body.Close()
+	if err != nil {
+		return err
+	}
+	item.Governance = g
+	return nil
+}
+
+// branchURL implements governanceFetcher.
+func (g *GitHubSearcher) branchURL(fullName, branch string) string {
+	return g.BaseURL + "/repos/" + fullName + "/branches/" + url.PathEscape(branch)
+}
+
+// parseBranchProtected implements governanceFetcher. GitHub reports the
+// flag to anyone; the rules themselves need admin rights.
+func (g *GitHubSearcher) parseBranchProtected(body io.Reader) (bool, error) {
+	var branch struct {
+		Protected bool `json:"protected"`
+	}
+	if err := json.NewDecoder(body).Decode(&branch); err != nil {
+		return false, fmt.Errorf("failed to unmarshal GitHub branch: %w", err)
+	}
+	return branch.Protected, nil
+}
+
+// commitsURL implements governanceFetcher.
+func (g *GitHubSearcher) commitsURL(fullName, branch string, n int) string {
+	q := url.Values{"sha": {branch}, "per_page": {fmt.Sprint(n)}}
+	return g.BaseURL + "/repos/" + fullName + "/commits?" + q.Encode()
+}
+
+// parseSignedCommits implements governanceFetcher.
+func (g *GitHubSearcher) parseSignedCommits(body io.Reader) (signed, total int, err error) {
+	var commits []struct {
+		Commit struct {
+			Verification struct {
+				Verified bool `json:"verified"`
+			} `json:"verification"`
+		} `json:"commit"`
+	}
+	if err := json.NewDecoder(body).Decode(&commits); err != nil {
+		return 0, 0, fmt.Errorf("failed to unmarshal GitHub commits: %w", err)
+	}
+	for _, c := range commits {
+		if c.Commit.Verification.Verified {
+			signed++
+		}
+	}
+	return signed, len(commits), nil
+}
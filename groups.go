@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+// --- Provider Groups ---
+//
+// The config file can name sets of services, e.g.
+//
+//	"provider_groups": {"china": ["gitee", "gitcode"]}
+//
+// and -service=@china (or -service=github,@china) then searches all of
+// them, so multi-instance setups don't have to list their services every
+// time.
+
+var (
+	groupsMu sync.Mutex
+	groups   map[string][]string // nil until loaded
+)
+
+// useProviderGroups selects the groups that -service values may refer to.
+// Loading a config file selects its groups.
+func useProviderGroups(g map[string][]string) {
+	groupsMu.Lock()
+	defer groupsMu.Unlock()
+	groups = make(map[string][]string, len(g))
+	for name, members := range g {
+		groups[strings.ToLower(name)] = members
+	}
+}
+
+// providerGroup returns the members of the named group, loading the groups
+// from the default config file on first use. If that fails, the error is
+// reported once and there are no groups.
+func providerGroup(name string) ([]string, bool) {
+	groupsMu.Lock()
+	loaded := groups != nil
+	groupsMu.Unlock()
+	if !loaded {
+		cfg, err := loadConfig("")
+		if err != nil {
+			log.Printf("Warning: no provider groups: %v", err)
+			useProviderGroups(nil)
+		} else {
+			useProviderGroups(cfg.ProviderGroups)
+		}
+	}
+	groupsMu.Lock()
+	defer groupsMu.Unlock()
+	members, ok := groups[strings.ToLower(name)]
+	return members, ok
+}
+
+// validateProviderGroups checks that every group lists known services.
+func validateProviderGroups(g map[string][]string) error {
+	for name, members := range g {
+		if name == "" || strings.ContainsAny(name, "@, ") {
+			return fmt.Errorf("provider_groups: invalid group name %q", name)
+		}
+		if len(members) == 0 {
+			return fmt.Errorf("provider_groups.%s: no services listed", name)
+		}
+		for _, member := range members {
+			if strings.HasPrefix(member, "fixture:") {
+				continue
+			}
+			if _, ok := lookupProvider(member); !ok {
+				return fmt.Errorf("provider_groups.%s: unknown service %q", name, member)
+			}
+		}
+	}
+	return nil
+}
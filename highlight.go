@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// --- Highlighting ---
+//
+// With color on, the console listing highlights the query's words in each
+// repository's name and description, so it's easy to see why a result
+// matched. Qualifiers (language:go), excluded words (-foo) and the boolean
+// operators are not highlighted; other patterns, such as those of regex
+// filters, can be added to the same highlighter.
+
+// colorModes are the accepted -color values.
+var colorModes = []string{"auto", "always", "never"}
+
+// highlightStart and highlightEnd wrap highlighted text: bold yellow.
+const (
+	highlightStart = "\x1b[1;33m"
+	highlightEnd   = "\x1b[0m"
+)
+
+// useColor decides whether console output is colored. In auto mode it is
+// when stdout is a terminal and NO_COLOR isn't set.
+func useColor(mode string) (bool, error) {
+	switch strings.ToLower(mode) {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto", "":
+		return isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == "", nil
+	}
+	return false, fmt.Errorf("unknown color mode %q; must be one of %s", mode, strings.Join(colorModes, ", "))
+}
+
+// highlighter marks matches of its patterns. A nil highlighter marks
+// nothing.
+type highlighter struct {
+	patterns []*regexp.Regexp
+}
+
+// newQueryHighlighter returns a highlighter for the free-text words and
+// phrases of the queries, matched case-insensitively.
+func newQueryHighlighter(queries ...string) *highlighter {
+	var words []string
+	for _, word := range queryWords(queries...) {
+		words = append(words, regexp.QuoteMeta(word))
+	}
+	h := &highlighter{}
+	if len(words) > 0 {
+		// Longest first, so a phrase wins over a word within it.
+		slices.SortFunc(words, func(a, b string) int { return len(b) - len(a) })
+		h.add(regexp.MustCompile(`(?i)` + strings.Join(words, "|")))
+	}
+	return h
+}
+
+// queryWords returns the distinct free-text words and quoted phrases of the
+// queries, leaving out qualifiers, excluded words and boolean operators.
+func queryWords(queries ...string) []string {
+	var words []string
+	for _, query := range queries {
+		terms, err := queryTerms(query)
+		if err != nil {
+			terms = strings.Fields(query)
+		}
+		for _, term := range terms {
+			if strings.HasPrefix(term, "-") || strings.Contains(term, ":") {
+				continue
+			}
+			if term == "AND" || term == "OR" || term == "NOT" {
+				continue
+			}
+			if term = strings.Trim(term, `"()`); term != "" && !slices.Contains(words, term) {
+				words = append(words, term)
+			}
+		}
+	}
+	return words
+}
+
+// add highlights the matches of re too.
+func (h *highlighter) add(re *regexp.Regexp) {
+	h.patterns = append(h.patterns, re)
+}
+
+// mark returns s with every match highlighted. Overlapping matches of
+// different patterns are merged.
+func (h *highlighter) mark(s string) string {
+	if h == nil || len(h.patterns) == 0 || s == "" {
+		return s
+	}
+	marked := make([]bool, len(s))
+	for _, re := range h.patterns {
+		for _, m := range re.FindAllStringIndex(s, -1) {
+			for i := m[0]; i < m[1]; i++ {
+				marked[i] = true
+			}
+		}
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if marked[i] && (i == 0 || !marked[i-1]) {
+			b.WriteString(highlightStart)
+		}
+		b.WriteByte(s[i])
+		if marked[i] && (i == len(s)-1 || !marked[i+1]) {
+			b.WriteString(highlightEnd)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// --- Run History ---
+
+// historyFile is the store document recording past searches.
+const historyFile = "history.json"
+
+// runRecord describes one completed search.
+type runRecord struct {
+	Service   string    `json:"service"`
+	Query     string    `json:"query"`
+	RanAt     time.Time `json:"ran_at"` // When the search started
+	Retrieved int       `json:"retrieved"`
+}
+
+// historyKey identifies a saved search: the same services and query.
+func historyKey(service, query string) string {
+	return strings.ToLower(service) + "\n" + query
+}
+
+// loadHistory reads the run history, keyed by historyKey.
+func loadHistory() (map[string]runRecord, error) {
+	history := map[string]runRecord{}
+	if err := readStoreJSON(historyFile, &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// lastRun returns the previous run of the same search, if there was one.
+func lastRun(service, query string) (runRecord, bool, error) {
+	history, err := loadHistory()
+	if err != nil {
+		return runRecord{}, false, err
+	}
+	rec, ok := history[historyKey(service, query)]
+	return rec, ok, nil
+}
+
+// recordRun stores rec as the latest run of its search.
+func recordRun(rec runRecord) error {
+	history := map[string]runRecord{}
+	return updateStoreJSON(historyFile, &history, func() error {
+		history[historyKey(rec.Service, rec.Query)] = rec
+		return nil
+	})
+}
+
+// updatedSince keeps the items updated at or after since. Providers that
+// can't filter server-side return everything, so this also applies to them.
+// Items with an unreadable timestamp are kept.
+func updatedSince(items []RepositorySummary, since time.Time) []RepositorySummary {
+	return filterSummaries(items, func(s RepositorySummary) bool {
+		t, ok := parseTimestamp(s.UpdatedAt)
+		return !ok || !t.Before(since)
+	})
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// --- Importing Results ---
+//
+// Results saved before the history store existed, or by someone else, can
+// be brought in with `rexplorer import Out-GitHub.json -query "..."`. The
+// run is recorded in the history like a search of its own, and the results
+// are kept as a snapshot under the data directory for later comparison.
+
+// snapshotsDir is the store directory holding imported result snapshots,
+// one subdirectory per search (see snapshotPath). Snapshots stay in the
+// local data directory whatever the store backend, as they can be large.
+const snapshotsDir = "snapshots"
+
+// resultSnapshot is the results of one run of a search.
+type resultSnapshot struct {
+	Service string    `json:"service"`
+	Query   string    `json:"query"`
+	RanAt   time.Time `json:"ran_at"`
+	// ImportedFrom is the file the results came from.
+	ImportedFrom string              `json:"imported_from,omitempty"`
+	Items        []RepositorySummary `json:"items"`
+}
+
+// snapshotPath returns where the snapshot of a run is stored: the search's
+// directory is named after a hash of its history key, and the file after
+// the run's start time, so snapshots sort chronologically.
+func snapshotPath(service, query string, ranAt time.Time) (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(historyKey(service, query)))
+	return filepath.Join(dir, snapshotsDir, hex.EncodeToString(sum[:8]), ranAt.UTC().Format(runDirLayout)+".json"), nil
+}
+
+// saveSnapshot stores snap, replacing any snapshot of the same run.
+func saveSnapshot(snap resultSnapshot) (string, error) {
+	path, err := snapshotPath(snap.Service, snap.Query, snap.RanAt)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return path, nil
+}
+
+// importedService works out the -service of saved results from the
+// providers recorded on the items. It fails if they came from several.
+func importedService(items []RepositorySummary) (string, error) {
+	var sources []string
+	for _, item := range items {
+		if item.Provider != "" && !slices.Contains(sources, item.Provider) {
+			sources = append(sources, item.Provider)
+		}
+	}
+	if len(sources) != 1 {
+		return "", fmt.Errorf("can't tell which service the results came from; pass -service")
+	}
+	p, ok := lookupProvider(strings.ReplaceAll(sources[0], " ", "-"))
+	if !ok {
+		return "", fmt.Errorf("unknown provider %q in results; pass -service", sources[0])
+	}
+	return p.Name, nil
+}
+
+// importedRanAt works out when saved results were produced: from the
+// provenance sidecar if there is one, otherwise from the file's
+// modification time.
+func importedRanAt(path string) (time.Time, error) {
+	var prov Provenance
+	data, err := os.ReadFile(path + provenanceSuffix)
+	if err == nil && json.Unmarshal(data, &prov) == nil && !prov.StartedAt.IsZero() {
+		return prov.StartedAt, nil
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return time.Time{}, fmt.Errorf("failed to read provenance: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return info.ModTime(), nil
+}
+
+// runImport implements `rexplorer import <file.json>...`.
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	query := fs.String("query", "", "The query that produced the results (required)")
+	service := fs.String("service", "", "Service the results came from (default: taken from the results)")
+	ranAt := fs.String("ran-at", "", "When the search ran, as RFC 3339 or YYYY-MM-DD (default: from the provenance sidecar or the file's modification time)")
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 || *query == "" {
+		return fmt.Errorf("usage: rexplorer import <file.json>... -query text [-service name] [-ran-at time]")
+	}
+	if *service != "" {
+		if _, ok := lookupProvider(*service); !ok {
+			return fmt.Errorf("unknown service %q", *service)
+		}
+	}
+	var at time.Time
+	if *ranAt != "" {
+		t, ok := parseTimestamp(*ranAt)
+		if !ok {
+			return fmt.Errorf("invalid -ran-at %q", *ranAt)
+		}
+		at = t
+	}
+
+	history, err := loadHistory()
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		items, err := loadSummaries(file)
+		if err != nil {
+			return err
+		}
+		snap := resultSnapshot{Service: *service, Query: *query, RanAt: at, ImportedFrom: file, Items: items}
+		if snap.Service == "" {
+			if snap.Service, err = importedService(items); err != nil {
+				return fmt.Errorf("%s: %w", file, err)
+			}
+		}
+		if snap.RanAt.IsZero() {
+			if snap.RanAt, err = importedRanAt(file); err != nil {
+				return err
+			}
+		}
+		snap.RanAt = snap.RanAt.UTC()
+		path, err := saveSnapshot(snap)
+		if err != nil {
+			return err
+		}
+		// An older import must not move -since-last-run backwards.
+		key := historyKey(snap.Service, snap.Query)
+		if rec, ok := history[key]; !ok || rec.RanAt.Before(snap.RanAt) {
+			history[key] = runRecord{Service: snap.Service, Query: snap.Query, RanAt: snap.RanAt, Retrieved: len(items)}
+		}
+		fmt.Printf("Imported %d repositories from %s (%s, %s) to %s\n", len(items), file, snap.Service, snap.RanAt.Format(time.RFC3339), path)
+	}
+	return writeStoreJSON(historyFile, history)
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// --- Provider Instances ---
+//
+// The config file can name self-hosted instances of a provider, each with
+// its own base URL and token, e.g.
+//
+//	"instances": {
+//	  "gitlab-internal": {"type": "gitlab", "url": "https://gitlab.corp.example", "token_env": "CORP_GITLAB_TOKEN"},
+//	  "gitlab-oss":      {"type": "gitlab", "url": "https://gitlab.gnome.org"}
+//	}
+//
+// Each is then a service of its own: -service=gitlab-internal searches one,
+// -service=gitlab-internal,gitlab-oss (or a provider group) searches them
+// together, and results are labelled with the instance name.
+
+// InstanceConfig is one named instance of a provider.
+type InstanceConfig struct {
+	// Type is the provider the instance runs, one of instanceTypes.
+	Type string `json:"type"`
+	// URL is the instance's web address, e.g. https://gitlab.example.com.
+	URL string `json:"url"`
+	// TokenEnv is the environment variable holding the instance's token;
+	// empty means the provider's usual one (e.g. GITLAB_TOKEN).
+	TokenEnv string `json:"token_env,omitempty"`
+}
+
+// instanceTypes maps the providers that can be self-hosted to how a
+// searcher is pointed at an instance's URL.
+var instanceTypes = map[string]func(searcherTemplate, string){
+	"github": func(s searcherTemplate, u string) {
+		// GitHub Enterprise Server serves the REST API under /api/v3.
+		if b, ok := baseOf(s); ok {
+			b.BaseURL = u + "/api/v3"
+		}
+	},
+	"gitlab":         func(s searcherTemplate, u string) { gitLabOptions{URL: u}.apply(s) },
+	"gitlab-graphql": func(s searcherTemplate, u string) { gitLabOptions{URL: u}.apply(s) },
+	"codeberg": func(s searcherTemplate, u string) {
+		// Any Forgejo or Gitea server serves Codeberg's API under /api/v1.
+		if b, ok := baseOf(s); ok {
+			b.BaseURL = u + "/api/v1"
+		}
+	},
+}
+
+var (
+	instancesMu sync.Mutex
+	instances   map[string]providerInfo // nil until loaded
+)
+
+// useInstances selects the instances that -service values may name.
+// Loading a config file selects its instances.
+func useInstances(cfg map[string]InstanceConfig) {
+	instancesMu.Lock()
+	defer instancesMu.Unlock()
+	instances = make(map[string]providerInfo, len(cfg))
+	for name, inst := range cfg {
+		if p, ok := inst.provider(name); ok {
+			instances[strings.ToLower(name)] = p
+		}
+	}
+}
+
+// lookupInstance finds a configured instance by name, loading the instances
+// from the default config file on first use. If that fails, the error is
+// reported once and there are no instances.
+func lookupInstance(name string) (providerInfo, bool) {
+	instancesMu.Lock()
+	loaded := instances != nil
+	instancesMu.Unlock()
+	if !loaded {
+		cfg, err := loadConfig("")
+		if err != nil {
+			log.Printf("Warning: no instances: %v", err)
+			useInstances(nil)
+		} else {
+			useInstances(cfg.Instances)
+		}
+	}
+	instancesMu.Lock()
+	defer instancesMu.Unlock()
+	p, ok := instances[strings.ToLower(name)]
+	return p, ok
+}
+
+// instanceNames returns the names of the configured instances, sorted.
+func instanceNames() []string {
+	lookupInstance("") // Loads the instances
+	instancesMu.Lock()
+	defer instancesMu.Unlock()
+	names := make([]string, 0, len(instances))
+	for _, p := range instances {
+		names = append(names, p.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// provider describes the instance as a service: the provider it runs,
+// with its own name, web host, token variable and base URL.
+func (inst InstanceConfig) provider(name string) (providerInfo, bool) {
+	base, ok := builtinProvider(inst.Type)
+	point, ok2 := instanceTypes[strings.ToLower(inst.Type)]
+	u, err := url.Parse(inst.URL)
+	if !ok || !ok2 || err != nil {
+		return providerInfo{}, false
+	}
+	root := strings.TrimSuffix(inst.URL, "/")
+
+	p := base
+	p.Name, p.Runs = name, base.Name
+	p.WebHost = strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if inst.TokenEnv != "" {
+		p.TokenEnv = inst.TokenEnv
+		p.MissingToken = fmt.Sprintf("%s not set. Using unauthenticated requests to %s.", inst.TokenEnv, name)
+		if base.TokenRequired {
+			p.MissingToken = fmt.Sprintf("%s environment variable not set.", inst.TokenEnv)
+		}
+	}
+	p.New = func(t string, c *http.Client) searcherTemplate {
+		searcher := base.New(t, c)
+		point(searcher, root)
+		if b, ok := baseOf(searcher); ok {
+			b.Source = name
+		}
+		return searcher
+	}
+	return p, true
+}
+
+// validateInstances checks that every instance names a provider that can be
+// self-hosted, a usable URL, and a name that doesn't hide a built-in service.
+func validateInstances(cfg map[string]InstanceConfig) error {
+	for name, inst := range cfg {
+		if name == "" || strings.ContainsAny(name, "@:, ") {
+			return fmt.Errorf("instances: invalid instance name %q", name)
+		}
+		if _, ok := builtinProvider(name); ok || strings.EqualFold(name, "all") {
+			return fmt.Errorf("instances: %q is already a service name", name)
+		}
+		if _, ok := instanceTypes[strings.ToLower(inst.Type)]; !ok {
+			types := make([]string, 0, len(instanceTypes))
+			for t := range instanceTypes {
+				types = append(types, t)
+			}
+			sort.Strings(types)
+			return fmt.Errorf("instances.%s: unknown type %q; must be one of %s", name, inst.Type, strings.Join(types, ", "))
+		}
+		if u, err := url.Parse(inst.URL); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("instances.%s: invalid URL %q", name, inst.URL)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// --- Result-Set Integrity ---
+//
+// A provider whose search index changes while we page through it returns
+// skewed results: short pages in the middle of a crawl, a total that
+// shrinks from one page to the next, or pages full of results already
+// seen. None of this fails the search, but each is reported as a warning so
+// the results aren't taken at face value.
+
+// duplicateHeavyShare is the share of a page's results that, once already
+// seen on earlier pages, makes the page suspicious.
+const duplicateHeavyShare = 0.5
+
+// integrityCheck watches the pages of one search for anomalies.
+type integrityCheck struct {
+	source  string
+	perPage int
+	// filtered is set when pages may be short by design, as some providers
+	// drop results that fail the activity filter while parsing them.
+	filtered bool
+	total    int   // Last total reported, or 0 if none yet
+	short    []int // Pages with fewer results than requested but more to come
+	shrunk   []string
+	dupes    []int // Pages mostly made of duplicates
+}
+
+// page records one fetched page: how many results it held, how many of
+// them were duplicates, the total it reported (0 or less if unknown) and
+// whether more pages follow. Some providers report more pages whenever a
+// page has results, so a page reaching the reported total is taken as the
+// last one.
+func (c *integrityCheck) page(page, fetched, duplicates, total int, hasMore bool) {
+	hasMore = hasMore && (total <= 0 || page*c.perPage < total)
+	if hasMore && !c.filtered && fetched > 0 && fetched < c.perPage {
+		c.short = append(c.short, page)
+	}
+	if total > 0 {
+		if c.total > 0 && total < c.total {
+			c.shrunk = append(c.shrunk, fmt.Sprintf("%d to %d on page %d", c.total, total, page))
+		}
+		c.total = total
+	}
+	if fetched > 0 && float64(duplicates) >= duplicateHeavyShare*float64(fetched) {
+		c.dupes = append(c.dupes, page)
+	}
+}
+
+// warnings describes the anomalies seen, one warning per kind.
+func (c *integrityCheck) warnings() []string {
+	var warnings []string
+	if len(c.short) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%s: %s returned fewer than %d results though more followed; the search index may have changed during the crawl",
+			c.source, pageList(c.short), c.perPage))
+	}
+	if len(c.shrunk) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%s: the reported total shrank between pages (%s); results may be skewed",
+			c.source, strings.Join(c.shrunk, ", ")))
+	}
+	if len(c.dupes) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%s: %s mostly repeated earlier results; the search index may have changed during the crawl",
+			c.source, pageList(c.dupes)))
+	}
+	return warnings
+}
+
+// pageList renders page numbers: "page 3" or "pages 3, 5".
+func pageList(pages []int) string {
+	nums := make([]string, len(pages))
+	for i, p := range pages {
+		nums[i] = strconv.Itoa(p)
+	}
+	if len(pages) == 1 {
+		return "page " + nums[0]
+	}
+	return "pages " + strings.Join(nums, ", ")
+}
@@ -0,0 +1,38 @@
+package main
+
+import "sync"
+
+// --- String Interning ---
+//
+// Big crawls repeat the same few hundred languages, licenses and topics
+// thousands of times. Interning them while parsing lets every result share
+// one copy of each string instead of one per decoded page.
+
+// maxInterned bounds the intern table; past it, strings are returned as is.
+const maxInterned = 8192
+
+var (
+	internMu    sync.Mutex
+	internTable = make(map[string]string)
+)
+
+// intern returns a canonical copy of s.
+func intern(s string) string {
+	internMu.Lock()
+	defer internMu.Unlock()
+	if v, ok := internTable[s]; ok {
+		return v
+	}
+	if len(internTable) < maxInterned {
+		internTable[s] = s
+	}
+	return s
+}
+
+// internAll interns every string in ss in place and returns ss.
+func internAll(ss []string) []string {
+	for i, s := range ss {
+		ss[i] = intern(s)
+	}
+	return ss
+}
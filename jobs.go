@@ -0,0 +1,456 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --- Search Jobs ---
+//
+// A crawl of many pages can outlast any HTTP client's patience, so serve
+// mode also takes searches as jobs: POST /api/jobs queues one and returns
+// its ID at once, GET /api/jobs/{id} reports whether it is pending, running
+// or finished, and GET /api/jobs/{id}/result returns its results once it
+// is (or GET /api/results/{id} a page of them; see results.go). Each job is kept as a file under -jobs-dir, so queued jobs survive a
+// restart (jobs that were running start over) and results stay retrievable
+// until -job-retention has passed.
+
+// Job states.
+const (
+	jobPending   = "pending"
+	jobRunning   = "running"
+	jobDone      = "done"
+	jobFailed    = "failed"
+	jobCancelled = "cancelled"
+)
+
+// maxPendingJobs is how many jobs a tenant may have waiting at once.
+const maxPendingJobs = 20
+
+// errTooManyJobs refuses jobs beyond maxPendingJobs.
+var errTooManyJobs = errors.New("too many jobs waiting")
+
+// job is one queued search.
+type job struct {
+	ID          string        `json:"id"`
+	Tenant      string        `json:"tenant"`
+	Name        string        `json:"name,omitempty"` // Saved search, if any
+	Search      SavedSearch   `json:"search"`
+	Status      string        `json:"status"`
+	SubmittedAt time.Time     `json:"submitted_at"`
+	StartedAt   *time.Time    `json:"started_at,omitempty"`
+	FinishedAt  *time.Time    `json:"finished_at,omitempty"`
+	Items       int           `json:"items"`
+	Error       string        `json:"error,omitempty"`
+	Result      *SearchResult `json:"result,omitempty"`
+}
+
+// finished reports whether the job has stopped for good.
+func (j *job) finished() bool {
+	return j.Status == jobDone || j.Status == jobFailed || j.Status == jobCancelled
+}
+
+// summary is the job as listed by the API, without its results.
+func (j *job) summary() job {
+	v := *j
+	v.Result = nil
+	return v
+}
+
+// jobQueue holds the jobs, and a file for each in dir.
+type jobQueue struct {
+	dir       string
+	retention time.Duration // Finished jobs are dropped after this; 0 keeps them
+
+	mu      sync.Mutex
+	jobs    map[string]*job
+	cancels map[string]context.CancelFunc // Running jobs
+	wake    chan struct{}
+}
+
+// openJobQueue loads the jobs kept in dir. Jobs that were running when the
+// server stopped are queued again.
+func openJobQueue(dir string, retention time.Duration, workers int) (*jobQueue, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create jobs directory: %w", err)
+	}
+	q := &jobQueue{
+		dir: dir, retention: retention,
+		jobs: make(map[string]*job), cancels: make(map[string]context.CancelFunc),
+		wake: make(chan struct{}, workers),
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read job: %w", err)
+		}
+		var j job
+		if err := json.Unmarshal(data, &j); err != nil || j.ID == "" {
+			log.Printf("Warning: skipping unreadable job %s", path)
+			continue
+		}
+		if j.Status == jobRunning {
+			log.Printf("%s: job %s was interrupted; queued again", j.Tenant, j.ID)
+			j.Status, j.StartedAt = jobPending, nil
+			if err := q.save(&j); err != nil {
+				return nil, err
+			}
+		}
+		q.jobs[j.ID] = &j
+	}
+	q.prune(time.Now())
+	return q, nil
+}
+
+// path is where a job is kept.
+func (q *jobQueue) path(id string) string {
+	return filepath.Join(q.dir, id+".json")
+}
+
+// save writes a job's file. q.mu must be held, or the job not yet shared.
+func (q *jobQueue) save(j *job) error {
+	data, err := json.Marshal(j)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job: %w", err)
+	}
+	path := q.path(j.ID)
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
+
+// prune drops the jobs that finished more than the retention period ago.
+func (q *jobQueue) prune(now time.Time) {
+	if q.retention <= 0 {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for id, j := range q.jobs {
+		if j.finished() && j.FinishedAt != nil && now.Sub(*j.FinishedAt) > q.retention {
+			q.remove(id)
+		}
+	}
+}
+
+// remove forgets a job and deletes its file. q.mu must be held.
+func (q *jobQueue) remove(id string) {
+	delete(q.jobs, id)
+	if err := os.Remove(q.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("Warning: failed to delete job %s: %v", id, err)
+	}
+}
+
+// signal wakes a worker, if one is waiting.
+func (q *jobQueue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// newJobID returns a random job ID.
+func newJobID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// submit queues a search for the tenant.
+func (q *jobQueue) submit(tenantName, name string, search SavedSearch) (*job, error) {
+	j := &job{
+		ID: newJobID(), Tenant: tenantName, Name: name, Search: search,
+		Status: jobPending, SubmittedAt: time.Now().UTC(),
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	pending := 0
+	for _, other := range q.jobs {
+		if other.Tenant == tenantName && other.Status == jobPending {
+			pending++
+		}
+	}
+	if pending >= maxPendingJobs {
+		return nil, fmt.Errorf("%w: %d are queued; try again when some have run", errTooManyJobs, pending)
+	}
+	if err := q.save(j); err != nil {
+		return nil, err
+	}
+	q.jobs[j.ID] = j
+	q.signal()
+	return j, nil
+}
+
+// next claims the oldest pending job, and marks it running. It returns nil
+// if none is pending.
+func (q *jobQueue) next(ctx context.Context) (*job, context.Context) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var oldest *job
+	pending := 0
+	for _, j := range q.jobs {
+		if j.Status != jobPending {
+			continue
+		}
+		pending++
+		if oldest == nil || j.SubmittedAt.Before(oldest.SubmittedAt) ||
+			j.SubmittedAt.Equal(oldest.SubmittedAt) && j.ID < oldest.ID {
+			oldest = j
+		}
+	}
+	if oldest == nil {
+		return nil, nil
+	}
+	if pending > 1 {
+		q.signal()
+	}
+	now := time.Now().UTC()
+	oldest.Status, oldest.StartedAt = jobRunning, &now
+	if err := q.save(oldest); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	q.cancels[oldest.ID] = cancel
+	return oldest, ctx
+}
+
+// finish records how a job ended.
+func (q *jobQueue) finish(j *job, result *SearchResult, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	cancelled := j.Status == jobCancelled
+	if cancel := q.cancels[j.ID]; cancel != nil {
+		cancel()
+		delete(q.cancels, j.ID)
+	}
+	now := time.Now().UTC()
+	j.FinishedAt, j.Result = &now, result
+	switch {
+	case cancelled:
+		if result != nil {
+			result.Warnings = addWarning(result.Warnings, "job cancelled on request; results are partial")
+		}
+	case err != nil:
+		j.Status = jobFailed
+	default:
+		j.Status = jobDone
+	}
+	if err != nil {
+		j.Error = redactText(err.Error())
+	}
+	if result != nil {
+		j.Items = len(result.Items)
+	}
+	if err := q.save(j); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+// get returns a copy of one of the tenant's jobs.
+func (q *jobQueue) get(tenantName, id string) (job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j, ok := q.jobs[id]
+	if !ok || j.Tenant != tenantName {
+		return job{}, false
+	}
+	return *j, true
+}
+
+// list returns the tenant's jobs, newest first, without their results.
+func (q *jobQueue) list(tenantName string) []job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	list := []job{}
+	for _, j := range q.jobs {
+		if j.Tenant == tenantName {
+			list = append(list, j.summary())
+		}
+	}
+	slices.SortFunc(list, func(a, b job) int {
+		return cmp.Or(b.SubmittedAt.Compare(a.SubmittedAt), strings.Compare(a.ID, b.ID))
+	})
+	return list
+}
+
+// cancel stops one of the tenant's unfinished jobs, or deletes a finished
+// one. It returns the job's state afterwards, or false if the tenant has
+// no such job.
+func (q *jobQueue) cancel(tenantName, id string) (string, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j, ok := q.jobs[id]
+	if !ok || j.Tenant != tenantName {
+		return "", false
+	}
+	switch j.Status {
+	case jobPending:
+		now := time.Now().UTC()
+		j.Status, j.FinishedAt = jobCancelled, &now
+		if err := q.save(j); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+		return jobCancelled, true
+	case jobRunning:
+		j.Status = jobCancelled
+		q.cancels[id]()
+		return "cancelling", true
+	default:
+		q.remove(id)
+		return "deleted", true
+	}
+}
+
+// runJobs runs queued jobs, workers at a time, until ctx is done.
+func (s *server) runJobs(ctx context.Context, workers int) {
+	for i := 0; i < workers; i++ {
+		go func() {
+			for {
+				if j, jobCtx := s.jobs.next(ctx); j != nil {
+					s.runJob(jobCtx, j)
+					continue
+				}
+				select {
+				case <-s.jobs.wake:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	s.jobs.signal()
+}
+
+// runJob runs one job as its tenant.
+func (s *server) runJob(ctx context.Context, j *job) {
+	t, ok := s.tenantNamed(j.Tenant)
+	if !ok {
+		s.jobs.finish(j, nil, fmt.Errorf("tenant %s is no longer configured", j.Tenant))
+		return
+	}
+	searchers, err := s.tenantSearchers(t, j.Search)
+	if err != nil {
+		s.jobs.finish(j, nil, err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.jobTimeout)
+	defer cancel()
+	result, err := s.search(ctx, t, j.Name, searchers, j.Search)
+	s.jobs.finish(j, result, err)
+	log.Printf("%s: job %s %s with %d repositories", t.Name, j.ID, j.Status, j.Items)
+	s.jobs.prune(time.Now())
+}
+
+// tenantNamed returns the tenant with the given name.
+func (s *server) tenantNamed(name string) (*tenant, bool) {
+	for _, t := range s.tenants {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// handleJobs serves the job API:
+//
+//	POST   /api/jobs?service=github&q=...&pages=3  queue an ad hoc search
+//	POST   /api/jobs?search=name                   queue a saved search
+//	GET    /api/jobs                               list the tenant's jobs
+//	GET    /api/jobs/{id}                          a job's state
+//	GET    /api/jobs/{id}/result                   a finished job's results
+//	DELETE /api/jobs/{id}                          cancel a job, or delete a finished one
+func (s *server) handleJobs(w http.ResponseWriter, r *http.Request, t *tenant) {
+	id, action, _ := strings.Cut(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/jobs"), "/"), "/")
+	switch {
+	case id == "" && r.Method == http.MethodPost:
+		s.submitJob(w, r, t)
+	case id == "" && r.Method == http.MethodGet:
+		writeAPIJSON(w, http.StatusOK, s.jobs.list(t.Name))
+	case id != "" && r.Method == http.MethodGet && (action == "" || action == "result"):
+		j, ok := s.jobs.get(t.Name, id)
+		switch {
+		case !ok:
+			writeAPIError(w, http.StatusNotFound, fmt.Sprintf("no job %q", id))
+		case action == "":
+			writeAPIJSON(w, http.StatusOK, j.summary())
+		case !j.finished():
+			writeAPIError(w, http.StatusConflict, fmt.Sprintf("job %s is %s; poll GET /api/jobs/%s until it finishes", id, j.Status, id))
+		case j.Result == nil:
+			writeAPIError(w, http.StatusConflict, fmt.Sprintf("job %s %s without results: %s", id, j.Status, j.Error))
+		default:
+			writeAPIJSON(w, http.StatusOK, j.Result)
+		}
+	case id != "" && action == "" && r.Method == http.MethodDelete:
+		status, ok := s.jobs.cancel(t.Name, id)
+		if !ok {
+			writeAPIError(w, http.StatusNotFound, fmt.Sprintf("no job %q", id))
+			return
+		}
+		writeAPIJSON(w, http.StatusAccepted, map[string]string{"id": id, "status": status})
+	default:
+		writeAPIError(w, http.StatusNotFound, "not found; use POST /api/jobs, GET /api/jobs[/{id}[/result]] or DELETE /api/jobs/{id}")
+	}
+}
+
+// submitJob queues the search the request describes.
+func (s *server) submitJob(w http.ResponseWriter, r *http.Request, t *tenant) {
+	q := r.URL.Query()
+	name := q.Get("search")
+	var search SavedSearch
+	if name != "" {
+		saved, ok := t.Searches[name]
+		if !ok {
+			writeAPIError(w, http.StatusNotFound, fmt.Sprintf("no saved search %q", name))
+			return
+		}
+		search = saved
+	} else {
+		pages, _ := strconv.Atoi(q.Get("pages"))
+		search = SavedSearch{Service: q.Get("service"), Query: q.Get("q"), Pages: pages}
+	}
+	if search.Query == "" {
+		writeAPIError(w, http.StatusBadRequest, "a query is required")
+		return
+	}
+	if !s.checkBudget(w, t, search) {
+		return
+	}
+	if _, err := s.tenantSearchers(t, search); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	j, err := s.jobs.submit(t.Name, name, search)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errTooManyJobs) {
+			status = http.StatusTooManyRequests
+		}
+		writeAPIError(w, status, err.Error())
+		return
+	}
+	log.Printf("%s: queued job %s: %s for %q", t.Name, j.ID, cmp.Or(search.Service, "github"), search.Query)
+	w.Header().Set("Location", "/api/jobs/"+j.ID)
+	writeAPIJSON(w, http.StatusAccepted, j.summary())
+}
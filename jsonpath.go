@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// --- JSONPath ---
+//
+// A small JSONPath subset for picking values out of provider responses:
+// $ is the root, .name and ['name'] select a member (\ escapes a quote in
+// the name), [n] an array element, and [*] (or .*) every element. Filters
+// and recursive descent aren't supported; provider responses are shallow
+// enough not to need them.
+
+// jsonPath is a compiled expression.
+type jsonPath struct {
+	expr  string
+	steps []pathStep
+}
+
+// pathStep selects a member (key), an element (index), or every element.
+type pathStep struct {
+	key   string
+	index int
+	elem  bool // index is set
+	all   bool
+}
+
+// compileJSONPath parses an expression such as $.owner.login or
+// $.topics[*].
+func compileJSONPath(expr string) (jsonPath, error) {
+	p := jsonPath{expr: expr}
+	rest, ok := strings.CutPrefix(strings.TrimSpace(expr), "$")
+	if !ok {
+		return p, fmt.Errorf("invalid JSONPath %q: must start with $", expr)
+	}
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "[") && isQuote(strings.TrimSpace(rest[1:])):
+			// A quoted name may hold ] itself, so find its closing quote first.
+			key, after, err := cutQuoted(strings.TrimSpace(rest[1:]))
+			if err != nil {
+				return p, fmt.Errorf("invalid JSONPath %q: %w", expr, err)
+			}
+			after, ok = strings.CutPrefix(strings.TrimSpace(after), "]")
+			if !ok {
+				return p, fmt.Errorf("invalid JSONPath %q: expected ] after %q", expr, key)
+			}
+			rest = after
+			p.steps = append(p.steps, pathStep{key: key})
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return p, fmt.Errorf("invalid JSONPath %q: unclosed [", expr)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			switch {
+			case inner == "*":
+				p.steps = append(p.steps, pathStep{all: true})
+			default:
+				n, err := strconv.Atoi(inner)
+				if err != nil || n < 0 {
+					return p, fmt.Errorf("invalid JSONPath %q: %q is not an index, * or a quoted name", expr, inner)
+				}
+				p.steps = append(p.steps, pathStep{index: n, elem: true})
+			}
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			rest = rest[end:]
+			if name == "" {
+				return p, fmt.Errorf("invalid JSONPath %q: empty member name", expr)
+			}
+			if name == "*" {
+				p.steps = append(p.steps, pathStep{all: true})
+			} else {
+				p.steps = append(p.steps, pathStep{key: name})
+			}
+		default:
+			return p, fmt.Errorf("invalid JSONPath %q: expected . or [ at %q", expr, rest)
+		}
+	}
+	return p, nil
+}
+
+// isQuote reports whether s starts with a quote.
+func isQuote(s string) bool {
+	return s != "" && (s[0] == '\'' || s[0] == '"')
+}
+
+// cutQuoted splits s, which starts with a quote, after the matching closing
+// quote, returning the name between them. A backslash escapes the next
+// character.
+func cutQuoted(s string) (name, rest string, err error) {
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s):
+			i++
+			b.WriteByte(s[i])
+		case c == quote:
+			return b.String(), s[i+1:], nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", "", fmt.Errorf("unclosed quote in %s", s)
+}
+
+// String returns the expression as written.
+func (p jsonPath) String() string { return p.expr }
+
+// multiple reports whether the expression can select several values.
+func (p jsonPath) multiple() bool {
+	for _, s := range p.steps {
+		if s.all {
+			return true
+		}
+	}
+	return false
+}
+
+// eval returns the values the expression selects in a decoded JSON
+// document, in document order. Missing members select nothing.
+func (p jsonPath) eval(doc any) []any {
+	values := []any{doc}
+	for _, step := range p.steps {
+		var next []any
+		for _, v := range values {
+			switch v := v.(type) {
+			case map[string]any:
+				if step.all {
+					for _, key := range slices.Sorted(maps.Keys(v)) {
+						next = append(next, v[key])
+					}
+				} else if m, ok := v[step.key]; ok && !step.elem {
+					next = append(next, m)
+				}
+			case []any:
+				if step.all {
+					next = append(next, v...)
+				} else if step.elem && step.index < len(v) {
+					next = append(next, v[step.index])
+				}
+			}
+		}
+		values = next
+	}
+	return values
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestJSONPathEval(t *testing.T) {
+	var doc any
+	json.Unmarshal([]byte(`{
+		"owner": {"login": "octo", "a]b": 1, "it's": 2},
+		"topics": ["cli", "tui"],
+		"items": [{"id": 1}, {"id": 2}, {"name": "x"}],
+		"labels": {"b": 2, "a": 1}
+	}`), &doc)
+	for _, tc := range []struct {
+		expr string
+		want []any
+	}{
+		{"$", []any{doc}},
+		{"$.owner.login", []any{"octo"}},
+		{"$['owner']['login']", []any{"octo"}},
+		{`$["owner"].login`, []any{"octo"}},
+		{"$.owner['a]b']", []any{1.0}},
+		{`$.owner['it\'s']`, []any{2.0}},
+		{"$.owner[ 'login' ]", []any{"octo"}},
+		{"$.topics[1]", []any{"tui"}},
+		{"$.topics[5]", nil},
+		{"$.topics[*]", []any{"cli", "tui"}},
+		{"$.items[*].id", []any{1.0, 2.0}}, // Missing members select nothing
+		{"$.labels.*", []any{1.0, 2.0}},    // Members in key order
+		{"$.owner[0]", nil},
+		{"$.topics.login", nil},
+		{"$.nope.login", nil},
+	} {
+		p, err := compileJSONPath(tc.expr)
+		if err != nil {
+			t.Errorf("%s: %v", tc.expr, err)
+			continue
+		}
+		if got := p.eval(doc); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s = %v, want %v", tc.expr, got, tc.want)
+		}
+	}
+}
+
+func TestCompileJSONPathRejects(t *testing.T) {
+	for _, expr := range []string{
+		"owner.login",     // No root
+		"$.owner[",        // Unclosed bracket
+		"$.owner['login'", // Unclosed bracket after a name
+		"$.owner['login",  // Unclosed quote
+		"$.topics[-1]",    // Negative index
+		"$.topics[x]",     // Neither index, * nor name
+		"$..login",        // Empty member name
+		"$owner",          // No . or [
+	} {
+		if _, err := compileJSONPath(expr); err == nil {
+			t.Errorf("%s: compiled, want an error", expr)
+		}
+	}
+}
+
+func TestJSONPathMultiple(t *testing.T) {
+	for expr, want := range map[string]bool{"$.a.b": false, "$.a[0]": false, "$.a[*]": true, "$.a.*.b": true} {
+		p, err := compileJSONPath(expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := p.multiple(); got != want {
+			t.Errorf("%s: multiple = %v, want %v", expr, got, want)
+		}
+	}
+}
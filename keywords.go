@@ -0,0 +1,41 @@
+package main
+
+import "strings"
+
+// --- Excluded Keywords ---
+//
+// `-exclude-keyword awesome -exclude-keyword tutorial` drops results whose
+// name or description contains one of the words, however each provider's
+// search treated them. Matching is on whole words, ignoring case and
+// punctuation, so "awesome" drops awesome-go but not awesomeness.
+
+// keywordList is the -exclude-keyword flag. It can be repeated, and each
+// value may hold several comma-separated keywords.
+type keywordList []string
+
+// String implements flag.Value.
+func (l *keywordList) String() string {
+	return strings.Join(*l, ",")
+}
+
+// Set implements flag.Value.
+func (l *keywordList) Set(value string) error {
+	*l = append(*l, splitList(value)...)
+	return nil
+}
+
+// matchesKeyword reports whether the item's name or description contains
+// one of the keywords.
+func matchesKeyword(s RepositorySummary, keywords []string) bool {
+	name, description := normalizeWords(s.Name), normalizeWords(s.Description)
+	for _, keyword := range keywords {
+		word := normalizeWords(keyword)
+		if word == "" {
+			continue
+		}
+		if countWords(name, word) > 0 || countWords(description, word) > 0 {
+			return true
+		}
+	}
+	return false
+}
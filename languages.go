@@ -0,0 +1,100 @@
+package main
+
+import "strings"
+
+// --- Language Normalization ---
+
+// languageAliases maps provider language labels (lowercased) to one
+// canonical name, so filters and statistics see "Go" whether a provider
+// said "Go", "golang" or "GO". Config.LanguageAliases extends and overrides
+// it.
+var languageAliases = map[string]string{
+	"go":               "Go",
+	"golang":           "Go",
+	"javascript":       "JavaScript",
+	"js":               "JavaScript",
+	"node.js":          "JavaScript",
+	"nodejs":           "JavaScript",
+	"typescript":       "TypeScript",
+	"ts":               "TypeScript",
+	"python":           "Python",
+	"python3":          "Python",
+	"py":               "Python",
+	"c++":              "C++",
+	"cpp":              "C++",
+	"c#":               "C#",
+	"csharp":           "C#",
+	"objective-c":      "Objective-C",
+	"objc":             "Objective-C",
+	"shell":            "Shell",
+	"bash":             "Shell",
+	"sh":               "Shell",
+	"vue":              "Vue",
+	"vue.js":           "Vue",
+	"vuejs":            "Vue",
+	"jupyter notebook": "Jupyter Notebook",
+	"jupyter":          "Jupyter Notebook",
+	"ipynb":            "Jupyter Notebook",
+	"ipython notebook": "Jupyter Notebook",
+	"rust":             "Rust",
+	"java":             "Java",
+	"kotlin":           "Kotlin",
+	"ruby":             "Ruby",
+	"php":              "PHP",
+	"html":             "HTML",
+	"css":              "CSS",
+	"dockerfile":       "Dockerfile",
+	"makefile":         "Makefile",
+	"hcl":              "HCL",
+	"terraform":        "HCL",
+	// Gitee and GitCode sometimes report Chinese labels.
+	"其他":   "Other",
+	"其它":   "Other",
+	"未知":   "Unknown",
+	"none": "Unknown",
+	"":     "Unknown",
+}
+
+// languageNormalizer canonicalizes language names.
+type languageNormalizer struct {
+	aliases map[string]string
+}
+
+// newLanguageNormalizer combines the built-in aliases with user overrides
+// (alias -> canonical name, case-insensitive on the alias).
+func newLanguageNormalizer(overrides map[string]string) languageNormalizer {
+	aliases := make(map[string]string, len(languageAliases)+len(overrides))
+	for k, v := range languageAliases {
+		aliases[k] = v
+	}
+	for k, v := range overrides {
+		aliases[strings.ToLower(strings.TrimSpace(k))] = v
+	}
+	return languageNormalizer{aliases: aliases}
+}
+
+// normalize returns the canonical name for a language label. Unknown labels
+// are returned trimmed but otherwise unchanged.
+func (n languageNormalizer) normalize(lang string) string {
+	lang = strings.TrimSpace(lang)
+	if canonical, ok := n.aliases[strings.ToLower(lang)]; ok {
+		return intern(canonical)
+	}
+	return lang
+}
+
+// apply normalizes the Language and Languages of every item, merging the
+// shares of labels that map to the same language.
+func (n languageNormalizer) apply(items []RepositorySummary) {
+	for i := range items {
+		items[i].Language = n.normalize(items[i].Language)
+		if len(items[i].Languages) == 0 {
+			continue
+		}
+		merged := make(map[string]float64, len(items[i].Languages))
+		for lang, share := range items[i].Languages {
+			merged[n.normalize(lang)] += share
+		}
+		items[i].Languages = merged
+	}
+}
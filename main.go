@@ -1,118 +1,573 @@
 package main
 
 import (
+	"bytes"
+	"cmp"
 	"context"
-	"encoding/json"
+	"crypto/ed25519"
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"slices"
 	"strings"
 	"time"
-)
 
-// PrintSummary prints repository summaries in a readable format
-func PrintSummary(summaries []RepositorySummary, source string) {
-	if len(summaries) == 0 {
-		fmt.Println("No repositories found.")
-		return
-	}
-
-	fmt.Printf("Found %d repositories from %s:\n\n", len(summaries), source)
-	for i, summary := range summaries {
-		fmt.Printf("%d. %s\n", i+1, summary.FullName)
-		fmt.Printf("   URL: %s\n", summary.URL)
-		fmt.Printf("   Description: %s\n", summary.Description)
-		fmt.Printf("   Language: %s | Stars: %d | Forks: %d\n",
-			summary.Language, summary.Stars, summary.Forks)
-		fmt.Printf("   Created: %s | Updated: %s\n", summary.CreatedAt, summary.UpdatedAt)
-		if len(summary.Topics) > 0 {
-			fmt.Printf("   Topics: %s\n", strings.Join(summary.Topics, ", "))
-		}
-		fmt.Println(strings.Repeat("-", 50))
-	}
-}
+	"github.com/suntong/rexplorer/rexplorertest"
+)
 
 // searcherTemplate is the interface our main function will program against.
 // This allows us to use any concrete implementation from the other files.
 type searcherTemplate interface {
 	Search(ctx context.Context, query string, maxPages int) (*SearchResult, error)
+	Plan(query string, maxPages int) (*SearchPlan, error)
 }
 
 func main() {
+	// Mask tokens in every log line, whatever its origin.
+	log.SetOutput(redactingWriter{w: os.Stderr})
+
+	// --- Subcommands ---
+	if len(os.Args) > 1 {
+		if cmd, ok := subcommands[os.Args[1]]; ok {
+			if err := cmd(os.Args[2:]); err != nil {
+				log.Fatalf("%s failed: %v", os.Args[1], err)
+			}
+			return
+		}
+	}
+
 	// --- Command Line Flag Parsing ---
-	service := flag.String("service", "github", "The search service to use (github, gitlab, bitbucket, gitcode, gitee)")
+	service := flag.String("service", "github", "The search service(s) to use: "+strings.Join(serviceNames(), ", ")+", a comma-separated list, all, a named instance or @group (a provider group) from the config file, or fixture:<dir> to replay recorded fixtures")
 	pages := flag.Int("pages", 5, "Maximum number of pages to fetch")
 	timeout := flag.Duration("timeout", 2*time.Minute, "Search timeout (e.g., 30s, 1m, 2m30s)")
+	recordDir := flag.String("record-fixtures", "", "Save every HTTP exchange as a replayable fixture in this directory")
+	format := flag.String("format", "text", "Console output format (text or table, markdown, html, or fragment for an HTML fragment to embed)")
+	fields := flag.String("fields", "", "Show one row per repository with just these columns in the text format: a comma-separated list of "+strings.Join(displayFieldNames(), ", "))
+	cleanDescriptions := flag.String("clean-descriptions", "", "Strip markup from descriptions in the console and CSV outputs (JSON keeps the originals): all, none, or a comma-separated list of "+strings.Join(cleaningKinds, ", ")+" (default from config, else none)")
+	sortBy := flag.String("sort", "", "Order the results by "+strings.Join(sortKeyNames(), ", ")+" (default: as each provider returned them)")
+	groupBy := flag.String("group-by", "", "Group console output by language, owner, license, provider, tag or matrix")
+	configFile := flag.String("config", "", "Configuration file (default: ~/.config/rexplorer/config.json)")
+	noBlocklist := flag.Bool("no-blocklist", false, "Don't remove repositories matching the blocklist (see 'rexplorer block')")
+	inFields := flag.String("in", "", "GitHub only: match the query in these fields: a comma-separated list of "+strings.Join(gitHubInFields, ", "))
+	user := flag.String("user", "", "GitHub only: search repositories owned by these users (comma-separated)")
+	org := flag.String("org", "", "GitHub only: search repositories owned by these organizations (comma-separated)")
+	is := flag.String("is", "", "GitHub only: keep repositories that are all of: "+strings.Join(gitHubIsValues, ", "))
+	pushedAfter := flag.String("pushed-after", "", "GitHub only: keep repositories pushed to after a date (2006-01-02) or within an age (e.g. 90d)")
+	gitlabURL := flag.String("gitlab-url", "", "GitLab only: search this self-hosted instance (e.g. https://gitlab.example.com) instead of gitlab.com")
+	gitlabGroup := flag.String("gitlab-group", "", "GitLab only: search within this group (path or ID) and its subgroups")
+	gitlabScope := flag.String("gitlab-scope", "", "GitLab only: what to match the query against: projects, or blobs to find the projects containing matching files")
+	gitlabSearchNamespaces := flag.Bool("gitlab-search-namespaces", false, "GitLab only: also match the query against group and user paths")
+	giteeOrg := flag.String("gitee-org", "", "Gitee only: list this organization's repositories (matched against the query) instead of searching all of Gitee")
+	giteeEnterprise := flag.String("gitee-enterprise", "", "Gitee only: list this enterprise's repositories (matched against the query) instead of searching all of Gitee")
+	sliceByDate := flag.Bool("slice-by-date", false, "GitHub only: split queries with more results than -pages (or the 1000-result cap) allow into created: date ranges and merge them")
+	topPerProvider := flag.Int("top-per-provider", 0, "Show at most N results per provider on the console (the JSON output keeps everything)")
+	interleave := flag.Bool("interleave", false, "Alternate between providers on the console instead of listing them one after another")
+	uniqueNames := flag.Bool("unique-names", false, "Keep only the highest-ranked repository for each repository name, by -sort (default: stars), after filtering")
+	compactNumbers := flag.Bool("compact-numbers", false, "Abbreviate large counts (12.3k) instead of grouping digits")
+	color := flag.String("color", "auto", "Highlight the query's words in console output: auto (when stdout is a terminal and NO_COLOR is unset), always or never")
+	noPager := flag.Bool("no-pager", false, "Don't pipe long console output through $PAGER")
+	activeWithin := flag.String("active-within", "", "Keep only repositories active within this age (e.g. 90d, 12m, 2y), filtered by the provider where it can")
+	sinceLastRun := flag.Bool("since-last-run", false, "Only fetch and keep repositories updated since the previous run of the same search")
+	cacheSize := flag.Int("cache-size", 256, "Number of API responses to keep in the in-memory cache (0 disables it)")
+	cacheTTL := flag.Duration("cache-ttl", 10*time.Minute, "How long a cached API response stays valid")
+	spillAfter := flag.Int("spill-after", 0, "Keep at most N results per search in memory while paging and removing duplicates, spooling the rest to temporary files; the unique results are still loaded into memory (0 keeps everything in memory)")
+	enrich := flag.String("enrich", "", "Fetch extra data per repository: a comma-separated list of "+strings.Join(enricherNames(), ", "))
+	enrichWorkers := flag.Int("enrich-workers", 8, "Number of repositories enriched at once, across all providers; a provider gets fewer as its rate limit runs low")
+	maxIdleConns := flag.Int("max-idle-conns-per-host", 0, "Idle connections kept per host (default from config, else 16)")
+	idleConnTimeout := flag.Duration("idle-conn-timeout", 0, "How long idle connections are kept (default from config, else 90s)")
+	forceHTTP2 := flag.Bool("force-http2", true, "Attempt HTTP/2 connections")
+	topicsReport := flag.Int("topics-report", 0, "After the results, list the N most common topics (0 disables the report)")
+	forkGraph := flag.String("fork-graph", "", "Write the fork relationships among the results to this file (.dot, or .graphml); implies -enrich=parent")
+	resolveForks := flag.String("resolve-forks", "", "Follow forks to their upstream and annotate them (annotate) or show the upstream instead (replace)")
+	maxReleaseAge := flag.String("max-release-age", "", "Keep only repositories with a release within this age (e.g. 90d, 12m, 2y); implies -enrich=releases")
+	requireDocs := flag.Bool("require-docs", false, "Keep only repositories with a documentation site; implies -enrich=docs")
+	requireCI := flag.Bool("require-ci", false, "Keep only repositories with a CI configuration; implies -enrich=ci")
+	encrypt := flag.String("encrypt", "", "Encrypt output files for a recipient: age:<recipient> or gpg:<recipient> (needs age or gpg installed)")
+	signKey := flag.String("sign-key", "", "Sign each artifact's provenance with this Ed25519 private key (see `provenance keygen`)")
+	confirmAbove := flag.Int("confirm-above", 0, "Ask before runs estimated to send more than N requests (default from config, else 100; negative disables)")
+	assumeYes := flag.Bool("yes", false, "Don't ask for confirmation before large runs")
+	outDir := flag.String("out-dir", "", "Write this run's files into a new timestamped folder under this directory, and point its \"latest\" symlink there")
+	var alsoWrite sinkList
+	flag.Var(&alsoWrite, "also-write", "Also save the results as format:path (formats: "+strings.Join(sinkFormats, ", ")+"); comma-separated or repeated")
+	manifestPath := flag.String("manifest", "", "Write a machine-readable summary of the run (flags, per-provider pages, requests, durations, warnings, status) to this file (default: "+manifestName+" in the -out-dir run directory, otherwise none)")
+	strict := flag.Bool("strict", false, "Fail with a non-zero exit status instead of returning partial results when a page can't be fetched or read")
+	var excludeKeywords keywordList
+	flag.Var(&excludeKeywords, "exclude-keyword", "Drop results whose name or description contains this word (e.g. awesome, tutorial, deprecated); comma-separated or repeated")
+	var where whereList
+	flag.Var(&where, "where", "Keep only results for which this holds, e.g. \"forks>=10\" or \"topics=cli\" (any JSON field; operators: "+strings.Join(whereOps, " ")+"); repeatable")
+	var matrix matrixVars
+	flag.Var(&matrix, "matrix", "Run the query once per combination of values, e.g. \"lang=go,rust topic=cli,tui\" with {lang} and {topic} in the query; repeatable")
+	providerConcurrency := flag.Int("provider-concurrency", 1, "How many providers to crawl at the same time when searching several; 1 searches them one after another. Services on the same API host always take turns")
+	var extraParams providerParams
+	flag.Var(&extraParams, "provider-param", "Add a query parameter to one service's search requests, as service:key=value (e.g. gitlab:order_by=stars); repeatable")
+	saveRaw := flag.String("save-raw", "", "Also keep every raw search page, gzipped, in this directory (per service and query), so the reparse command can parse them again later")
+	includeRawFields := flag.String("include-raw-fields", "", "Keep these fields of the providers' responses, missing from the normalized results, under \"extra\" (comma-separated, e.g. id,owner.login,default_branch)")
+	verbose := flag.Bool("verbose", false, "Log statistics for every page fetched: items, new and duplicate results, running total, time taken and rate limit left")
+	dryRun := flag.Bool("dry-run", false, "Print the requests a search would make (tokens redacted) without sending them")
 	flag.Parse()
 
 	args := flag.Args()
+	// `-matrix lang=go,rust topic=cli,tui query` leaves the later variables
+	// in front of the query.
+	for len(matrix) > 0 && len(args) > 1 && isMatrixVar(args[0]) {
+		if err := matrix.Set(args[0]); err != nil {
+			log.Fatalf("Error: -matrix: %v", err)
+		}
+		args = args[1:]
+	}
 	if len(args) < 1 {
-		log.Fatal("Usage: go run . -service=<github|gitlab|bitbucket|gitcode|gitee> [options] <search_query>")
+		log.Fatalf("Usage: go run . -service=<%s> [options] <search_query>\n"+
+			"   or: go run . <%s> [args]", strings.Join(serviceNames(), "|"), strings.Join(subcommandNames(), "|"))
 	}
 	query := args[0]
+	queries := []string{query}
+	combos := matrix.combinations()
+	if len(matrix) > 0 {
+		if err := matrix.validate(query); err != nil {
+			log.Fatalf("Error: -matrix: %v", err)
+		}
+		queries = matrixQueries(query, combos)
+	}
+
+	if err := validateFormat(*format); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if err := validateGroupBy(*groupBy); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if _, ok := sortKeys[*sortBy]; *sortBy != "" && !ok {
+		log.Fatalf("Error: unknown sort key %q; must be one of %s", *sortBy, strings.Join(sortKeyNames(), ", "))
+	}
+	columns, err := parseFields(*fields)
+	if err != nil {
+		log.Fatalf("Error: -fields: %v", err)
+	}
+	colored, err := useColor(*color)
+	if err != nil {
+		log.Fatalf("Error: -color: %v", err)
+	}
+	qualifiers, err := gitHubQualifierFlags{In: *inFields, User: *user, Org: *org, Is: *is, PushedAfter: *pushedAfter}.terms(time.Now())
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if *giteeOrg != "" && *giteeEnterprise != "" {
+		log.Fatal("Error: -gitee-org and -gitee-enterprise cannot be combined")
+	}
+	gitlab := gitLabOptions{URL: *gitlabURL, Group: *gitlabGroup, Scope: *gitlabScope, SearchNamespaces: *gitlabSearchNamespaces}
+	if err := gitlab.validate(); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	enrichList := *enrich
+	if *forkGraph != "" && !containsFold(splitList(enrichList), "parent") {
+		enrichList += ",parent"
+	}
+	if *requireCI && !containsFold(splitList(enrichList), "ci") {
+		enrichList += ",ci"
+	}
+	if *requireDocs && !containsFold(splitList(enrichList), "docs") {
+		enrichList += ",docs"
+	}
+	var releaseAge time.Duration
+	if *maxReleaseAge != "" {
+		var err error
+		if releaseAge, err = parseAge(*maxReleaseAge); err != nil {
+			log.Fatalf("Error: -max-release-age: %v", err)
+		}
+		if !containsFold(splitList(enrichList), "releases") {
+			enrichList += ",releases"
+		}
+	}
+	selectedEnrichers, err := resolveEnrichers(enrichList)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	upstreams := newUpstreamResolver()
+	if *resolveForks != "" {
+		if !containsFold(resolveForksModes, *resolveForks) {
+			log.Fatalf("Error: unknown -resolve-forks mode %q; must be one of %s", *resolveForks, strings.Join(resolveForksModes, ", "))
+		}
+		selectedEnrichers = append(selectedEnrichers, upstreams.enricher())
+	}
+
+	enc, err := parseEncrypt(*encrypt)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	var signingKey ed25519.PrivateKey
+	if *signKey != "" {
+		if signingKey, err = loadSigningKey(*signKey); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+
+	cfg, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if err := useStore(cfg.Store); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	rawFields, err := rawFieldMappings(splitList(*includeRawFields))
+	if err != nil {
+		log.Fatalf("Error: -include-raw-fields: %v", err)
+	}
+	if *providerConcurrency < 1 {
+		log.Fatal("Error: -provider-concurrency must be at least 1")
+	}
+	if err := extraParams.validate(); err != nil {
+		log.Fatalf("Error: -provider-param: %v", err)
+	}
+	cleaning := cfg.CleanDescriptions
+	if *cleanDescriptions != "" {
+		cleaning = splitList(*cleanDescriptions)
+	}
+	cleaner, err := parseCleaning(cleaning)
+	if err != nil {
+		log.Fatalf("Error: -clean-descriptions: %v", err)
+	}
 
 	// --- Service Initialization ---
-	var searcher searcherTemplate
-	var token string
-	var client = &http.Client{Timeout: 30 * time.Second}
-
-	switch strings.ToLower(*service) {
-	case "github":
-		token = os.Getenv("GITHUB_TOKEN") // Optional, but higher rate limits
-		if token == "" {
-			log.Println("Warning: GITHUB_TOKEN not set. Using unauthenticated requests (low rate limit).")
-		}
-		searcher = NewGitHubSearcher(token, client)
-	case "gitlab":
-		token = os.Getenv("GITLAB_TOKEN")
-		if token == "" {
-			log.Println("Warning: GITLAB_TOKEN not set. Using unauthenticated requests.")
-		}
-		searcher = NewGitLabSearcher(token, client)
-	case "bitbucket":
-		token = os.Getenv("BITBUCKET_TOKEN")
-		if token == "" {
-			log.Fatal("Error: BITBUCKET_TOKEN environment variable not set. Expected format is 'username:app_password'.")
-		}
-		// Useless!! The authenticated call will only search repos where you have an explicit role (member, contributor, admin, or owner)!
-		searcher = NewBitbucketSearcher(token, client)
-	case "gitcode":
-		token = os.Getenv("GITCODE_TOKEN")
-		if token == "" {
-			log.Fatal("Error: GITCODE_TOKEN environment variable not set.")
-		}
-		searcher = NewGitCodeSearcher(token, client)
-	case "gitee":
-		token = os.Getenv("GITEE_TOKEN")
-		if token == "" {
-			log.Fatal("Error: GITEE_TOKEN environment variable not set.")
-		}
-		searcher = NewGiteeSearcher(token, client)
-	default:
-		log.Fatalf("Unknown service: %s. Must be one of github, gitlab, bitbucket, gitcode, or gitee.", *service)
+	// Flags override the config file, which overrides the defaults.
+	tc := TransportConfig{MaxIdleConnsPerHost: *maxIdleConns}
+	if *idleConnTimeout > 0 {
+		tc.IdleConnTimeout = idleConnTimeout.String()
+	}
+	if flagSet("force-http2") {
+		tc.ForceHTTP2 = forceHTTP2
+	}
+	var transport http.RoundTripper = newTransport(tc.merge(cfg.Transport).merge(defaultTransportConfig))
+	if *recordDir != "" {
+		transport = &rexplorertest.RecordingTransport{Dir: *recordDir, Next: transport, RedactURL: redactURL, RedactHeaders: redactHeaders}
+	}
+	var client = &http.Client{Timeout: 30 * time.Second, Transport: transport}
+
+	// One cache serves every provider, so repeated lookups are only sent once.
+	cache := NewResponseCache(*cacheSize, *cacheTTL)
+
+	// A dry run never talks to the provider, so a missing token is not fatal.
+	var requests requestCounter
+	var searchers []searcherTemplate
+	usesLicense := *groupBy == "license" ||
+		slices.ContainsFunc(columns, func(f displayField) bool { return f.Name == "license" }) ||
+		slices.ContainsFunc(where, func(e whereExpr) bool { return strings.EqualFold(e.Field, "license") })
+	services := resolveServices(*service)
+	for _, name := range services {
+		searcher, err := newSearcher(name, client, *dryRun)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		if _, ok := lookupInstance(name); ok {
+			// An instance keeps its own URL; the other GitLab flags still apply.
+			opts := gitlab
+			opts.URL = ""
+			opts.apply(searcher)
+		} else {
+			gitlab.apply(searcher)
+		}
+		if gh, ok := searcher.(*GitHubSearcher); ok {
+			gh.Qualifiers = qualifiers
+		}
+		if gh, ok := searcher.(*GitHubGraphQLSearcher); ok {
+			gh.Qualifiers = qualifiers
+		}
+		if gt, ok := searcher.(*GiteeSearcher); ok {
+			gt.Org, gt.Enterprise = *giteeOrg, *giteeEnterprise
+		}
+		if cb, ok := searcher.(*CodebergSearcher); ok {
+			cb.UsesLicense = usesLicense
+		}
+		if b, ok := baseOf(searcher); ok {
+			cfg.Providers[strings.ToLower(name)].apply(b)
+			if *saveRaw != "" {
+				b.RawArchive = &rawArchive{Dir: *saveRaw, Service: archivedService(name, searcher)}
+			}
+			b.FieldMappings = slices.Concat(cfg.mappings[strings.ToLower(name)], rawFields)
+			if params := extraParams[strings.ToLower(name)]; params != nil {
+				if _, ok := b.implementation.(cursorPaginator); ok {
+					log.Fatalf("Error: -provider-param: %s is a GraphQL service and takes no query parameters", name)
+				}
+				b.ExtraParams = params
+			}
+			b.Use(cache.Middleware(), requests.Middleware(b.Source))
+			b.SpillAfter = *spillAfter
+			b.Strict = *strict
+		}
+		searchers = append(searchers, searcher)
+	}
+	if len(searchers) == 0 {
+		log.Fatal("Error: no service given")
+	}
+	for name := range extraParams {
+		if !containsFold(services, name) {
+			log.Printf("Warning: -provider-param for %s, which is not being searched, is ignored", name)
+		}
+	}
+	for _, q := range queries {
+		if err := preflight(searchers, q, *pages, *timeout); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+
+	// Activity filters: providers that can filter by activity do so
+	// server-side; everything is filtered client-side after the search.
+	// With both -active-within and -since-last-run, the later cutoff wins.
+	var since time.Time
+	if *activeWithin != "" {
+		age, err := parseAge(*activeWithin)
+		if err != nil {
+			log.Fatalf("Error: -active-within: %v", err)
+		}
+		since = time.Now().Add(-age)
+		log.Printf("Fetching repositories active since %s.", since.Format(time.RFC3339))
+	}
+	if *sinceLastRun {
+		prev, ok, err := lastRun(*service, query)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		if !ok {
+			log.Printf("No previous run of this search; fetching everything.")
+		} else if prev.RanAt.After(since) {
+			since = prev.RanAt
+			log.Printf("Fetching repositories updated since the last run at %s.", since.Format(time.RFC3339))
+		}
+	}
+	if !since.IsZero() {
+		for _, searcher := range searchers {
+			if b, ok := baseOf(searcher); ok {
+				b.UpdatedSince = since
+			}
+		}
+	}
+
+	if *dryRun {
+		for _, q := range queries {
+			for _, searcher := range searchers {
+				plan, err := searcher.Plan(q, *pages)
+				if err != nil {
+					log.Fatalf("Dry run failed: %v", err)
+				}
+				PrintPlan(os.Stdout, plan)
+				fmt.Println()
+			}
+		}
+		return
 	}
 
 	// --- Execution ---
 	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
 	defer cancel()
 
+	started := time.Now()
+	var runDir string
+	if *outDir != "" {
+		if runDir, err = newRunDir(*outDir, started); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		log.Printf("Writing this run's files to %s", runDir)
+	}
+	if runDir != "" && !flagSet("manifest") {
+		*manifestPath = manifestName
+	}
+	*manifestPath = inRunDir(runDir, *manifestPath)
+	manifest := newRunManifest(query, started)
+	for _, searcher := range searchers {
+		if b, ok := baseOf(searcher); ok {
+			manifest.track(b, query)
+			if *verbose {
+				b.OnEvent = pageStats(b.OnEvent)
+			}
+		}
+	}
+	// fail ends a run that has started, recording the failure in the manifest.
+	fail := func(format string, args ...any) {
+		err := fmt.Errorf(format, args...)
+		if *manifestPath != "" {
+			if werr := manifest.write(*manifestPath, requests.snapshot(), err); werr != nil {
+				log.Printf("Warning: %v", werr)
+			}
+		}
+		log.Fatal(err)
+	}
+
+	threshold := cmp.Or(*confirmAbove, cfg.ConfirmAbove, defaultConfirmAbove)
+	if err := checkRunSize(ctx, searchers, queries, *pages, len(selectedEnrichers), threshold, *assumeYes); err != nil {
+		fail("Error: %w", err)
+	}
+
 	log.Printf("Starting search on %s for query %q (max %d pages)...", *service, query, *pages)
 
-	result, err := searcher.Search(ctx, query, *pages)
+	var result *SearchResult
+	if len(matrix) > 0 {
+		log.Printf("Running %d matrix combinations.", len(combos))
+		result, err = searchMatrix(ctx, searchers, query, combos, *pages, *sliceByDate, *providerConcurrency)
+	} else {
+		result, err = searchAll(ctx, searchers, query, *pages, *sliceByDate, *providerConcurrency)
+	}
 	if err != nil {
-		log.Fatalf("Search failed: %v", err)
+		fail("Search failed: %w", err)
+	}
+	if !since.IsZero() {
+		before := len(result.Items)
+		result.Items = updatedSince(result.Items, since)
+		if n := before - len(result.Items); n > 0 {
+			log.Printf("Dropped %d repositories not active since %s.", n, since.Format(time.RFC3339))
+		}
+	}
+	if err := recordRun(runRecord{Service: *service, Query: query, RanAt: started, Retrieved: len(result.Items)}); err != nil {
+		log.Printf("Warning: failed to record run history: %v", err)
+	}
+
+	// --- Post-processing ---
+	languages := newLanguageNormalizer(cfg.LanguageAliases)
+	languages.apply(result.Items)
+	newTopicNormalizer(cfg.TopicAliases).apply(result.Items)
+	markSuccessors(result.Items)
+	markDocsSites(result.Items)
+	if !*noBlocklist {
+		blocked, err := loadBlocklist()
+		if err != nil {
+			fail("Error: %w", err)
+		}
+		before := len(result.Items)
+		result.Items = filterSummaries(result.Items, func(s RepositorySummary) bool { return !blocked.Blocks(s) })
+		if n := before - len(result.Items); n > 0 {
+			log.Printf("Removed %d blocklisted repositories.", n)
+		}
+	}
+
+	if len(excludeKeywords) > 0 {
+		before := len(result.Items)
+		result.Items = filterSummaries(result.Items, func(s RepositorySummary) bool { return !matchesKeyword(s, excludeKeywords) })
+		log.Printf("Dropped %d repositories matching excluded keywords.", before-len(result.Items))
+	}
+
+	applyTagRules(cfg.TagRules, result.Items, time.Now())
+
+	enrichAll(ctx, searchers, result.Items, selectedEnrichers, *enrichWorkers)
+	if len(selectedEnrichers) > 0 {
+		// Enrichers may add languages straight from the provider.
+		languages.apply(result.Items)
+	}
+	if *requireCI {
+		before := len(result.Items)
+		result.Items = filterSummaries(result.Items, func(s RepositorySummary) bool { return len(s.CISystems) > 0 })
+		log.Printf("Dropped %d repositories without a CI configuration.", before-len(result.Items))
+	}
+	if *requireDocs {
+		before := len(result.Items)
+		result.Items = filterSummaries(result.Items, func(s RepositorySummary) bool { return s.HasDocsSite })
+		log.Printf("Dropped %d repositories without a documentation site.", before-len(result.Items))
+	}
+	if *maxReleaseAge != "" {
+		before, now := len(result.Items), time.Now()
+		result.Items = filterSummaries(result.Items, func(s RepositorySummary) bool { return releasedWithin(s, releaseAge, now) })
+		log.Printf("Dropped %d repositories without a release in the last %s.", before-len(result.Items), *maxReleaseAge)
+	}
+	if strings.EqualFold(*resolveForks, "replace") {
+		var n int
+		result.Items, n = upstreams.replace(result.Items)
+		log.Printf("Replaced %d forks with their upstream repositories.", n)
+	}
+
+	if n := markNameCollisions(result.Items); n > 0 && !*uniqueNames {
+		log.Printf("Warning: %d repository names are shared by more than one result (see -unique-names).", n)
+	}
+	scoreRelevance(result.Items, queries...)
+	if len(where) > 0 {
+		before := len(result.Items)
+		result.Items = filterSummaries(result.Items, where.matchAll)
+		log.Printf("Dropped %d repositories not matching -where %s.", before-len(result.Items), where.String())
+	}
+	if *sortBy != "" {
+		if err := sortSummaries(result.Items, *sortBy, false); err != nil {
+			fail("Error: %w", err)
+		}
+	}
+	if *uniqueNames {
+		// Rank by -sort, or by stars, as the providers do, without it.
+		rank := sortKeys["stars"]
+		if *sortBy != "" {
+			rank = sortKeys[*sortBy]
+		}
+		before := len(result.Items)
+		result.Items = uniqueByName(result.Items, rank)
+		log.Printf("Dropped %d repositories whose names were already taken by a higher-ranked result.", before-len(result.Items))
 	}
 
-	// --- Results ---
 	// --- Results ---
 	fmt.Fprintln(os.Stderr, "\n=== KEY REPOSITORY INFORMATION ===")
-	PrintSummary(result.Items, result.Source)
+	view := result.Items
+	if *topPerProvider > 0 || *interleave {
+		view = balanceByProvider(result.Items, *topPerProvider, *interleave)
+	}
+	console := renderOptions{GroupBy: *groupBy, CompactNumbers: *compactNumbers, Fields: columns, Clean: cleaner}
+	if colored && *format != "html" && *format != "fragment" {
+		console.Highlight = newQueryHighlighter(queries...)
+		for _, re := range where.highlights() {
+			console.Highlight.add(re)
+		}
+	}
+	var out bytes.Buffer
+	if err := render(&out, *format, view, result.Source, console); err != nil {
+		fail("Error: %w", err)
+	}
+	if *topicsReport > 0 && len(result.Items) > 0 {
+		fmt.Fprintf(&out, "\n=== TOP %d TOPICS ===\n", *topicsReport)
+		if err := writeTopicsReport(&out, result.Items, *topicsReport); err != nil {
+			fail("Error: %w", err)
+		}
+	}
+	if err := writePaged(out.Bytes(), *noPager); err != nil {
+		log.Printf("Warning: failed to write output: %v", err)
+	}
+
+	// Files are written in a deterministic order, so that runs can be
+	// compared and kept in git.
+	ordered := *result
+	ordered.Items = stableOrder(result.Items, *sortBy)
+	var artifacts []string
+	if *forkGraph != "" {
+		n, written, err := writeForkGraph(inRunDir(runDir, *forkGraph), ordered.Items, enc)
+		if err != nil {
+			log.Printf("Warning: %v", err)
+		} else {
+			log.Printf("Wrote %d fork relationships to %s", n, written)
+			artifacts = append(artifacts, written)
+		}
+	}
 
 	// Write JSON output
-	if err := writeJSONOutput(result); err != nil {
+	if filename, err := writeJSONOutput(runDir, &ordered, enc); err != nil {
 		log.Printf("Warning: failed to write JSON output: %v", err)
+	} else if filename != "" {
+		artifacts = append(artifacts, filename)
+	}
+	for _, sink := range alsoWrite {
+		sink.Path = inRunDir(runDir, sink.Path)
+		written, err := sink.write(&ordered, renderOptions{GroupBy: *groupBy, CompactNumbers: *compactNumbers, Clean: cleaner}, enc)
+		if err != nil {
+			log.Printf("Warning: failed to write %s output: %v", sink.Format, err)
+			continue
+		}
+		log.Printf("Wrote %s output to %s", sink.Format, written)
+		artifacts = append(artifacts, written)
+	}
+
+	version, revision := toolVersion()
+	prov := Provenance{
+		Tool: "rexplorer", Version: version, Revision: revision,
+		Query: query, Providers: resolveServices(*service),
+		StartedAt: started.UTC(), FinishedAt: time.Now().UTC(),
+		Requests: requests.snapshot(), Retrieved: len(result.Items),
+	}
+	for _, path := range artifacts {
+		if err := writeProvenance(path, prov, signingKey); err != nil {
+			log.Printf("Warning: %v", err)
+		}
 	}
 
 	fmt.Fprintf(os.Stderr, "\nSearch completed:\n")
@@ -124,29 +579,44 @@ func main() {
 		fmt.Fprintf(os.Stderr, "- Total repositories available: %d\n", result.TotalCount)
 	}
 	fmt.Fprintf(os.Stderr, "- Repositories retrieved: %d\n", len(result.Items))
+	printWarnings(os.Stderr, result.Warnings)
+
+	if *manifestPath != "" {
+		manifest.Retrieved = len(result.Items)
+		manifest.Outputs = artifacts
+		manifest.Warnings = result.Warnings
+		if err := manifest.write(*manifestPath, requests.snapshot(), nil); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+	if runDir != "" {
+		if err := updateLatest(*outDir, runDir); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+	applyRetention(cfg.Retention, *outDir)
 }
 
-// writeJSONOutput marshals the search result items to a JSON file.
-func writeJSONOutput(result *SearchResult) error {
+// writeJSONOutput marshals the search result items to a JSON file in dir
+// (the current directory if empty), encrypted if enc is set, and returns its
+// name ("" if there was nothing to write).
+func writeJSONOutput(dir string, result *SearchResult, enc *encrypter) (string, error) {
 	if len(result.Items) == 0 {
-		return nil // Don't write empty files
+		return "", nil // Don't write empty files
 	}
 
 	// Sanitize the source for the filename
 	safeSource := strings.ReplaceAll(result.Source, " ", "")
-	filename := fmt.Sprintf("Out-%s.json", safeSource)
+	filename := inRunDir(dir, fmt.Sprintf("Out-%s.json", safeSource))
 
-	// Marshal the items with pretty printing
-	jsonData, err := json.MarshalIndent(result.Items, "", "  ")
+	data, err := marshalSummaries(result.Items)
 	if err != nil {
-		return fmt.Errorf("failed to marshal results to JSON: %w", err)
+		return "", err
 	}
-
-	// Write the file
-	if err := os.WriteFile(filename, jsonData, 0644); err != nil {
-		return fmt.Errorf("failed to write JSON to file %s: %w", filename, err)
+	if filename, err = writeArtifact(filename, data, enc); err != nil {
+		return "", err
 	}
 
 	log.Printf("Successfully wrote %d results to %s", len(result.Items), filename)
-	return nil
+	return filename, nil
 }
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// --- Run Manifest ---
+//
+// run.json records how a run went, for orchestrators that need to check a
+// run before using its output or want operational stats across many runs.
+// It is written on failure too, once the search has started. It goes next
+// to the data, in the -out-dir run directory, unless -manifest names a file.
+
+// manifestName is the manifest's file name in a run directory.
+const manifestName = "run.json"
+
+// runManifest is the content of run.json.
+type runManifest struct {
+	Tool    string `json:"tool"`
+	Version string `json:"version"`
+	// Args are the command-line arguments; Flags are the flags that were set.
+	Args  []string          `json:"args"`
+	Flags map[string]string `json:"flags"`
+	Query string            `json:"query"`
+	// Status is "ok" or "failed"; ExitCode is the process exit status.
+	Status     string         `json:"status"`
+	ExitCode   int            `json:"exit_code"`
+	Error      string         `json:"error,omitempty"`
+	StartedAt  time.Time      `json:"started_at"`
+	FinishedAt time.Time      `json:"finished_at"`
+	Duration   float64        `json:"duration_seconds"`
+	Providers  []*providerRun `json:"providers"`
+	Retrieved  int            `json:"retrieved"`
+	Outputs    []string       `json:"outputs,omitempty"`
+	Warnings   []string       `json:"warnings,omitempty"`
+
+	mu sync.Mutex
+}
+
+// providerRun is what one provider did during the run.
+type providerRun struct {
+	Source string `json:"source"`
+	// SearchURL is the first page's URL (credentials redacted), which shows
+	// the query as the provider received it.
+	SearchURL  string  `json:"search_url,omitempty"`
+	Pages      int     `json:"pages"`
+	Items      int     `json:"items"`
+	TotalCount int     `json:"total_count"`
+	Requests   int     `json:"requests"` // Sent over the network; cache hits aren't counted
+	Retries    int     `json:"retries"`
+	RateLimits int     `json:"rate_limited"`
+	Duration   float64 `json:"duration_seconds"`
+}
+
+// newRunManifest starts a manifest for a run of query.
+func newRunManifest(query string, started time.Time) *runManifest {
+	version, _ := toolVersion()
+	m := &runManifest{
+		Tool: "rexplorer", Version: version,
+		Args: os.Args[1:], Flags: make(map[string]string),
+		Query: query, StartedAt: started.UTC(),
+	}
+	flag.Visit(func(f *flag.Flag) { m.Flags[f.Name] = f.Value.String() })
+	return m
+}
+
+// track records a searcher's events in the manifest.
+func (m *runManifest) track(b *BaseRepoSearcher, query string) {
+	run := m.provider(b.Source)
+	if u, err := b.implementation.buildSearchURL(query, 1, b.PerPage); err == nil && run.SearchURL == "" {
+		if u, err := b.withExtraParams(u); err == nil {
+			run.SearchURL = redactURL(u)
+		}
+	}
+	b.OnEvent = m.observe
+}
+
+// provider returns the entry for source, adding it if needed.
+func (m *runManifest) provider(source string) *providerRun {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, run := range m.Providers {
+		if run.Source == source {
+			return run
+		}
+	}
+	run := &providerRun{Source: source, TotalCount: -1}
+	m.Providers = append(m.Providers, run)
+	return run
+}
+
+// observe implements BaseRepoSearcher.OnEvent.
+func (m *runManifest) observe(e SearchEvent) {
+	run := m.provider(e.Source)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch e.Kind {
+	case EventPageCompleted:
+		run.Pages++
+		if e.Page == 1 && run.TotalCount < 0 {
+			run.TotalCount = e.TotalCount
+		}
+	case EventRetry:
+		run.Retries++
+	case EventRateLimited:
+		run.RateLimits++
+	case EventProviderFinished:
+		run.Items += e.Items
+		run.Duration += e.Elapsed.Seconds()
+	}
+}
+
+// write finishes the manifest and saves it to path. A non-nil runErr marks
+// the run as failed.
+func (m *runManifest) write(path string, requests map[string]int, runErr error) error {
+	m.mu.Lock()
+	m.FinishedAt = time.Now().UTC()
+	m.Duration = m.FinishedAt.Sub(m.StartedAt).Seconds()
+	m.Status = "ok"
+	if runErr != nil {
+		m.Status, m.ExitCode, m.Error = "failed", 1, redactText(runErr.Error())
+	}
+	for _, run := range m.Providers {
+		run.Requests = requests[run.Source]
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false) // Keep URLs readable
+	enc.SetIndent("", "  ")
+	err := enc.Encode(m)
+	m.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal run manifest: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write run manifest: %w", err)
+	}
+	return nil
+}
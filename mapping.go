@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// --- Field Mappings ---
+//
+// Self-hosted forges with custom plugins report fields the built-in
+// mappers don't know. The config file can map them, per service, into a
+// summary field or into Extra with JSONPath expressions evaluated against
+// each result's JSON object:
+//
+//	"field_mappings": {
+//	  "gitlab-internal": {"language": "$.custom_attributes.language", "extra.team": "$.namespace.owner"}
+//	}
+//
+// Mapped values replace what the provider's mapper set, if they are present.
+// -include-raw-fields id,owner.login is shorthand for mapping those fields
+// into Extra under the same names, for every service.
+
+// rawItemSource is implemented by providers whose results can be traced
+// back to the JSON objects they were parsed from.
+type rawItemSource interface {
+	// rawItemPaths returns the JSONPath of the result objects in a page,
+	// and of the URL that becomes RepositorySummary.URL within each.
+	rawItemPaths() (items, url string)
+}
+
+// fieldMapping fills one summary field, or one Extra key, from a path in
+// the raw result.
+type fieldMapping struct {
+	Target string
+	path   jsonPath
+	extra  string // Key in Extra, for extra.<key> targets
+	field  []int  // Index of the summary field otherwise
+	kind   reflect.Kind
+}
+
+// compileFieldMappings checks one service's mappings, target field to
+// JSONPath, and returns them ordered by target.
+func compileFieldMappings(m map[string]string) ([]fieldMapping, error) {
+	var mappings []fieldMapping
+	typ := reflect.TypeOf(RepositorySummary{})
+	for target, expr := range m {
+		path, err := compileJSONPath(expr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", target, err)
+		}
+		fm := fieldMapping{Target: target, path: path}
+		if key, ok := strings.CutPrefix(target, "extra."); ok {
+			if key == "" {
+				return nil, fmt.Errorf("%s: missing key after extra.", target)
+			}
+			fm.extra = key
+		} else {
+			f, ok := jsonField(typ, target)
+			if !ok {
+				return nil, fmt.Errorf("unknown field %q; use extra.<name> for fields the summary doesn't have", target)
+			}
+			fm.field, fm.kind = f.Index, f.Type.Kind()
+			if fm.kind == reflect.Slice && f.Type.Elem().Kind() != reflect.String {
+				fm.kind = reflect.Invalid
+			}
+			if fm.kind != reflect.String && fm.kind != reflect.Bool && fm.kind != reflect.Slice && !isNumericKind(fm.kind) {
+				return nil, fmt.Errorf("%s can't be mapped; map into extra.<name> instead", target)
+			}
+		}
+		mappings = append(mappings, fm)
+	}
+	slices.SortFunc(mappings, func(a, b fieldMapping) int { return strings.Compare(a.Target, b.Target) })
+	return mappings, nil
+}
+
+// rawFieldMappings copies raw result fields into Extra under their own
+// names, for -include-raw-fields. Nested fields are reached with dots
+// (owner.login).
+func rawFieldMappings(names []string) ([]fieldMapping, error) {
+	m := make(map[string]string, len(names))
+	for _, name := range names {
+		m["extra."+name] = "$." + name
+	}
+	return compileFieldMappings(m)
+}
+
+// apply sets the target from the raw result object. A path that selects
+// nothing, or a value of the wrong type, leaves the target unchanged.
+func (m fieldMapping) apply(item *RepositorySummary, raw any) {
+	values := m.path.eval(raw)
+	if len(values) == 0 {
+		return
+	}
+	var value any = values[0]
+	if m.path.multiple() {
+		value = values
+	}
+	if m.extra != "" {
+		if item.Extra == nil {
+			item.Extra = make(map[string]any)
+		}
+		item.Extra[m.extra] = value
+		return
+	}
+
+	f := reflect.ValueOf(item).Elem().FieldByIndex(m.field)
+	switch {
+	case m.kind == reflect.Slice:
+		var list []string
+		for _, v := range values {
+			if s, ok := scalarText(v); ok {
+				list = append(list, s)
+			} else if vs, ok := v.([]any); ok {
+				for _, v := range vs {
+					if s, ok := scalarText(v); ok {
+						list = append(list, s)
+					}
+				}
+			}
+		}
+		f.Set(reflect.ValueOf(list))
+	case m.kind == reflect.String:
+		if s, ok := scalarText(value); ok {
+			f.SetString(s)
+		}
+	case m.kind == reflect.Bool:
+		if s, ok := scalarText(value); ok {
+			if b, err := strconv.ParseBool(s); err == nil {
+				f.SetBool(b)
+			}
+		}
+	case isNumericKind(m.kind):
+		s, _ := scalarText(value)
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return
+		}
+		switch {
+		case f.CanInt():
+			f.SetInt(int64(n))
+		case f.CanUint() && n >= 0:
+			f.SetUint(uint64(n))
+		case f.CanFloat():
+			f.SetFloat(n)
+		}
+	}
+}
+
+// scalarText renders a JSON string, number or boolean as text.
+func scalarText(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+// rawResults pairs each result with the JSON object it was parsed from,
+// matching them by URL, since parsers may skip some objects. Results
+// without a match are left out.
+func rawResults(impl RepoSearcher, body []byte, repos []RepositorySummary) (map[int]any, error) {
+	src, ok := impl.(rawItemSource)
+	if !ok {
+		return nil, nil
+	}
+	itemsExpr, urlExpr := src.rawItemPaths()
+	itemsPath, err := compileJSONPath(itemsExpr)
+	if err != nil {
+		return nil, err
+	}
+	urlPath, err := compileJSONPath(urlExpr)
+	if err != nil {
+		return nil, err
+	}
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode raw results: %w", err)
+	}
+
+	byURL := make(map[string]any)
+	for _, item := range itemsPath.eval(doc) {
+		for _, u := range urlPath.eval(item) {
+			if s, ok := u.(string); ok && s != "" {
+				byURL[s] = item
+			}
+		}
+	}
+	matched := make(map[int]any, len(repos))
+	for i, r := range repos {
+		if item, ok := byURL[r.URL]; ok {
+			matched[i] = item
+		}
+	}
+	return matched, nil
+}
+
+// keepsRaw reports whether pages must be kept as read, for the field
+// mappings, raw fields or the raw archive.
+func (s *BaseRepoSearcher) keepsRaw() bool {
+	return len(s.FieldMappings) > 0 || s.RawArchive != nil
+}
+
+// applyRaw applies the field mappings to a page's results.
+func (s *BaseRepoSearcher) applyRaw(body []byte, repos []RepositorySummary) {
+	matched, err := rawResults(s.implementation, body, repos)
+	if err != nil {
+		log.Printf("Warning: %s: field mappings skipped for a page: %v", s.Source, err)
+		return
+	}
+	for i, raw := range matched {
+		for _, m := range s.FieldMappings {
+			m.apply(&repos[i], raw)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// --- Matrix Runs ---
+//
+// For ecosystem surveys, `-matrix "lang=go,rust topic=cli,tui"` turns the
+// query into a template: every {lang} and {topic} in it is replaced by each
+// combination of values in turn, giving four searches here. The results are
+// merged, and each repository lists the combinations that found it, so the
+// output can be grouped with -group-by matrix.
+
+// matrixVar is one -matrix variable and the values it takes.
+type matrixVar struct {
+	Name   string
+	Values []string
+}
+
+// matrixVars is the -matrix flag. It is repeatable, and one value may hold
+// several space-separated variables.
+type matrixVars []matrixVar
+
+// matrixVarName is what a variable may be called.
+var matrixVarName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// String implements flag.Value.
+func (m *matrixVars) String() string {
+	parts := make([]string, len(*m))
+	for i, v := range *m {
+		parts[i] = v.Name + "=" + strings.Join(v.Values, ",")
+	}
+	return strings.Join(parts, " ")
+}
+
+// Set implements flag.Value.
+func (m *matrixVars) Set(value string) error {
+	for _, part := range strings.Fields(value) {
+		name, values, ok := strings.Cut(part, "=")
+		if !ok || !matrixVarName.MatchString(name) {
+			return fmt.Errorf("invalid matrix variable %q: expected name=value,value", part)
+		}
+		if slices.ContainsFunc(*m, func(v matrixVar) bool { return v.Name == name }) {
+			return fmt.Errorf("matrix variable %q given twice", name)
+		}
+		list := splitList(values)
+		if len(list) == 0 {
+			return fmt.Errorf("matrix variable %q has no values", name)
+		}
+		*m = append(*m, matrixVar{Name: name, Values: list})
+	}
+	return nil
+}
+
+// isMatrixVar reports whether arg looks like a matrix variable (name=values).
+func isMatrixVar(arg string) bool {
+	name, _, ok := strings.Cut(arg, "=")
+	return ok && !strings.ContainsAny(arg, " \t") && matrixVarName.MatchString(name)
+}
+
+// validate checks that the query template uses every variable, as an unused
+// one would only repeat the same search.
+func (m matrixVars) validate(template string) error {
+	for _, v := range m {
+		if !strings.Contains(template, "{"+v.Name+"}") {
+			return fmt.Errorf("the query doesn't use matrix variable %q; add {%s} where its values go", v.Name, v.Name)
+		}
+	}
+	return nil
+}
+
+// matrixCombination is one value for each variable, in flag order.
+type matrixCombination []matrixBinding
+
+// matrixBinding is a variable set to one of its values.
+type matrixBinding struct {
+	Name, Value string
+}
+
+// combinations returns every combination of values, varying the last
+// variable fastest.
+func (m matrixVars) combinations() []matrixCombination {
+	combos := []matrixCombination{nil}
+	for _, v := range m {
+		var next []matrixCombination
+		for _, combo := range combos {
+			for _, value := range v.Values {
+				next = append(next, append(slices.Clone(combo), matrixBinding{v.Name, value}))
+			}
+		}
+		combos = next
+	}
+	return combos
+}
+
+// label names the combination, e.g. "lang=go topic=cli".
+func (c matrixCombination) label() string {
+	parts := make([]string, len(c))
+	for i, b := range c {
+		parts[i] = b.Name + "=" + b.Value
+	}
+	return strings.Join(parts, " ")
+}
+
+// expand fills the combination's values into the query template.
+func (c matrixCombination) expand(template string) string {
+	pairs := make([]string, 0, 2*len(c))
+	for _, b := range c {
+		pairs = append(pairs, "{"+b.Name+"}", b.Value)
+	}
+	return strings.NewReplacer(pairs...).Replace(template)
+}
+
+// matrixQueries returns the query of every combination.
+func matrixQueries(template string, combos []matrixCombination) []string {
+	queries := make([]string, len(combos))
+	for i, combo := range combos {
+		queries[i] = combo.expand(template)
+	}
+	return queries
+}
+
+// searchMatrix runs the search once per combination and merges the results.
+// A repository found by several combinations appears once, at its first
+// position, listing them all in Matrix. A combination that fails is skipped
+// with a warning, unless a searcher is strict; once the timeout is reached,
+// the remaining combinations are skipped.
+func searchMatrix(ctx context.Context, searchers []searcherTemplate, template string, combos []matrixCombination, pages int, sliceByDate bool, concurrency int) (*SearchResult, error) {
+	var results []*SearchResult
+	var lastErr error
+	index := make(map[string]int) // itemKey to position in the merged items
+	var items []RepositorySummary
+	for i, combo := range combos {
+		label := combo.label()
+		if err := ctx.Err(); err != nil {
+			log.Printf("Warning: skipping matrix combination %s: %v", label, err)
+			lastErr = err
+			continue
+		}
+		query := combo.expand(template)
+		log.Printf("Matrix combination %d/%d (%s): %q", i+1, len(combos), label, query)
+		result, err := searchAll(ctx, searchers, query, pages, sliceByDate, concurrency)
+		if err != nil {
+			for _, searcher := range searchers {
+				if b, ok := baseOf(searcher); ok && b.Strict {
+					return nil, fmt.Errorf("%s: %w", label, err)
+				}
+			}
+			log.Printf("Warning: matrix combination %s failed: %v. Continuing with the others.", label, err)
+			lastErr = err
+			continue
+		}
+		for _, item := range result.Items {
+			key := itemKey(item)
+			if j, ok := index[key]; ok {
+				items[j].Matrix = append(items[j].Matrix, label)
+				continue
+			}
+			item.Matrix = []string{label}
+			index[key] = len(items)
+			items = append(items, item)
+		}
+		log.Printf("Matrix combination %s retrieved %d repositories.", label, len(result.Items))
+		for j, w := range result.Warnings {
+			result.Warnings[j] = w + " (" + label + ")"
+		}
+		results = append(results, result)
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("all matrix combinations failed, last error: %w", lastErr)
+	}
+	merged := mergeResults(template, results)
+	merged.Items = items
+	// mergeResults names each provider once per combination.
+	var sources []string
+	for _, r := range results {
+		for _, source := range strings.Split(r.Source, "+") {
+			if !slices.Contains(sources, source) {
+				sources = append(sources, source)
+			}
+		}
+	}
+	merged.Source = strings.Join(sources, "+")
+	return merged, nil
+}
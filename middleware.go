@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http"
+)
+
+// --- HTTP Middleware ---
+
+// Middleware wraps the transport used for every request a searcher sends.
+// It can mutate requests (custom auth, extra headers), observe responses
+// (audit logging, metrics), or replace the transport outright (stubs).
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts an ordinary function to http.RoundTripper.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper.
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// MutateRequest returns middleware that calls fn on a copy of every request
+// before it is sent. The caller's request is never modified.
+func MutateRequest(fn func(*http.Request)) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			fn(req)
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// ObserveResponse returns middleware that calls fn after every round trip,
+// with either the response or the transport error. fn must not consume the
+// response body.
+func ObserveResponse(fn func(req *http.Request, resp *http.Response, err error)) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.RoundTrip(req)
+			fn(req, resp, err)
+			return resp, err
+		})
+	}
+}
+
+// Use appends middleware to the searcher's chain. The first middleware added
+// is the outermost, i.e. it sees each request first and each response last.
+// Use is part of configuration and must not be called during a search.
+func (s *BaseRepoSearcher) Use(mw ...Middleware) {
+	s.middleware = append(s.middleware, mw...)
+}
+
+// client returns the HTTP client to send requests with: HTTPClient, with its
+// transport wrapped in the middleware chain.
+func (s *BaseRepoSearcher) client() *http.Client {
+	if len(s.middleware) == 0 {
+		return s.HTTPClient
+	}
+	transport := s.HTTPClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	for i := len(s.middleware) - 1; i >= 0; i-- {
+		transport = s.middleware[i](transport)
+	}
+	c := *s.HTTPClient
+	c.Transport = transport
+	return &c
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// --- Multi-Service Searches ---
+
+// resolveServices expands a -service value into individual service names.
+// It accepts a single name, a comma-separated list, or "all"; @name stands
+// for the services of a provider group. A service listed twice is searched
+// once. Unknown groups are left as they are, for newSearcher to report.
+func resolveServices(value string) []string {
+	if strings.EqualFold(value, "all") {
+		return providerNames()
+	}
+	var services []string
+	for _, name := range splitList(value) {
+		expanded := []string{name}
+		if group, ok := strings.CutPrefix(name, "@"); ok {
+			if members, ok := providerGroup(group); ok {
+				expanded = members
+			}
+		}
+		for _, service := range expanded {
+			if !containsFold(services, service) {
+				services = append(services, service)
+			}
+		}
+	}
+	return services
+}
+
+// searchOne runs a single searcher. GitHub searches are sliced by date when
+// sliceByDate is set, the file hits of a GitLab blobs search are replaced
+// by their projects, and Gitee organization listings are matched against
+// the query.
+func searchOne(ctx context.Context, searcher searcherTemplate, query string, pages int, sliceByDate bool) (*SearchResult, error) {
+	if gh, ok := searcher.(*GitHubSearcher); ok && sliceByDate {
+		return gh.SearchSliced(ctx, query, pages)
+	}
+	result, err := searcher.Search(ctx, query, pages)
+	if gl, ok := searcher.(*GitLabSearcher); ok && err == nil && gl.Scope == "blobs" {
+		gl.resolveBlobProjects(ctx, result)
+	}
+	if gt, ok := searcher.(*GiteeSearcher); ok && err == nil && (gt.Org != "" || gt.Enterprise != "") {
+		gt.filterListing(result, query)
+	}
+	return result, err
+}
+
+// searchAll runs every searcher and merges their results, in searcher
+// order. Up to concurrency providers are crawled at once (see
+// -provider-concurrency), but searchers sharing an API host always take
+// turns, as they share its rate limit. A provider that fails is skipped
+// with a warning; the search only fails if all do, or if the failed
+// searcher is strict, which also stops the others.
+func searchAll(ctx context.Context, searchers []searcherTemplate, query string, pages int, sliceByDate bool, concurrency int) (*SearchResult, error) {
+	if len(searchers) == 1 {
+		return searchOne(ctx, searchers[0], query, pages, sliceByDate)
+	}
+
+	results := make([]*SearchResult, len(searchers))
+	errs := make([]error, len(searchers))
+	if concurrency <= 1 {
+		for i, searcher := range searchers {
+			results[i], errs[i] = searchOne(ctx, searcher, query, pages, sliceByDate)
+			if b, ok := baseOf(searcher); ok && errs[i] != nil && b.Strict {
+				break
+			}
+		}
+	} else {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		slots := make(chan struct{}, concurrency)
+		hosts := make(map[string]*sync.Mutex)
+		var wg sync.WaitGroup
+		for i, searcher := range searchers {
+			host := apiHost(searcher, i)
+			if hosts[host] == nil {
+				hosts[host] = &sync.Mutex{}
+			}
+			turn := hosts[host]
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				turn.Lock()
+				defer turn.Unlock()
+				slots <- struct{}{}
+				defer func() { <-slots }()
+				results[i], errs[i] = searchOne(ctx, searcher, query, pages, sliceByDate)
+				if b, ok := baseOf(searcher); ok && errs[i] != nil && b.Strict {
+					cancel()
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	var merged []*SearchResult
+	var lastErr error
+	for i, searcher := range searchers {
+		err := errs[i]
+		if b, ok := baseOf(searcher); ok && err != nil && b.Strict {
+			return nil, fmt.Errorf("%s: %w", b.Source, err)
+		}
+		if err != nil {
+			log.Printf("Warning: search failed on one provider: %v. Continuing with the others.", err)
+			lastErr = err
+			continue
+		}
+		if results[i] != nil {
+			merged = append(merged, results[i])
+		}
+	}
+	if len(merged) == 0 {
+		return nil, fmt.Errorf("all providers failed, last error: %w", lastErr)
+	}
+	return mergeResults(query, merged), nil
+}
+
+// apiHost is the host a searcher sends its requests to, for telling which
+// searchers share a rate limit. Searchers without a base URL get a key of
+// their own from their position i.
+func apiHost(searcher searcherTemplate, i int) string {
+	if b, ok := baseOf(searcher); ok {
+		if u, err := url.Parse(b.BaseURL); err == nil && u.Host != "" {
+			return strings.ToLower(u.Host)
+		}
+	}
+	return fmt.Sprintf("#%d", i)
+}
+
+// mergeResults combines per-provider results into one. The merged total is
+// unknown (-1) if any provider's total is unknown.
+func mergeResults(query string, results []*SearchResult) *SearchResult {
+	merged := &SearchResult{Query: query}
+	var sources []string
+	for _, r := range results {
+		sources = append(sources, r.Source)
+		merged.Items = append(merged.Items, r.Items...)
+		merged.Warnings = addWarning(merged.Warnings, r.Warnings...)
+		if r.TotalCount < 0 || merged.TotalCount < 0 {
+			merged.TotalCount = -1
+		} else {
+			merged.TotalCount += r.TotalCount
+		}
+	}
+	merged.Source = strings.Join(sources, "+")
+	return merged
+}
+
+// balanceByProvider keeps at most topN items per provider (0 means no limit),
+// preserving each provider's own order. With interleave, providers take
+// turns, so the first screen of output shows every provider.
+func balanceByProvider(items []RepositorySummary, topN int, interleave bool) []RepositorySummary {
+	groups := groupSummaries(items, "provider")
+	// groupSummaries orders by size; restore first-appearance order so the
+	// provider order given on the command line wins.
+	seen := make(map[string]int)
+	for _, item := range items {
+		if _, ok := seen[item.Provider]; !ok {
+			seen[item.Provider] = len(seen)
+		}
+	}
+	ordered := make([]summaryGroup, len(groups))
+	for _, g := range groups {
+		name := g.Name
+		if name == "(none)" {
+			name = ""
+		}
+		ordered[seen[name]] = g
+	}
+
+	for i := range ordered {
+		if topN > 0 && len(ordered[i].Items) > topN {
+			ordered[i].Items = ordered[i].Items[:topN]
+		}
+	}
+
+	var out []RepositorySummary
+	if !interleave {
+		for _, g := range ordered {
+			out = append(out, g.Items...)
+		}
+		return out
+	}
+	for i := 0; ; i++ {
+		added := false
+		for _, g := range ordered {
+			if i < len(g.Items) {
+				out = append(out, g.Items[i])
+				added = true
+			}
+		}
+		if !added {
+			return out
+		}
+	}
+}
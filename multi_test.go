@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/suntong/rexplorer/rexplorertest"
+)
+
+// fakeSearcher is a rexplorertest.FakeSearcher for rexplorer's types.
+type fakeSearcher = rexplorertest.FakeSearcher[SearchResult, SearchPlan]
+
+func TestResolveAllSkipsAlternateBackends(t *testing.T) {
+	all := resolveServices("all")
+	for _, p := range providers {
+		if got := slices.Contains(all, p.Name); got == p.Alternate {
+			t.Errorf("all includes %s: %v, want %v", p.Name, got, !p.Alternate)
+		}
+		if p.Alternate {
+			if got := resolveServices(p.Name); len(got) != 1 || got[0] != p.Name {
+				t.Errorf("resolveServices(%q) = %v", p.Name, got)
+			}
+		}
+	}
+}
+
+func TestSearchAllSkipsFailedProviders(t *testing.T) {
+	ok := &fakeSearcher{Result: &SearchResult{Source: "A", TotalCount: 1, Items: []RepositorySummary{{FullName: "o/a"}}}}
+	failed := &fakeSearcher{Err: errors.New("boom")}
+	for _, concurrency := range []int{1, 2} {
+		result, err := searchAll(context.Background(), []searcherTemplate{failed, ok}, "go", 1, false, concurrency)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Items) != 1 || result.Items[0].FullName != "o/a" {
+			t.Errorf("concurrency %d: got %v", concurrency, result.Items)
+		}
+	}
+	if got := failed.Queries(); !slices.Equal(got, []string{"go", "go"}) {
+		t.Errorf("failed provider was asked %v", got)
+	}
+
+	if _, err := searchAll(context.Background(), []searcherTemplate{failed, failed}, "go", 1, false, 1); err == nil {
+		t.Error("search succeeded with every provider failing")
+	}
+}
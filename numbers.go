@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// --- Number Formatting and Aggregate Statistics ---
+
+// thousandsSeparator picks the digit-group separator for the user's locale
+// (LC_ALL, LC_NUMERIC, then LANG), defaulting to a comma.
+func thousandsSeparator() string {
+	locale := ""
+	for _, env := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		if v := os.Getenv(env); v != "" {
+			locale = v
+			break
+		}
+	}
+	lang, _, _ := strings.Cut(strings.ToLower(locale), "_")
+	switch lang {
+	case "de", "es", "it", "nl", "pt", "da", "id", "tr", "el":
+		return "."
+	case "fr", "ru", "sv", "nb", "no", "fi", "pl", "cs", "uk":
+		return " "
+	}
+	return ","
+}
+
+// compactSuffixes abbreviate thousands, millions and billions.
+var compactSuffixes = []string{"k", "M", "B"}
+
+// formatCount formats a star/fork/issue count for display. Negative counts
+// mean "not available" (see the Bitbucket provider). With compact, large
+// numbers are abbreviated (12.3k, 4.5M); otherwise digits are grouped.
+func formatCount(n int, compact bool) string {
+	if n < 0 {
+		return "n/a"
+	}
+	if compact && n >= 1_000 {
+		// Round first, so 999,950 is 1M rather than 1000k.
+		unit := 0
+		v := math.Round(float64(n)/1e2) / 10
+		for v >= 1000 && unit < len(compactSuffixes)-1 {
+			unit++
+			v = math.Round(float64(n)/math.Pow(1000, float64(unit+1))*10) / 10
+		}
+		return strings.TrimSuffix(strconv.FormatFloat(v, 'f', 1, 64), ".0") + compactSuffixes[unit]
+	}
+	if compact {
+		return strconv.Itoa(n)
+	}
+
+	digits := strconv.Itoa(n)
+	sep := thousandsSeparator()
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteString(sep)
+		}
+		b.WriteRune(d)
+	}
+	return b.String()
+}
+
+// resultStats are aggregate figures shown in the output footer.
+type resultStats struct {
+	Count     int
+	Stars     int // Sum over results that report stars
+	Forks     int
+	MedianAge time.Duration // Median time since creation; 0 if unknown
+}
+
+// computeStats aggregates items as of now.
+func computeStats(items []RepositorySummary, now time.Time) resultStats {
+	stats := resultStats{Count: len(items)}
+	var ages []time.Duration
+	for _, item := range items {
+		if item.Stars > 0 {
+			stats.Stars += item.Stars
+		}
+		if item.Forks > 0 {
+			stats.Forks += item.Forks
+		}
+		if created, ok := parseTimestamp(item.CreatedAt); ok {
+			ages = append(ages, now.Sub(created))
+		}
+	}
+	if len(ages) > 0 {
+		sort.Slice(ages, func(i, j int) bool { return ages[i] < ages[j] })
+		mid := len(ages) / 2
+		stats.MedianAge = ages[mid]
+		if len(ages)%2 == 0 {
+			stats.MedianAge = (ages[mid-1] + ages[mid]) / 2
+		}
+	}
+	return stats
+}
+
+// formatAge renders a duration as a rough human age ("3.2 years", "5 months").
+func formatAge(d time.Duration) string {
+	days := d.Hours() / 24
+	switch {
+	case days >= 365:
+		return fmt.Sprintf("%.1f years", days/365)
+	case days >= 30:
+		return fmt.Sprintf("%.0f months", days/30)
+	}
+	return fmt.Sprintf("%.0f days", days)
+}
+
+// footer renders the aggregate statistics line.
+func (s resultStats) footer(compact bool) string {
+	line := fmt.Sprintf("Total: %s repositories | %s stars | %s forks",
+		formatCount(s.Count, compact), formatCount(s.Stars, compact), formatCount(s.Forks, compact))
+	if s.MedianAge > 0 {
+		line += " | median age " + formatAge(s.MedianAge)
+	}
+	return line
+}
@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestFormatCountCompact(t *testing.T) {
+	for n, want := range map[int]string{
+		-1:            "n/a",
+		999:           "999",
+		1_000:         "1k",
+		12_345:        "12.3k",
+		999_949:       "999.9k",
+		999_950:       "1M",
+		999_999:       "1M",
+		1_250_000:     "1.3M",
+		999_950_000:   "1B",
+		2_500_000_000: "2.5B",
+	} {
+		if got := formatCount(n, true); got != want {
+			t.Errorf("formatCount(%d, true) = %q, want %q", n, got, want)
+		}
+	}
+}
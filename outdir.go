@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// --- Output Directories ---
+//
+// With -out-dir, every run writes its files into a new timestamped folder
+// under the directory, so scheduled runs never overwrite each other, and a
+// "latest" symlink points at the newest successful one.
+
+// runDirLayout names run folders; it sorts chronologically.
+const runDirLayout = "20060102T150405Z"
+
+// latestLink is the name of the symlink to the newest run.
+const latestLink = "latest"
+
+// newRunDir creates the folder for a run that started at started. Runs in
+// the same second get a numeric suffix.
+func newRunDir(base string, started time.Time) (string, error) {
+	if err := os.MkdirAll(base, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create output directory %s: %w", base, err)
+	}
+	name := started.UTC().Format(runDirLayout)
+	for i := 1; ; i++ {
+		dir := filepath.Join(base, name)
+		if i > 1 {
+			dir = fmt.Sprintf("%s-%d", dir, i)
+		}
+		err := os.Mkdir(dir, 0o755)
+		if err == nil {
+			return dir, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return "", fmt.Errorf("failed to create run directory: %w", err)
+		}
+	}
+}
+
+// inRunDir places a relative output path inside dir. Absolute paths, and
+// every path when dir is empty, are left alone.
+func inRunDir(dir, path string) string {
+	if dir == "" || path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// updateLatest points base/latest at runDir. The link is relative, so the
+// whole directory can be moved, and is replaced atomically.
+func updateLatest(base, runDir string) error {
+	link := filepath.Join(base, latestLink)
+	tmp := link + ".tmp"
+	os.Remove(tmp)
+	if err := os.Symlink(filepath.Base(runDir), tmp); err != nil {
+		return fmt.Errorf("failed to link latest run: %w", err)
+	}
+	if err := os.Rename(tmp, link); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to link latest run: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// --- Package Registries ---
+//
+// Finding a repository is not the same as finding something to depend on.
+// The packages enricher checks whether a Go, JavaScript or Python repository
+// is published to its language's registry. A registry entry only counts if
+// it points back at the repository, so a same-named package by someone
+// else isn't mistaken for it.
+
+// PackageInfo describes a repository's package in a registry.
+type PackageInfo struct {
+	Registry string `json:"registry"`
+	Name     string `json:"name"`
+	Version  string `json:"version,omitempty"`
+	URL      string `json:"url"`
+	// MonthlyDownloads is reported by npm and PyPI, not by the Go proxy.
+	MonthlyDownloads int `json:"monthly_downloads,omitempty"`
+}
+
+// packageRegistries finds the package for a repository written in a
+// language, by its lowercase name.
+var packageRegistries = map[string]func(ctx context.Context, s *BaseRepoSearcher, item *RepositorySummary) (*PackageInfo, error){
+	"go":         goModule,
+	"javascript": npmPackage,
+	"typescript": npmPackage,
+	"python":     pypiPackage,
+}
+
+// enrichPackages fills in Package for repositories in supported languages.
+func enrichPackages(ctx context.Context, s *BaseRepoSearcher, item *RepositorySummary) error {
+	lookup, ok := packageRegistries[strings.ToLower(item.Language)]
+	if !ok {
+		return nil
+	}
+	pkg, err := lookup(ctx, s, item)
+	if isNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	item.Package = pkg
+	return nil
+}
+
+// fetchRegistryJSON GETs a registry API document into v.
+func fetchRegistryJSON(ctx context.Context, s *BaseRepoSearcher, rawURL string, v any) error {
+	body, err := s.fetchPublic(ctx, rawURL, "application/json")
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	if err := json.NewDecoder(body).Decode(v); err != nil {
+		return fmt.Errorf("failed to unmarshal registry response: %w", err)
+	}
+	return nil
+}
+
+// repoPath is the repository URL without its scheme, e.g.
+// "github.com/owner/repo", which is also its Go module path.
+func repoPath(item *RepositorySummary) string {
+	u, err := url.Parse(item.URL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return strings.ToLower(u.Host) + strings.TrimSuffix(u.Path, "/")
+}
+
+// pointsAt reports whether a registry's repository link is the repository.
+func pointsAt(link string, item *RepositorySummary) bool {
+	p := repoPath(item)
+	link = strings.ToLower(strings.TrimSuffix(link, ".git"))
+	p = strings.ToLower(p)
+	return p != "" && (strings.HasSuffix(link, p) || strings.Contains(link, p+"/"))
+}
+
+// escapeModulePath applies the Go module proxy's case encoding: each upper
+// case letter becomes '!' and its lower case form.
+func escapeModulePath(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		if unicode.IsUpper(r) {
+			b.WriteByte('!')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// goModule asks the Go module proxy for the module at the repository root.
+func goModule(ctx context.Context, s *BaseRepoSearcher, item *RepositorySummary) (*PackageInfo, error) {
+	module := repoPath(item)
+	if module == "" {
+		return nil, nil
+	}
+	var latest struct {
+		Version string `json:"Version"`
+	}
+	if err := fetchRegistryJSON(ctx, s, "https://proxy.golang.org/"+escapeModulePath(module)+"/@latest", &latest); err != nil {
+		return nil, err
+	}
+	return &PackageInfo{Registry: "Go", Name: module, Version: latest.Version, URL: "https://pkg.go.dev/" + module}, nil
+}
+
+// npmPackage looks up the repository's name on npm.
+func npmPackage(ctx context.Context, s *BaseRepoSearcher, item *RepositorySummary) (*PackageInfo, error) {
+	name := strings.ToLower(item.Name)
+	var doc struct {
+		DistTags struct {
+			Latest string `json:"latest"`
+		} `json:"dist-tags"`
+		Repository json.RawMessage `json:"repository"` // A string or {"url": ...}
+	}
+	if err := fetchRegistryJSON(ctx, s, "https://registry.npmjs.org/"+url.PathEscape(name), &doc); err != nil {
+		return nil, err
+	}
+	var repo struct {
+		URL string `json:"url"`
+	}
+	if json.Unmarshal(doc.Repository, &repo) != nil {
+		_ = json.Unmarshal(doc.Repository, &repo.URL)
+	}
+	if !pointsAt(repo.URL, item) {
+		return nil, nil
+	}
+	pkg := &PackageInfo{Registry: "npm", Name: name, Version: doc.DistTags.Latest, URL: "https://www.npmjs.com/package/" + name}
+	var downloads struct {
+		Downloads int `json:"downloads"`
+	}
+	if err := fetchRegistryJSON(ctx, s, "https://api.npmjs.org/downloads/point/last-month/"+url.PathEscape(name), &downloads); err == nil {
+		pkg.MonthlyDownloads = downloads.Downloads
+	}
+	return pkg, nil
+}
+
+// pypiPackage looks up the repository's name on PyPI.
+func pypiPackage(ctx context.Context, s *BaseRepoSearcher, item *RepositorySummary) (*PackageInfo, error) {
+	name := strings.ToLower(item.Name)
+	var doc struct {
+		Info struct {
+			Name        string            `json:"name"`
+			Version     string            `json:"version"`
+			HomePage    string            `json:"home_page"`
+			ProjectURLs map[string]string `json:"project_urls"`
+		} `json:"info"`
+	}
+	if err := fetchRegistryJSON(ctx, s, "https://pypi.org/pypi/"+url.PathEscape(name)+"/json", &doc); err != nil {
+		return nil, err
+	}
+	linked := pointsAt(doc.Info.HomePage, item)
+	for _, link := range doc.Info.ProjectURLs {
+		linked = linked || pointsAt(link, item)
+	}
+	if !linked {
+		return nil, nil
+	}
+	pkg := &PackageInfo{Registry: "PyPI", Name: doc.Info.Name, Version: doc.Info.Version, URL: "https://pypi.org/project/" + doc.Info.Name + "/"}
+	var stats struct {
+		Data struct {
+			LastMonth int `json:"last_month"`
+		} `json:"data"`
+	}
+	if err := fetchRegistryJSON(ctx, s, "https://pypistats.org/api/packages/"+url.PathEscape(name)+"/recent", &stats); err == nil {
+		pkg.MonthlyDownloads = stats.Data.LastMonth
+	}
+	return pkg, nil
+}
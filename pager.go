@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// --- Pager ---
+
+// isTerminal reports whether f is an interactive terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// terminalHeight returns the number of rows of the controlling terminal,
+// falling back to 24 when it can't be determined.
+func terminalHeight() int {
+	if n, err := strconv.Atoi(os.Getenv("LINES")); err == nil && n > 0 {
+		return n
+	}
+	if tty, err := os.Open("/dev/tty"); err == nil {
+		defer tty.Close()
+		cmd := exec.Command("stty", "size")
+		cmd.Stdin = tty
+		if out, err := cmd.Output(); err == nil {
+			if rows, _, ok := strings.Cut(strings.TrimSpace(string(out)), " "); ok {
+				if n, err := strconv.Atoi(rows); err == nil && n > 0 {
+					return n
+				}
+			}
+		}
+	}
+	return 24
+}
+
+// writePaged writes output to stdout, piping it through $PAGER (default
+// "less -R") when stdout is a terminal and the output doesn't fit on one
+// screen, the way git does. If the pager can't be started, the output is
+// written directly.
+func writePaged(output []byte, noPager bool) error {
+	if noPager || !isTerminal(os.Stdout) || bytes.Count(output, []byte("\n")) < terminalHeight() {
+		_, err := os.Stdout.Write(output)
+		return err
+	}
+
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		pager = "less -R"
+	}
+	if pager == "cat" {
+		_, err := os.Stdout.Write(output)
+		return err
+	}
+
+	cmd := exec.Command("sh", "-c", pager)
+	cmd.Stdin = bytes.NewReader(output)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// Like git: quit if one screen, keep colors, don't clear the screen.
+	if os.Getenv("LESS") == "" {
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return nil // The user quit the pager; that's not an error
+		}
+		_, err := io.Copy(os.Stdout, bytes.NewReader(output))
+		return err
+	}
+	return nil
+}
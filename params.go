@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// --- Provider Query Parameters ---
+//
+// `-provider-param gitlab:order_by=stars -provider-param gitee:sort=stars_count`
+// adds query parameters to one provider's search requests, for provider
+// features the unified flags don't cover yet. A parameter replaces the
+// provider's own value for the same key; giving a key twice sends both
+// values. GraphQL services put their query in the request body and take no
+// parameters.
+
+// providerParams is the -provider-param flag, keyed by lowercased service
+// name. It can be repeated.
+type providerParams map[string]url.Values
+
+// String implements flag.Value.
+func (p *providerParams) String() string {
+	var parts []string
+	for service, params := range *p {
+		for key, values := range params {
+			for _, v := range values {
+				parts = append(parts, service+":"+key+"="+v)
+			}
+		}
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, " ")
+}
+
+// Set implements flag.Value.
+func (p *providerParams) Set(value string) error {
+	service, param, ok := strings.Cut(value, ":")
+	key, v, ok2 := strings.Cut(param, "=")
+	service, key = strings.TrimSpace(service), strings.TrimSpace(key)
+	if !ok || !ok2 || service == "" || key == "" {
+		return fmt.Errorf("invalid parameter %q: expected service:key=value, e.g. gitlab:order_by=stars", value)
+	}
+	if *p == nil {
+		*p = make(providerParams)
+	}
+	service = strings.ToLower(service)
+	if (*p)[service] == nil {
+		(*p)[service] = make(url.Values)
+	}
+	(*p)[service].Add(key, v)
+	return nil
+}
+
+// validate checks that every parameter is for a known service.
+func (p providerParams) validate() error {
+	for service := range p {
+		if _, ok := lookupProvider(service); !ok {
+			return fmt.Errorf("unknown service %q; must be one of %s", service, strings.Join(append(serviceNames(), instanceNames()...), ", "))
+		}
+	}
+	return nil
+}
+
+// withExtraParams adds the searcher's extra parameters to a search URL.
+func (s *BaseRepoSearcher) withExtraParams(rawURL string) (string, error) {
+	if len(s.ExtraParams) == 0 {
+		return rawURL, nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse search URL: %w", err)
+	}
+	q := u.Query()
+	for key, values := range s.ExtraParams {
+		q[key] = values
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// --- Parsing Benchmarks ---
+//
+// Each benchmark parses one full page in the provider's wire format:
+// benchPageSize repositories with the fields a real search returns. Run
+// with go test -bench ParseSearchResponse -run '^$'.
+
+// benchPageSize is the number of repositories on a benchmark page.
+const benchPageSize = 100
+
+// benchPage repeats item benchPageSize times, numbering each copy in place
+// of {i}, and wraps the list in envelope in place of {items}.
+func benchPage(envelope, item string) []byte {
+	items := make([]string, benchPageSize)
+	languages := []string{"Go", "Rust", "Python", "TypeScript", "C"}
+	for i := range items {
+		items[i] = strings.NewReplacer("{i}", strconv.Itoa(i), "{lang}", languages[i%len(languages)]).Replace(item)
+	}
+	return []byte(strings.Replace(envelope, "{items}", strings.Join(items, ","), 1))
+}
+
+// benchmarkParse parses body with s over and over.
+func benchmarkParse(b *testing.B, s RepoSearcher, body []byte) {
+	b.ReportAllocs()
+	b.SetBytes(int64(len(body)))
+	for b.Loop() {
+		items, _, _, err := s.parseSearchResponse(bytes.NewReader(body))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(items) != benchPageSize {
+			b.Fatalf("parsed %d items, want %d", len(items), benchPageSize)
+		}
+	}
+}
+
+func BenchmarkParseSearchResponseGitHub(b *testing.B) {
+	body := benchPage(`{"total_count": 48213, "incomplete_results": false, "items": [{items}]}`, `{
+		"id": 1000{i}, "name": "project-{i}", "full_name": "owner{i}/project-{i}",
+		"description": "A fast, small and well tested library for doing things, number {i}",
+		"private": false, "fork": false, "html_url": "https://github.com/owner{i}/project-{i}",
+		"created_at": "2019-03-14T09:26:53Z", "updated_at": "2026-09-30T17:02:11Z",
+		"stargazers_count": 12{i}, "forks_count": {i}, "language": "{lang}", "archived": false,
+		"open_issues_count": 7, "license": {"key": "mit", "name": "MIT License", "spdx_id": "MIT"},
+		"topics": ["cli", "library", "{lang}"], "default_branch": "main",
+		"homepage": "https://project-{i}.dev", "has_pages": true}`)
+	benchmarkParse(b, NewGitHubSearcher("", nil), body)
+}
+
+func BenchmarkParseSearchResponseGitHubGraphQL(b *testing.B) {
+	body := benchPage(`{"data": {"rateLimit": {"cost": 1, "remaining": 4990, "resetAt": "2026-10-16T20:00:00Z"},
+		"search": {"repositoryCount": 48213, "pageInfo": {"endCursor": "Y3Vyc29yOjEwMA==", "hasNextPage": true}, "nodes": [{items}]}}}`, `{
+		"name": "project-{i}", "nameWithOwner": "owner{i}/project-{i}",
+		"description": "A fast, small and well tested library for doing things, number {i}",
+		"url": "https://github.com/owner{i}/project-{i}", "homepageUrl": "https://project-{i}.dev",
+		"isPrivate": false, "isFork": false, "isArchived": false,
+		"createdAt": "2019-03-14T09:26:53Z", "pushedAt": "2026-09-30T17:02:11Z",
+		"stargazerCount": 12{i}, "forkCount": {i}, "primaryLanguage": {"name": "{lang}"},
+		"licenseInfo": {"name": "MIT License"}, "defaultBranchRef": {"name": "main"}, "parent": null,
+		"issues": {"totalCount": 7},
+		"repositoryTopics": {"nodes": [{"topic": {"name": "cli"}}, {"topic": {"name": "library"}}]},
+		"languages": {"totalSize": 120000, "edges": [{"size": 100000, "node": {"name": "{lang}"}}, {"size": 20000, "node": {"name": "Shell"}}]}}`)
+	benchmarkParse(b, NewGitHubGraphQLSearcher("", nil), body)
+}
+
+func BenchmarkParseSearchResponseGitLab(b *testing.B) {
+	body := benchPage(`[{items}]`, `{
+		"id": 2000{i}, "name": "project-{i}", "path_with_namespace": "group{i}/sub/project-{i}",
+		"description": "A fast, small and well tested library for doing things, number {i}",
+		"visibility": "public", "web_url": "https://gitlab.com/group{i}/sub/project-{i}",
+		"created_at": "2019-03-14T09:26:53.000Z", "last_activity_at": "2026-09-30T17:02:11.000Z",
+		"star_count": 12{i}, "forks_count": {i}, "archived": false, "open_issues_count": 7,
+		"topics": ["cli", "library"], "license": {"name": "MIT License"},
+		"forked_from_project": null, "default_branch": "main"}`)
+	benchmarkParse(b, NewGitLabSearcher("", nil), body)
+}
+
+func BenchmarkParseSearchResponseGitLabGraphQL(b *testing.B) {
+	body := benchPage(`{"data": {"projects": {"count": 48213, "pageInfo": {"endCursor": "eyJpZCI6IjEwMCJ9", "hasNextPage": true}, "nodes": [{items}]}}}`, `{
+		"name": "project-{i}", "fullPath": "group{i}/sub/project-{i}",
+		"description": "A fast, small and well tested library for doing things, number {i}",
+		"webUrl": "https://gitlab.com/group{i}/sub/project-{i}", "visibility": "public",
+		"createdAt": "2019-03-14T09:26:53Z", "lastActivityAt": "2026-09-30T17:02:11Z",
+		"starCount": 12{i}, "forksCount": {i}, "archived": false, "openIssuesCount": 7,
+		"topics": ["cli", "library"], "namespace": {"fullPath": "group{i}/sub", "name": "sub"},
+		"languages": [{"name": "{lang}", "share": 83.3}, {"name": "Shell", "share": 16.7}],
+		"repository": {"rootRef": "main"}}`)
+	benchmarkParse(b, NewGitLabGraphQLSearcher("", nil), body)
+}
+
+func BenchmarkParseSearchResponseBitbucket(b *testing.B) {
+	body := benchPage(`{"size": 48213, "page": 1, "next": "https://api.bitbucket.org/2.0/repositories?page=2", "values": [{items}]}`, `{
+		"name": "project-{i}", "full_name": "workspace{i}/project-{i}",
+		"description": "A fast, small and well tested library for doing things, number {i}",
+		"language": "{lang}", "created_on": "2019-03-14T09:26:53.000000+00:00",
+		"updated_on": "2026-09-30T17:02:11.000000+00:00", "is_private": false, "parent": null,
+		"mainbranch": {"name": "main", "type": "branch"}, "website": "https://project-{i}.dev",
+		"links": {"html": {"href": "https://bitbucket.org/workspace{i}/project-{i}"}}}`)
+	benchmarkParse(b, NewBitbucketSearcher("", nil), body)
+}
+
+func BenchmarkParseSearchResponseGitCode(b *testing.B) {
+	body := benchPage(`[{items}]`, `{
+		"id": 3000{i}, "name": "project-{i}", "full_name": "owner{i}/project-{i}",
+		"description": "A fast, small and well tested library for doing things, number {i}",
+		"private": false, "fork": false, "html_url": "https://gitcode.com/owner{i}/project-{i}",
+		"created_at": "2019-03-14T09:26:53+08:00", "updated_at": "2026-09-30T17:02:11+08:00",
+		"stargazers_count": 12{i}, "forks_count": {i}, "language": "{lang}", "archived": false,
+		"open_issues_count": 7, "license": {"name": "MIT"}, "topics": ["cli", "library"],
+		"parent": null, "default_branch": "main", "homepage": "https://project-{i}.dev"}`)
+	benchmarkParse(b, NewGitCodeSearcher("", nil), body)
+}
+
+func BenchmarkParseSearchResponseGitee(b *testing.B) {
+	body := benchPage(`[{items}]`, `{
+		"id": 4000{i}, "name": "project-{i}", "full_name": "owner{i}/project-{i}",
+		"description": "A fast, small and well tested library for doing things, number {i}",
+		"private": false, "fork": false, "html_url": "https://gitee.com/owner{i}/project-{i}",
+		"created_at": "2019-03-14T09:26:53+08:00", "updated_at": "2026-09-30T17:02:11+08:00",
+		"stargazers_count": 12{i}, "forks_count": {i}, "language": "{lang}", "archived": false,
+		"open_issues_count": 7, "license": "MIT", "topics": ["cli", "library"],
+		"parent": null, "default_branch": "master", "homepage": "https://project-{i}.dev"}`)
+	benchmarkParse(b, NewGiteeSearcher("", nil), body)
+}
+
+func BenchmarkParseSearchResponseCodeberg(b *testing.B) {
+	body := benchPage(`{"ok": true, "data": [{items}]}`, `{
+		"id": 5000{i}, "name": "project-{i}", "full_name": "owner{i}/project-{i}",
+		"description": "A fast, small and well tested library for doing things, number {i}",
+		"private": false, "fork": false, "html_url": "https://codeberg.org/owner{i}/project-{i}",
+		"created_at": "2019-03-14T09:26:53+01:00", "updated_at": "2026-09-30T17:02:11+02:00",
+		"stars_count": 12{i}, "forks_count": {i}, "language": "{lang}", "archived": false,
+		"open_issues_count": 7, "topics": ["cli", "library"], "parent": null,
+		"default_branch": "main", "website": "https://project-{i}.dev"}`)
+	benchmarkParse(b, NewCodebergSearcher("", nil), body)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// --- Provider Health Check ---
+
+// healthChecker is implemented by providers with a cheap endpoint to ping.
+// Providers without one are pinged with a one-item search.
+type healthChecker interface {
+	healthCheckURL() string
+}
+
+// PingResult describes one provider's health.
+type PingResult struct {
+	Source     string
+	Reachable  bool
+	Latency    time.Duration
+	Status     int
+	Auth       string // "authenticated", "anonymous", "rejected" or "unknown"
+	APIVersion string
+	Err        error
+}
+
+// Ping issues a single cheap request (no retries) and reports reachability,
+// latency, authentication status and API version.
+func (s *BaseRepoSearcher) Ping(ctx context.Context) PingResult {
+	result := PingResult{Source: s.Source, Auth: "unknown"}
+	// Most providers version their API in the base path (/api/v4, /2.0).
+	if u, err := url.Parse(s.BaseURL); err == nil && strings.Trim(u.Path, "/") != "" {
+		result.APIVersion = path.Base(u.Path)
+	}
+
+	var target string
+	if hc, ok := s.implementation.(healthChecker); ok {
+		target = hc.healthCheckURL()
+	} else {
+		u, err := s.implementation.buildSearchURL("test", 1, 1)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		target = u
+	}
+
+	req, err := s.implementation.buildSearchRequest(ctx, target)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if err := s.authorize(req); err != nil {
+		result.Err = err
+		return result
+	}
+
+	start := time.Now()
+	resp, err := s.client().Do(req)
+	result.Latency = time.Since(start)
+	if err != nil {
+		result.Err = redactError(err)
+		return result
+	}
+	defer resp.Body.Close()
+	result.Reachable = true
+	result.Status = resp.StatusCode
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		result.Auth = "rejected"
+		if s.Token == "" {
+			result.Auth = "anonymous (token required)"
+		}
+	case resp.StatusCode < 300 && s.Token != "":
+		result.Auth = "authenticated"
+	case resp.StatusCode < 300:
+		result.Auth = "anonymous"
+	default:
+		result.Err = fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	if v := resp.Header.Get("X-GitHub-Media-Type"); v != "" {
+		result.APIVersion = v
+	}
+	// GitLab's /version endpoint reports the server version in the body.
+	var version struct {
+		Version string `json:"version"`
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if json.Unmarshal(body, &version) == nil && version.Version != "" {
+		result.APIVersion = strings.TrimSpace(result.APIVersion + " (server " + version.Version + ")")
+	}
+	return result
+}
+
+// runPing implements `rexplorer ping`.
+func runPing(args []string) error {
+	fs := flag.NewFlagSet("ping", flag.ExitOnError)
+	service := fs.String("service", "all", "Service(s) to check: a name, a comma-separated list, or all")
+	timeout := fs.Duration("timeout", 15*time.Second, "Timeout per provider")
+	configFile := fs.String("config", "", "Configuration file with instances and provider groups (default: ~/.config/rexplorer/config.json)")
+	fs.Parse(args)
+	// Loading the configuration selects its instances and groups.
+	if _, err := loadConfig(*configFile); err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: *timeout}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "PROVIDER\tREACHABLE\tLATENCY\tAUTH\tAPI VERSION\tERROR")
+	failed := 0
+	for _, name := range resolveServices(*service) {
+		p, ok := lookupProvider(name)
+		if !ok {
+			return fmt.Errorf("unknown service %q; must be one of %s", name, strings.Join(append(serviceNames(), instanceNames()...), ", "))
+		}
+		token := os.Getenv(p.TokenEnv)
+		registerSecret(token)
+		searcher, _ := baseOf(p.New(token, client))
+
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		r := searcher.Ping(ctx)
+		cancel()
+
+		errText := ""
+		if r.Err != nil {
+			errText = r.Err.Error()
+			failed++
+		}
+		reachable := "no"
+		if r.Reachable {
+			reachable = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", r.Source, reachable,
+			r.Latency.Round(time.Millisecond), r.Auth, r.APIVersion, strings.TrimSpace(errText))
+	}
+	w.Flush()
+	if failed > 0 {
+		return fmt.Errorf("%d provider(s) failed the health check", failed)
+	}
+	return nil
+}
+
+// healthCheckURL implements healthChecker. /rate_limit doesn't count
+// against the rate limit.
+func (g *GitHubSearcher) healthCheckURL() string { return g.BaseURL + "/rate_limit" }
+
+// healthCheckURL implements healthChecker.
+func (g *GitLabSearcher) healthCheckURL() string { return g.BaseURL + "/version" }
+
+// healthCheckURL implements healthChecker.
+func (b *BitbucketSearcher) healthCheckURL() string { return b.BaseURL + "/user" }
+
+// healthCheckURL implements healthChecker.
+func (g *GiteeSearcher) healthCheckURL() string { return g.BaseURL + "/user" }
+
+// healthCheckURL implements healthChecker. /version needs no token.
+func (c *CodebergSearcher) healthCheckURL() string { return c.BaseURL + "/version" }
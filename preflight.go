@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// --- Preflight Validation ---
+//
+// Providers answer a malformed query with 422 (or silently ignore part of
+// it), which only shows up after the request has been sent and retried.
+// These checks run before anything is sent and explain what to fix.
+
+// queryValidator is implemented by providers with query syntax to check.
+type queryValidator interface {
+	validateQuery(query string) error
+}
+
+// preflight checks the flags and the query against every selected searcher.
+func preflight(searchers []searcherTemplate, query string, pages int, timeout time.Duration) error {
+	if strings.TrimSpace(query) == "" {
+		return fmt.Errorf("empty search query")
+	}
+	if pages < 1 {
+		return fmt.Errorf("-pages must be at least 1, got %d", pages)
+	}
+	if timeout <= 0 {
+		return fmt.Errorf("-timeout must be positive, got %s", timeout)
+	}
+	for _, searcher := range searchers {
+		b, ok := baseOf(searcher)
+		if !ok {
+			continue
+		}
+		if v, ok := b.implementation.(queryValidator); ok {
+			if err := v.validateQuery(query); err != nil {
+				return fmt.Errorf("invalid %s query: %w", b.Source, err)
+			}
+		}
+	}
+	return nil
+}
+
+// queryTerms splits a query on whitespace, keeping double-quoted phrases
+// together. It reports an error for an unterminated quote.
+func queryTerms(query string) ([]string, error) {
+	var terms []string
+	var term strings.Builder
+	quoted := false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			term.WriteRune(r)
+		case !quoted && (r == ' ' || r == '\t' || r == '\n'):
+			if term.Len() > 0 {
+				terms = append(terms, term.String())
+				term.Reset()
+			}
+		default:
+			term.WriteRune(r)
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote in %q", query)
+	}
+	if term.Len() > 0 {
+		terms = append(terms, term.String())
+	}
+	return terms, nil
+}
+
+// closestWord returns the candidate nearest to word by edit distance, if it
+// is within maxDistance.
+func closestWord(word string, candidates []string, maxDistance int) (string, bool) {
+	best, bestDistance := "", maxDistance+1
+	for _, c := range candidates {
+		if d := editDistance(word, c); d < bestDistance {
+			best, bestDistance = c, d
+		}
+	}
+	return best, bestDistance <= maxDistance
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// --- GitHub ---
+
+// gitHubQualifiers are the qualifiers accepted by GitHub repository search,
+// mapped to the pattern their values must match (nil for free text).
+var gitHubQualifiers = map[string]*regexp.Regexp{
+	"in":                 regexp.MustCompile(`^(name|description|readme|topics)(,(name|description|readme|topics))*$`),
+	"user":               nil,
+	"org":                nil,
+	"owner":              nil,
+	"repo":               nil,
+	"language":           nil,
+	"topic":              nil,
+	"license":            nil,
+	"fork":               regexp.MustCompile(`^(true|only)$`),
+	"is":                 regexp.MustCompile(`^(public|private|internal|template|sponsorable|archived|mirror)$`),
+	"archived":           regexp.MustCompile(`^(true|false)$`),
+	"mirror":             regexp.MustCompile(`^(true|false)$`),
+	"template":           regexp.MustCompile(`^(true|false)$`),
+	"has":                regexp.MustCompile(`^funding-file$`),
+	"sort":               regexp.MustCompile(`^(stars|forks|help-wanted-issues|updated)(-(asc|desc))?$`),
+	"stars":              gitHubRange,
+	"forks":              gitHubRange,
+	"size":               gitHubRange,
+	"followers":          gitHubRange,
+	"topics":             gitHubRange,
+	"good-first-issues":  gitHubRange,
+	"help-wanted-issues": gitHubRange,
+	"created":            gitHubDateRange,
+	"pushed":             gitHubDateRange,
+}
+
+var (
+	gitHubRange     = regexp.MustCompile(`^((<|<=|>|>=)?\d+|(\d+|\*)\.\.(\d+|\*))$`)
+	gitHubDateRange = regexp.MustCompile(`^((<|<=|>|>=)?\d{4}-\d{2}-\d{2}(T[\d:+Z-]+)?|(\d{4}-\d{2}-\d{2}(T[\d:+Z-]+)?|\*)\.\.(\d{4}-\d{2}-\d{2}(T[\d:+Z-]+)?|\*))$`)
+	// gitHubQualifierTerm matches a term that looks like a qualifier: a
+	// word, optionally negated, followed by a colon.
+	gitHubQualifierTerm = regexp.MustCompile(`^-?([a-zA-Z][a-zA-Z-]*):(.*)$`)
+)
+
+// validateQuery implements queryValidator for GitHub.
+func (g *GitHubSearcher) validateQuery(query string) error {
+	terms, err := queryTerms(query)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(gitHubQualifiers))
+	for name := range gitHubQualifiers {
+		names = append(names, name)
+	}
+	sort.Strings(names) // Stable suggestions when two are equally close
+
+	for _, term := range terms {
+		m := gitHubQualifierTerm.FindStringSubmatch(term)
+		if m == nil {
+			continue
+		}
+		name, value := strings.ToLower(m[1]), strings.Trim(m[2], `"`)
+		pattern, known := gitHubQualifiers[name]
+		if !known {
+			// GitHub adds qualifiers now and then, so an unknown one close
+			// to a known one is only a likely typo; anything farther is
+			// probably plain text.
+			if suggestion, ok := closestWord(name, names, 2); ok {
+				g.warn("unknown qualifier %q, sent as it is (did you mean %q?)", name+":", suggestion+":")
+			}
+			continue
+		}
+		if value == "" {
+			return fmt.Errorf("qualifier %q has no value", name+":")
+		}
+		if pattern != nil && !pattern.MatchString(value) {
+			return fmt.Errorf("invalid value %q for %q", value, name+":")
+		}
+	}
+	return nil
+}
+
+// --- Bitbucket ---
+
+// validateQuery implements queryValidator for Bitbucket. The query is
+// embedded in a BBQL string literal (name~"..."), which can't hold quotes or
+// backslashes, and BBQL has no qualifiers of its own.
+func (b *BitbucketSearcher) validateQuery(query string) error {
+	if strings.ContainsAny(query, `"\`) {
+		return fmt.Errorf("quotes and backslashes are not supported; Bitbucket matches the query as a substring of repository names")
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGitHubValidateQuery(t *testing.T) {
+	for _, tc := range []struct {
+		query   string
+		err     string // Substring of the error; empty for none
+		warning string // Substring of the one warning; empty for none
+	}{
+		{query: "cli language:go stars:>100 pushed:2024-01-01..*"},
+		{query: "has:funding-file mirror:false template:true sort:stars-desc owner:octo"},
+		{query: "see https://example.com note:later"}, // Far from any qualifier
+		{query: "langauge:go", warning: `unknown qualifier "langauge:", sent as it is (did you mean "language:"?)`},
+		{query: "stars:lots", err: `invalid value "lots" for "stars:"`},
+		{query: "language:", err: `qualifier "language:" has no value`},
+		{query: `"unterminated`, err: "unterminated quote"},
+	} {
+		g := NewGitHubSearcher("", nil)
+		err := g.validateQuery(tc.query)
+		switch {
+		case tc.err == "" && err != nil:
+			t.Errorf("%s: %v", tc.query, err)
+		case tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)):
+			t.Errorf("%s: error %v, want %q", tc.query, err, tc.err)
+		}
+		switch {
+		case tc.warning == "" && len(g.notes) > 0:
+			t.Errorf("%s: unexpected warnings %v", tc.query, g.notes)
+		case tc.warning != "" && (len(g.notes) != 1 || !strings.Contains(g.notes[0], tc.warning)):
+			t.Errorf("%s: warnings %v, want %q", tc.query, g.notes, tc.warning)
+		}
+	}
+}
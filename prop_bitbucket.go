@@ -30,6 +30,8 @@ type bitbucketRepository struct {
 	UpdatedOn   string          `json:"updated_on"`
 	IsPrivate   bool            `json:"is_private"`
 	Parent      *map[string]any `json:"parent"` // If not nil, it's a fork
+	MainBranch  *map[string]any `json:"mainbranch"`
+	Website     string          `json:"website"`
 	Links       bitbucketLinks  `json:"links"`
 }
 
@@ -53,6 +55,8 @@ func NewBitbucketSearcher(token string, client *http.Client) *BitbucketSearcher
 	base := NewBaseRepoSearcher(searcher, token, client)
 	base.Source = "Bitbucket"
 	base.BaseURL = "https://api.bitbucket.org/2.0"
+	// Bitbucket Cloud API uses Basic Auth with username and an app password.
+	base.Auth = BasicAuth{}
 	searcher.BaseRepoSearcher = base
 	return searcher
 }
@@ -81,19 +85,20 @@ func (b *BitbucketSearcher) buildSearchRequest(ctx context.Context, url string)
 	}
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("User-Agent", "go-repo-searcher/1.0")
-
-	// Bitbucket Cloud API uses Basic Auth with username and an app password.
-	// We expect the token to be in the "username:app_password" format.
-	if b.Token != "" {
-		parts := strings.SplitN(b.Token, ":", 2)
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid Bitbucket token format; expected 'username:app_password'")
-		}
-		req.SetBasicAuth(parts[0], parts[1])
-	}
 	return req, nil
 }
 
+// itemSchema implements schemaSource for Bitbucket. Only forks have a
+// parent.
+func (b *BitbucketSearcher) itemSchema() (item any, optional []string) {
+	return bitbucketRepository{}, []string{"parent", "mainbranch"}
+}
+
+// rawItemPaths implements rawItemSource for Bitbucket.
+func (b *BitbucketSearcher) rawItemPaths() (items, url string) {
+	return "$.values[*]", "$.links.html.href"
+}
+
 // parseSearchResponse implements the RepoSearcher interface for Bitbucket.
 func (b *BitbucketSearcher) parseSearchResponse(body io.Reader) (summaries []RepositorySummary, totalCount int, hasMore bool, err error) {
 	var resp bitbucketSearchResponse
@@ -102,8 +107,8 @@ func (b *BitbucketSearcher) parseSearchResponse(body io.Reader) (summaries []Rep
 	}
 
 	summaries = make([]RepositorySummary, len(resp.Values))
-	for i, repo := range resp.Values {
-		summaries[i] = b.mapRepoToSummary(repo)
+	for i := range resp.Values {
+		summaries[i] = b.mapRepoToSummary(&resp.Values[i])
 	}
 
 	totalCount = resp.Size
@@ -112,7 +117,7 @@ func (b *BitbucketSearcher) parseSearchResponse(body io.Reader) (summaries []Rep
 }
 
 // mapRepoToSummary converts a Bitbucket-specific repo to the generic summary.
-func (b *BitbucketSearcher) mapRepoToSummary(repo bitbucketRepository) RepositorySummary {
+func (b *BitbucketSearcher) mapRepoToSummary(repo *bitbucketRepository) RepositorySummary {
 	language := "Unknown"
 	if repo.Language != "" {
 		language = repo.Language
@@ -126,11 +131,14 @@ func (b *BitbucketSearcher) mapRepoToSummary(repo bitbucketRepository) Repositor
 		URL:             repo.Links.HTML.Href,
 		Stars:           -1, // Not available in this endpoint
 		Forks:           -1, // Not available in this endpoint
-		Language:        language,
+		Language:        intern(language),
 		CreatedAt:       repo.CreatedOn,
 		UpdatedAt:       repo.UpdatedOn,
 		IsPrivate:       repo.IsPrivate,
 		IsFork:          repo.Parent != nil,
+		ParentFullName:  mapString(repo.Parent, "full_name"),
+		DefaultBranch:   mapString(repo.MainBranch, "name"),
+		Homepage:        strings.TrimSpace(repo.Website),
 		IsArchived:      false,      // Not available in this endpoint
 		Topics:          []string{}, // Not available
 		License:         "Unknown",  // Not available
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// --- Codeberg Specific Data Structures ---
+
+// codebergRepository represents the raw JSON structure for a Codeberg repo.
+// Codeberg runs Forgejo, whose API is Gitea's.
+type codebergRepository struct {
+	ID              int64    `json:"id"`
+	Name            string   `json:"name"`
+	FullName        string   `json:"full_name"`
+	Description     string   `json:"description"`
+	Private         bool     `json:"private"`
+	Fork            bool     `json:"fork"`
+	HTMLURL         string   `json:"html_url"`
+	CreatedAt       string   `json:"created_at"`
+	UpdatedAt       string   `json:"updated_at"`
+	StarsCount      int      `json:"stars_count"`
+	ForksCount      int      `json:"forks_count"`
+	Language        string   `json:"language"`
+	Archived        bool     `json:"archived"`
+	OpenIssuesCount int      `json:"open_issues_count"`
+	Topics          []string `json:"topics"`
+	Licenses        []string `json:"licenses"` // Gitea 1.23+; Forgejo doesn't send it
+	Parent          *repoRef `json:"parent"`
+	DefaultBranch   string   `json:"default_branch"`
+	Website         string   `json:"website"`
+}
+
+// codebergSearchResponse wraps the results of /repos/search.
+type codebergSearchResponse struct {
+	OK   bool                 `json:"ok"`
+	Data []codebergRepository `json:"data"`
+}
+
+// codebergMaxPerPage is the most items Codeberg returns on a page.
+const codebergMaxPerPage = 50
+
+// codebergPageHeaders are the paging headers Forgejo sends.
+var codebergPageHeaders = pageHeaders{Total: "X-Total-Count"}
+
+// CodebergSearcher is the concrete implementation for searching Codeberg,
+// or any other Forgejo or Gitea server configured as an instance.
+type CodebergSearcher struct {
+	*BaseRepoSearcher
+	// UsesLicense is set when the results' licenses are filtered, shown or
+	// grouped by, so the missing Forgejo licenses matter.
+	UsesLicense bool
+}
+
+// NewCodebergSearcher creates a new searcher for Codeberg.
+func NewCodebergSearcher(token string, client *http.Client) *CodebergSearcher {
+	searcher := &CodebergSearcher{}
+	base := NewBaseRepoSearcher(searcher, token, client)
+	base.Source = "Codeberg"
+	base.BaseURL = "https://codeberg.org/api/v1"
+	base.Auth = HeaderAuth{Header: "Authorization", Prefix: "token "}
+	base.PerPage = codebergMaxPerPage
+	searcher.BaseRepoSearcher = base
+	return searcher
+}
+
+// buildSearchURL implements the RepoSearcher interface for Codeberg.
+func (c *CodebergSearcher) buildSearchURL(query string, page, perPage int) (string, error) {
+	u, err := url.Parse(c.BaseURL + "/repos/search")
+	if err != nil {
+		return "", fmt.Errorf("failed to parse base URL: %w", err)
+	}
+	q := u.Query()
+	q.Set("q", query)
+	// Match descriptions too, as the other services' searches do.
+	q.Set("includeDesc", "true")
+	q.Set("page", fmt.Sprintf("%d", page))
+	q.Set("limit", fmt.Sprintf("%d", perPage))
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// buildSearchRequest implements the RepoSearcher interface for Codeberg.
+func (c *CodebergSearcher) buildSearchRequest(ctx context.Context, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "go-repo-searcher/1.0")
+	return req, nil
+}
+
+// itemSchema implements schemaSource for Codeberg. Only forks have a
+// parent, and only Gitea reports licenses.
+func (c *CodebergSearcher) itemSchema() (item any, optional []string) {
+	return codebergRepository{}, []string{"parent", "licenses"}
+}
+
+// rawItemPaths implements rawItemSource for Codeberg.
+func (c *CodebergSearcher) rawItemPaths() (items, url string) {
+	return "$.data[*]", "$.html_url"
+}
+
+// maxPerPage implements pageSizeLimiter for Codeberg.
+func (c *CodebergSearcher) maxPerPage() int {
+	return codebergMaxPerPage
+}
+
+// parseSearchResponse implements the RepoSearcher interface for Codeberg.
+func (c *CodebergSearcher) parseSearchResponse(body io.Reader) (summaries []RepositorySummary, totalCount int, hasMore bool, err error) {
+	var resp codebergSearchResponse
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		return nil, 0, false, fmt.Errorf("failed to unmarshal Codeberg response: %w", err)
+	}
+	if !resp.OK {
+		return nil, 0, false, fmt.Errorf("codeberg search failed")
+	}
+
+	summaries = make([]RepositorySummary, len(resp.Data))
+	for i := range resp.Data {
+		summaries[i] = c.mapRepoToSummary(&resp.Data[i])
+	}
+
+	// The total is in the X-Total-Count header; see parsePageHeaders.
+	totalCount = -1 // -1 signifies unknown
+	hasMore = len(resp.Data) > 0
+	return summaries, totalCount, hasMore, nil
+}
+
+// parsePageHeaders implements headerPaginator for Codeberg.
+func (c *CodebergSearcher) parsePageHeaders(h http.Header, page, perPage, totalCount int, hasMore bool) (int, bool) {
+	return codebergPageHeaders.read(h, page, perPage, totalCount, hasMore)
+}
+
+// mapRepoToSummary converts a Codeberg-specific repo to the generic summary.
+func (c *CodebergSearcher) mapRepoToSummary(repo *codebergRepository) RepositorySummary {
+	language := "Unknown"
+	if repo.Language != "" {
+		language = repo.Language
+	}
+
+	license := "None"
+	if len(repo.Licenses) > 0 {
+		license = repo.Licenses[0]
+	}
+
+	return RepositorySummary{
+		Name:            repo.Name,
+		FullName:        repo.FullName,
+		Description:     strings.TrimSpace(repo.Description),
+		URL:             repo.HTMLURL,
+		Stars:           repo.StarsCount,
+		Forks:           repo.ForksCount,
+		Language:        intern(language),
+		CreatedAt:       repo.CreatedAt,
+		UpdatedAt:       repo.UpdatedAt,
+		IsPrivate:       repo.Private,
+		IsFork:          repo.Fork,
+		ParentFullName:  repo.Parent.name(),
+		DefaultBranch:   repo.DefaultBranch,
+		Homepage:        strings.TrimSpace(repo.Website),
+		IsArchived:      repo.Archived,
+		Topics:          internAll(repo.Topics),
+		License:         intern(license),
+		OpenIssuesCount: repo.OpenIssuesCount,
+	}
+}
@@ -31,6 +31,9 @@ type gitCodeRepository struct {
 	OpenIssuesCount int             `json:"open_issues_count"`
 	License         *gitCodeLicense `json:"license"`
 	Topics          []string        `json:"topics"`
+	Parent          *repoRef        `json:"parent"`
+	DefaultBranch   string          `json:"default_branch"`
+	Homepage        string          `json:"homepage"`
 }
 
 type gitCodeLicense struct {
@@ -50,6 +53,7 @@ func NewGitCodeSearcher(token string, client *http.Client) *GitCodeSearcher {
 	base := NewBaseRepoSearcher(searcher, token, client)
 	base.Source = "GitCode"
 	base.BaseURL = "https://api.gitcode.com/api/v5"
+	base.Auth = HeaderAuth{Header: "Authorization", Prefix: "Bearer "}
 	searcher.BaseRepoSearcher = base
 	return searcher
 }
@@ -79,10 +83,19 @@ func (g *GitCodeSearcher) buildSearchRequest(ctx context.Context, url string) (*
 	}
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("User-Agent", "go-repo-searcher/1.0")
-	req.Header.Set("Authorization", "Bearer "+g.Token)
 	return req, nil
 }
 
+// itemSchema implements schemaSource for GitCode. Only forks have a parent.
+func (g *GitCodeSearcher) itemSchema() (item any, optional []string) {
+	return gitCodeRepository{}, []string{"parent"}
+}
+
+// rawItemPaths implements rawItemSource for GitCode.
+func (g *GitCodeSearcher) rawItemPaths() (items, url string) {
+	return "$[*]", "$.html_url"
+}
+
 // parseSearchResponse implements the RepoSearcher interface for GitCode.
 func (g *GitCodeSearcher) parseSearchResponse(body io.Reader) (summaries []RepositorySummary, totalCount int, hasMore bool, err error) {
 	// GitCode's response is just an array of repositories.
@@ -92,19 +105,33 @@ func (g *GitCodeSearcher) parseSearchResponse(body io.Reader) (summaries []Repos
 	}
 
 	summaries = make([]RepositorySummary, len(repos))
-	for i, repo := range repos {
-		summaries[i] = g.mapRepoToSummary(repo)
+	for i := range repos {
+		summaries[i] = g.mapRepoToSummary(&repos[i])
 	}
 
-	// GitCode doesn't return total count in the body or headers.
-	// We also don't know if there's more. We assume `hasMore` if we got a full page.
+	// The body has no paging information; parsePageHeaders fills it in
+	// from the headers when they are sent.
 	totalCount = -1 // -1 signifies unknown
 	hasMore = len(repos) > 0
 	return summaries, totalCount, hasMore, nil
 }
 
+// gitCodePageHeaders are the header sets GitCode has been seen to send: the
+// GitLab style on some endpoints and the Gitee style on others.
+var gitCodePageHeaders = []pageHeaders{gitLabPageHeaders, giteePageHeaders}
+
+// parsePageHeaders implements headerPaginator for GitCode.
+func (g *GitCodeSearcher) parsePageHeaders(h http.Header, page, perPage, totalCount int, hasMore bool) (int, bool) {
+	for _, names := range gitCodePageHeaders {
+		if names.present(h) {
+			return names.read(h, page, perPage, totalCount, hasMore)
+		}
+	}
+	return totalCount, hasMore
+}
+
 // mapRepoToSummary converts a GitCode-specific repo to the generic summary.
-func (g *GitCodeSearcher) mapRepoToSummary(repo gitCodeRepository) RepositorySummary {
+func (g *GitCodeSearcher) mapRepoToSummary(repo *gitCodeRepository) RepositorySummary {
 	language := "Unknown"
 	if repo.Language != nil && *repo.Language != "" {
 		language = *repo.Language
@@ -122,14 +149,17 @@ func (g *GitCodeSearcher) mapRepoToSummary(repo gitCodeRepository) RepositorySum
 		URL:             repo.HTMLURL,
 		Stars:           repo.StargazersCount,
 		Forks:           repo.ForksCount,
-		Language:        language,
+		Language:        intern(language),
 		CreatedAt:       repo.CreatedAt,
 		UpdatedAt:       repo.UpdatedAt,
 		IsPrivate:       repo.Private,
 		IsFork:          repo.Fork,
+		ParentFullName:  repo.Parent.name(),
+		DefaultBranch:   repo.DefaultBranch,
+		Homepage:        strings.TrimSpace(repo.Homepage),
 		IsArchived:      repo.Archived,
-		Topics:          repo.Topics,
-		License:         license,
+		Topics:          internAll(repo.Topics),
+		License:         intern(license),
 		OpenIssuesCount: repo.OpenIssuesCount,
 	}
 }
@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 )
 
@@ -31,11 +33,19 @@ type giteeRepository struct {
 	OpenIssuesCount int      `json:"open_issues_count"`
 	License         *string  `json:"license"` // Gitee license is just a string
 	Topics          []string `json:"topics"`
+	Parent          *repoRef `json:"parent"`
+	DefaultBranch   string   `json:"default_branch"`
+	Homepage        string   `json:"homepage"`
 }
 
 // GiteeSearcher is the concrete implementation for searching Gitee.
 type GiteeSearcher struct {
 	*BaseRepoSearcher
+	// Org or Enterprise, if set, lists that organization's or enterprise's
+	// repositories instead of searching all of Gitee. The listings take no
+	// query, so results are matched against it afterwards (see filterListing).
+	Org        string
+	Enterprise string
 }
 
 // NewGiteeSearcher creates a new searcher for Gitee.
@@ -45,23 +55,33 @@ func NewGiteeSearcher(token string, client *http.Client) *GiteeSearcher {
 	base := NewBaseRepoSearcher(searcher, token, client)
 	base.Source = "Gitee"
 	base.BaseURL = "https://gitee.com/api/v5"
+	// Per docs, the `access_token` query param is the standard way to authenticate.
+	base.Auth = QueryParamAuth{Param: "access_token"}
 	searcher.BaseRepoSearcher = base
 	return searcher
 }
 
 // buildSearchURL implements the RepoSearcher interface for Gitee.
 func (g *GiteeSearcher) buildSearchURL(query string, page, perPage int) (string, error) {
-	u, err := url.Parse(g.BaseURL + "/search/repositories")
+	endpoint := g.BaseURL + "/search/repositories"
+	switch {
+	case g.Enterprise != "":
+		endpoint = g.BaseURL + "/enterprises/" + url.PathEscape(g.Enterprise) + "/repos"
+	case g.Org != "":
+		endpoint = g.BaseURL + "/orgs/" + url.PathEscape(g.Org) + "/repos"
+	}
+	u, err := url.Parse(endpoint)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse base URL: %w", err)
 	}
 	q := u.Query()
-	q.Set("q", query)
+	if g.Org != "" || g.Enterprise != "" {
+		q.Set("type", "all")
+	} else {
+		q.Set("q", query)
+	}
 	q.Set("page", fmt.Sprintf("%d", page))
 	q.Set("per_page", fmt.Sprintf("%d", perPage))
-	if g.Token != "" {
-		q.Set("access_token", g.Token)
-	}
 	u.RawQuery = q.Encode()
 	return u.String(), nil
 }
@@ -74,12 +94,19 @@ func (g *GiteeSearcher) buildSearchRequest(ctx context.Context, url string) (*ht
 	}
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("User-Agent", "go-repo-searcher/1.0")
-	// Token is already in the query param for Gitee, but adding as a header
-	// is also an option if preferred (and supported).
-	// Per docs, query param `access_token` is standard.
 	return req, nil
 }
 
+// itemSchema implements schemaSource for Gitee. Only forks have a parent.
+func (g *GiteeSearcher) itemSchema() (item any, optional []string) {
+	return giteeRepository{}, []string{"parent"}
+}
+
+// rawItemPaths implements rawItemSource for Gitee.
+func (g *GiteeSearcher) rawItemPaths() (items, url string) {
+	return "$[*]", "$.html_url"
+}
+
 // parseSearchResponse implements the RepoSearcher interface for Gitee.
 func (g *GiteeSearcher) parseSearchResponse(body io.Reader) (summaries []RepositorySummary, totalCount int, hasMore bool, err error) {
 	var repos []giteeRepository
@@ -88,23 +115,41 @@ func (g *GiteeSearcher) parseSearchResponse(body io.Reader) (summaries []Reposit
 	}
 
 	summaries = make([]RepositorySummary, len(repos))
-	for i, repo := range repos {
-		summaries[i] = g.mapRepoToSummary(repo)
+	for i := range repos {
+		summaries[i] = g.mapRepoToSummary(&repos[i])
 	}
 
-	// Gitee also doesn't return total count in the response body.
-	// We also don't know if there's more. We assume `hasMore` if we got a full page.
-	// Note: Gitee *does* provide a `Total-Count` header. A more robust
-	// implementation would read this from the `http.Response` object,
-	// but our `parseSearchResponse` only gets an `io.Reader`.
-	// This is a tradeoff for this simple template method.
+	// Gitee doesn't return the total in the response body, but in the
+	// total_count header; see parsePageHeaders.
 	totalCount = -1 // -1 signifies unknown
 	hasMore = len(repos) > 0
 	return summaries, totalCount, hasMore, nil
 }
 
+// parsePageHeaders implements headerPaginator for Gitee.
+func (g *GiteeSearcher) parsePageHeaders(h http.Header, page, perPage, totalCount int, hasMore bool) (int, bool) {
+	return giteePageHeaders.read(h, page, perPage, totalCount, hasMore)
+}
+
+// filterListing keeps the repositories of an organization or enterprise
+// listing that match every word of query in their name, description or
+// topics, as a search would have.
+func (g *GiteeSearcher) filterListing(result *SearchResult, query string) {
+	terms := strings.Fields(strings.ToLower(query))
+	kept := result.Items[:0]
+	for _, item := range result.Items {
+		text := strings.ToLower(item.FullName + " " + item.Description + " " + strings.Join(item.Topics, " "))
+		if !slices.ContainsFunc(terms, func(t string) bool { return !strings.Contains(text, t) }) {
+			kept = append(kept, item)
+		}
+	}
+	log.Printf("%d of %d listed Gitee repositories match %q.", len(kept), len(result.Items), query)
+	result.Items = kept
+	result.TotalCount = -1 // The listing's total counts non-matches too
+}
+
 // mapRepoToSummary converts a Gitee-specific repo to the generic summary.
-func (g *GiteeSearcher) mapRepoToSummary(repo giteeRepository) RepositorySummary {
+func (g *GiteeSearcher) mapRepoToSummary(repo *giteeRepository) RepositorySummary {
 	language := "Unknown"
 	if repo.Language != nil && *repo.Language != "" {
 		language = *repo.Language
@@ -122,14 +167,17 @@ func (g *GiteeSearcher) mapRepoToSummary(repo giteeRepository) RepositorySummary
 		URL:             repo.HTMLURL,
 		Stars:           repo.StargazersCount,
 		Forks:           repo.ForksCount,
-		Language:        language,
+		Language:        intern(language),
 		CreatedAt:       repo.CreatedAt,
 		UpdatedAt:       repo.UpdatedAt,
 		IsPrivate:       repo.Private,
 		IsFork:          repo.Fork,
+		ParentFullName:  repo.Parent.name(),
+		DefaultBranch:   repo.DefaultBranch,
+		Homepage:        strings.TrimSpace(repo.Homepage),
 		IsArchived:      repo.Archived,
-		Topics:          repo.Topics,
-		License:         license,
+		Topics:          internAll(repo.Topics),
+		License:         intern(license),
 		OpenIssuesCount: repo.OpenIssuesCount,
 	}
 }
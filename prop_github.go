@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 )
 
 // --- GitHub Specific Data Structures ---
@@ -37,6 +38,11 @@ type gitHubRepository struct {
 	OpenIssuesCount int            `json:"open_issues_count"`
 	License         *gitHubLicense `json:"license"`
 	Topics          []string       `json:"topics"`
+	DefaultBranch   string         `json:"default_branch"`
+	Homepage        string         `json:"homepage"`
+	HasPages        bool           `json:"has_pages"`
+	// Parent is only present in single-repository responses.
+	Parent *repoRef `json:"parent"`
 }
 
 type gitHubLicense struct {
@@ -46,6 +52,8 @@ type gitHubLicense struct {
 // GitHubSearcher is the concrete implementation for searching GitHub.
 type GitHubSearcher struct {
 	*BaseRepoSearcher
+	// Qualifiers are added to every query, e.g. "org:golang" (see gitHubQualifierFlags).
+	Qualifiers []string
 }
 
 // NewGitHubSearcher creates a new searcher for GitHub.
@@ -54,6 +62,7 @@ func NewGitHubSearcher(token string, client *http.Client) *GitHubSearcher {
 	base := NewBaseRepoSearcher(searcher, token, client)
 	base.Source = "GitHub"
 	base.BaseURL = "https://api.github.com"
+	base.Auth = HeaderAuth{Header: "Authorization", Prefix: "Bearer "}
 	searcher.BaseRepoSearcher = base
 	return searcher
 }
@@ -64,6 +73,10 @@ func (g *GitHubSearcher) buildSearchURL(query string, page, perPage int) (string
 	if err != nil {
 		return "", fmt.Errorf("failed to parse base URL: %w", err)
 	}
+	query = withQualifiers(query, g.Qualifiers)
+	if !g.UpdatedSince.IsZero() {
+		query += " pushed:>" + g.UpdatedSince.UTC().Format(time.RFC3339)
+	}
 	q := u.Query()
 	q.Set("q", query)
 	q.Set("page", fmt.Sprintf("%d", page))
@@ -80,12 +93,20 @@ func (g *GitHubSearcher) buildSearchRequest(ctx context.Context, url string) (*h
 	}
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 	req.Header.Set("User-Agent", "go-repo-searcher/1.0")
-	if g.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+g.Token)
-	}
 	return req, nil
 }
 
+// itemSchema implements schemaSource for GitHub. The parent is only sent
+// for single repositories.
+func (g *GitHubSearcher) itemSchema() (item any, optional []string) {
+	return gitHubRepository{}, []string{"parent"}
+}
+
+// rawItemPaths implements rawItemSource for GitHub.
+func (g *GitHubSearcher) rawItemPaths() (items, url string) {
+	return "$.items[*]", "$.html_url"
+}
+
 // parseSearchResponse implements the RepoSearcher interface for GitHub.
 func (g *GitHubSearcher) parseSearchResponse(body io.Reader) (summaries []RepositorySummary, totalCount int, hasMore bool, err error) {
 	var resp gitHubSearchResponse
@@ -94,8 +115,8 @@ func (g *GitHubSearcher) parseSearchResponse(body io.Reader) (summaries []Reposi
 	}
 
 	summaries = make([]RepositorySummary, len(resp.Items))
-	for i, repo := range resp.Items {
-		summaries[i] = g.mapRepoToSummary(repo)
+	for i := range resp.Items {
+		summaries[i] = g.mapRepoToSummary(&resp.Items[i])
 	}
 
 	// GitHub provides the total count
@@ -111,7 +132,7 @@ func (g *GitHubSearcher) parseSearchResponse(body io.Reader) (summaries []Reposi
 }
 
 // mapRepoToSummary converts a GitHub-specific repo to the generic summary.
-func (g *GitHubSearcher) mapRepoToSummary(repo gitHubRepository) RepositorySummary {
+func (g *GitHubSearcher) mapRepoToSummary(repo *gitHubRepository) RepositorySummary {
 	language := "Unknown"
 	if repo.Language != nil && *repo.Language != "" {
 		language = *repo.Language
@@ -129,14 +150,18 @@ func (g *GitHubSearcher) mapRepoToSummary(repo gitHubRepository) RepositorySumma
 		URL:             repo.HTMLURL,
 		Stars:           repo.StargazersCount,
 		Forks:           repo.ForksCount,
-		Language:        language,
+		Language:        intern(language),
 		CreatedAt:       repo.CreatedAt,
 		UpdatedAt:       repo.UpdatedAt,
 		IsPrivate:       repo.Private,
 		IsFork:          repo.Fork,
+		ParentFullName:  repo.Parent.name(),
+		DefaultBranch:   repo.DefaultBranch,
+		Homepage:        strings.TrimSpace(repo.Homepage),
+		HasPages:        repo.HasPages,
 		IsArchived:      repo.Archived,
-		Topics:          repo.Topics,
-		License:         license,
+		Topics:          internAll(repo.Topics),
+		License:         intern(license),
 		OpenIssuesCount: repo.OpenIssuesCount,
 	}
 }
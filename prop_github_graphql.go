@@ -0,0 +1,416 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --- GitHub GraphQL Searcher ---
+//
+// GitHub's GraphQL API returns languages, topics and open issue counts with
+// the search results, but every query is charged rate-limit points by its
+// size (up to 5000 an hour), and a query that asks for too many nodes is
+// refused outright. The searcher estimates each query's cost before sending
+// it and uses smaller pages, over more requests, when a page would cost more
+// than MaxQueryCost. It reads the points left from every response and waits
+// for the reset, or stops with a warning, rather than run into GitHub's
+// secondary rate limits.
+
+// gitHubSearchQuery asks for one page of repositories matching $q, and for
+// the rate limit cost of asking.
+const gitHubSearchQuery = `query($q: String!, $first: Int!, $after: String, $topics: Int!, $languages: Int!) {
+  rateLimit { cost remaining resetAt }
+  search(query: $q, type: REPOSITORY, first: $first, after: $after) {
+    repositoryCount
+    pageInfo { endCursor hasNextPage }
+    nodes {
+      ... on Repository {
+        name nameWithOwner description url homepageUrl
+        isPrivate isFork isArchived createdAt pushedAt
+        stargazerCount forkCount
+        primaryLanguage { name }
+        licenseInfo { name }
+        defaultBranchRef { name }
+        parent { nameWithOwner }
+        issues(states: OPEN) { totalCount }
+        repositoryTopics(first: $topics) { nodes { topic { name } } }
+        languages(first: $languages, orderBy: {field: SIZE, direction: DESC}) { totalSize edges { size node { name } } }
+      }
+    }
+  }
+}`
+
+// Sizes of the nested connections in gitHubSearchQuery, and how many there
+// are, for estimating its cost.
+const (
+	gitHubGraphQLTopics      = 20
+	gitHubGraphQLLanguages   = 10
+	gitHubGraphQLConnections = 3 // issues, repositoryTopics, languages
+)
+
+// gitHubGraphQLNodeLimit is the most nodes GitHub lets one query ask for.
+const gitHubGraphQLNodeLimit = 500000
+
+// defaultMaxQueryCost is the most rate-limit points one page may cost
+// before it is split into smaller pages.
+const defaultMaxQueryCost = 2
+
+// gitHubGraphQLMaxWait is the longest the searcher waits for the rate limit
+// to reset; a later reset ends the search with partial results.
+const gitHubGraphQLMaxWait = 5 * time.Minute
+
+// gitHubGraphQLResponse is the raw JSON structure for gitHubSearchQuery.
+type gitHubGraphQLResponse struct {
+	Data struct {
+		RateLimit *gitHubRateLimit `json:"rateLimit"`
+		Search    struct {
+			RepositoryCount int `json:"repositoryCount"`
+			PageInfo        struct {
+				EndCursor   string `json:"endCursor"`
+				HasNextPage bool   `json:"hasNextPage"`
+			} `json:"pageInfo"`
+			Nodes []gitHubGraphQLRepository `json:"nodes"`
+		} `json:"search"`
+	} `json:"data"`
+	Errors []struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// gitHubRateLimit is GraphQL's report of what a query cost.
+type gitHubRateLimit struct {
+	Cost      int       `json:"cost"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"resetAt"`
+}
+
+type gitHubGraphQLRepository struct {
+	Name             string                 `json:"name"`
+	NameWithOwner    string                 `json:"nameWithOwner"`
+	Description      string                 `json:"description"`
+	URL              string                 `json:"url"`
+	HomepageURL      string                 `json:"homepageUrl"`
+	IsPrivate        bool                   `json:"isPrivate"`
+	IsFork           bool                   `json:"isFork"`
+	IsArchived       bool                   `json:"isArchived"`
+	CreatedAt        string                 `json:"createdAt"`
+	PushedAt         string                 `json:"pushedAt"`
+	StargazerCount   int                    `json:"stargazerCount"`
+	ForkCount        int                    `json:"forkCount"`
+	PrimaryLanguage  *struct{ Name string } `json:"primaryLanguage"`
+	LicenseInfo      *struct{ Name string } `json:"licenseInfo"`
+	DefaultBranchRef *struct{ Name string } `json:"defaultBranchRef"`
+	Parent           *struct {
+		NameWithOwner string `json:"nameWithOwner"`
+	} `json:"parent"`
+	Issues struct {
+		TotalCount int `json:"totalCount"`
+	} `json:"issues"`
+	RepositoryTopics struct {
+		Nodes []struct {
+			Topic struct{ Name string } `json:"topic"`
+		} `json:"nodes"`
+	} `json:"repositoryTopics"`
+	Languages struct {
+		TotalSize int `json:"totalSize"`
+		Edges     []struct {
+			Size int                   `json:"size"`
+			Node struct{ Name string } `json:"node"`
+		} `json:"edges"`
+	} `json:"languages"`
+}
+
+// GitHubGraphQLSearcher searches GitHub through its GraphQL API. It embeds
+// the REST searcher for qualifiers, repository details and enrichment.
+type GitHubGraphQLSearcher struct {
+	*GitHubSearcher
+	// GraphQLURL is the GraphQL endpoint.
+	GraphQLURL string
+	// MaxQueryCost is the most rate-limit points one page may cost; more
+	// expensive pages are split into smaller ones.
+	MaxQueryCost int
+
+	mu        sync.Mutex
+	rateLimit *gitHubRateLimit // From the latest response
+	spent     int              // Points spent by this searcher
+	waitUntil time.Time        // Don't send before this; the rate limit is exhausted
+	exhausted bool             // Stopped early because the rate limit ran out
+}
+
+// NewGitHubGraphQLSearcher creates a new GraphQL searcher for GitHub.
+func NewGitHubGraphQLSearcher(token string, client *http.Client) *GitHubGraphQLSearcher {
+	searcher := &GitHubGraphQLSearcher{
+		GitHubSearcher: NewGitHubSearcher(token, client),
+		GraphQLURL:     "https://api.github.com/graphql",
+		MaxQueryCost:   defaultMaxQueryCost,
+	}
+	// Search through the GraphQL primitives below instead of the REST ones.
+	searcher.implementation = searcher
+	searcher.Use(searcher.waitForReset())
+	return searcher
+}
+
+// gitHubQueryCost estimates what gitHubSearchQuery costs with first results
+// per page, following GitHub's documented formula: one request for the
+// search, plus one per result for each nested connection, in hundreds,
+// rounded, and at least one point. It also returns the node count.
+func gitHubQueryCost(first int) (cost, nodes int) {
+	requests := 1 + first*gitHubGraphQLConnections
+	cost = max(1, int(math.Round(float64(requests)/100)))
+	nodes = first + first*(gitHubGraphQLTopics+gitHubGraphQLLanguages) + first // The issues count is one node each
+	return cost, nodes
+}
+
+// affordablePageSize returns the largest page size, up to perPage, whose
+// query stays within MaxQueryCost and the node limit.
+func (g *GitHubGraphQLSearcher) affordablePageSize(perPage int) int {
+	first := min(perPage, 100) // GitHub's page size limit
+	for first > 1 {
+		cost, nodes := gitHubQueryCost(first)
+		if (g.MaxQueryCost <= 0 || cost <= g.MaxQueryCost) && nodes <= gitHubGraphQLNodeLimit {
+			break
+		}
+		first /= 2
+	}
+	return max(first, 1)
+}
+
+// Search runs the search, splitting pages that would cost too much into
+// smaller ones so that maxPages still covers the same number of results.
+func (g *GitHubGraphQLSearcher) Search(ctx context.Context, query string, maxPages int) (*SearchResult, error) {
+	g.mu.Lock()
+	g.spent, g.exhausted = 0, false
+	g.mu.Unlock()
+
+	perPage := g.PerPage
+	if first := g.affordablePageSize(perPage); first < perPage {
+		log.Printf("Pages of %d would exceed GitHub's GraphQL limits (or %d points per query); fetching pages of %d instead.", perPage, g.MaxQueryCost, first)
+		maxPages = (maxPages*perPage + first - 1) / first
+		g.PerPage = first
+		defer func() { g.PerPage = perPage }()
+	}
+
+	result, err := g.BaseRepoSearcher.Search(ctx, query, maxPages)
+	if err != nil {
+		return nil, err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.exhausted && g.rateLimit != nil {
+		result.Warnings = addWarning(result.Warnings, fmt.Sprintf("%s: the GraphQL rate limit ran out (%d points left, resets at %s); results are partial",
+			g.Source, g.rateLimit.Remaining, g.rateLimit.ResetAt.Format(time.RFC3339)))
+	}
+	if g.rateLimit != nil {
+		log.Printf("GitHub GraphQL search cost %d points; %d left until %s.", g.spent, g.rateLimit.Remaining, g.rateLimit.ResetAt.Format(time.RFC3339))
+	}
+	return result, nil
+}
+
+// waitForReset returns middleware that holds requests back until the rate
+// limit resets, when the previous response said the points had run out.
+func (g *GitHubGraphQLSearcher) waitForReset() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			g.mu.Lock()
+			wait := time.Until(g.waitUntil)
+			g.mu.Unlock()
+			if wait > 0 {
+				log.Printf("GitHub GraphQL rate limit exhausted; waiting %v for it to reset.", wait.Round(time.Second))
+				if err := sleepCtx(req.Context(), wait); err != nil {
+					return nil, err
+				}
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// buildSearchURL implements the RepoSearcher interface. Pages after the
+// first can only be reached by cursor; see buildCursorURL.
+func (g *GitHubGraphQLSearcher) buildSearchURL(query string, page, perPage int) (string, error) {
+	if page != 1 {
+		return "", fmt.Errorf("GitHub GraphQL pages are addressed by cursor, not page %d", page)
+	}
+	return g.buildCursorURL(query, "", perPage)
+}
+
+// buildCursorURL implements cursorPaginator. Like the GitLab GraphQL
+// searcher, the URL carries the query variables, and buildSearchRequest
+// moves them into the POST body.
+func (g *GitHubGraphQLSearcher) buildCursorURL(query, cursor string, perPage int) (string, error) {
+	u, err := url.Parse(g.GraphQLURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse GraphQL URL: %w", err)
+	}
+	query = withQualifiers(query, g.Qualifiers)
+	if !g.UpdatedSince.IsZero() {
+		query += " pushed:>" + g.UpdatedSince.UTC().Format(time.RFC3339)
+	}
+	q := u.Query()
+	q.Set("q", query)
+	q.Set("first", strconv.Itoa(min(perPage, 100)))
+	if cursor != "" {
+		q.Set("after", cursor)
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// buildSearchRequest implements the RepoSearcher interface: it POSTs
+// gitHubSearchQuery with the variables taken from the URL.
+func (g *GitHubGraphQLSearcher) buildSearchRequest(ctx context.Context, rawURL string) (*http.Request, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+	q := u.Query()
+	first, err := strconv.Atoi(q.Get("first"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid page size %q: %w", q.Get("first"), err)
+	}
+	variables := map[string]any{
+		"q": q.Get("q"), "first": first,
+		"topics": gitHubGraphQLTopics, "languages": gitHubGraphQLLanguages,
+	}
+	if after := q.Get("after"); after != "" {
+		variables["after"] = after
+	}
+	payload, err := json.Marshal(map[string]any{"query": gitHubSearchQuery, "variables": variables})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal GraphQL query: %w", err)
+	}
+
+	u.RawQuery = ""
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "go-repo-searcher/1.0")
+	return req, nil
+}
+
+// itemSchema implements schemaSource. GraphQL responses hold exactly the
+// fields the query asks for, and a renamed field fails the query, so there
+// is nothing to check.
+func (g *GitHubGraphQLSearcher) itemSchema() (item any, optional []string) {
+	return nil, nil
+}
+
+// rawItemPaths implements rawItemSource.
+func (g *GitHubGraphQLSearcher) rawItemPaths() (items, url string) {
+	return "$.data.search.nodes[*]", "$.url"
+}
+
+// parseSearchResponse implements the RepoSearcher interface.
+func (g *GitHubGraphQLSearcher) parseSearchResponse(body io.Reader) (summaries []RepositorySummary, totalCount int, hasMore bool, err error) {
+	summaries, totalCount, next, err := g.parseCursorResponse(body)
+	return summaries, totalCount, next != "", err
+}
+
+// parseCursorResponse implements cursorPaginator. It also records the
+// query's cost, and ends the search early if the rate limit can't pay for
+// another page before gitHubGraphQLMaxWait.
+func (g *GitHubGraphQLSearcher) parseCursorResponse(body io.Reader) (summaries []RepositorySummary, totalCount int, next string, err error) {
+	var resp gitHubGraphQLResponse
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		return nil, 0, "", fmt.Errorf("failed to unmarshal GitHub GraphQL response: %w", err)
+	}
+	// GraphQL reports errors with a 200 status.
+	if len(resp.Errors) > 0 {
+		messages := make([]string, len(resp.Errors))
+		for i, e := range resp.Errors {
+			messages[i] = e.Message
+			if e.Type == "MAX_NODE_LIMIT_EXCEEDED" {
+				messages[i] += " (lower the provider's page_size in the config file)"
+			}
+		}
+		return nil, 0, "", fmt.Errorf("GitHub GraphQL error: %s", strings.Join(messages, "; "))
+	}
+
+	search := resp.Data.Search
+	summaries = make([]RepositorySummary, 0, len(search.Nodes))
+	for i := range search.Nodes {
+		if search.Nodes[i].NameWithOwner == "" {
+			continue // Not a repository
+		}
+		summaries = append(summaries, g.mapGraphQLRepository(&search.Nodes[i]))
+	}
+	if search.PageInfo.HasNextPage {
+		next = search.PageInfo.EndCursor
+	}
+
+	if rl := resp.Data.RateLimit; rl != nil {
+		g.mu.Lock()
+		g.rateLimit = rl
+		g.spent += rl.Cost
+		nextCost, _ := gitHubQueryCost(g.PerPage)
+		if next != "" && rl.Remaining < nextCost {
+			if time.Until(rl.ResetAt) > gitHubGraphQLMaxWait {
+				g.exhausted = true
+				next = ""
+			} else {
+				g.waitUntil = rl.ResetAt
+			}
+		}
+		g.mu.Unlock()
+	}
+	return summaries, search.RepositoryCount, next, nil
+}
+
+// mapGraphQLRepository converts a GraphQL repository to the generic
+// summary. Language shares are percentages of the repository's code.
+func (g *GitHubGraphQLSearcher) mapGraphQLRepository(r *gitHubGraphQLRepository) RepositorySummary {
+	summary := RepositorySummary{
+		Name:            r.Name,
+		FullName:        r.NameWithOwner,
+		Description:     strings.TrimSpace(r.Description),
+		URL:             r.URL,
+		Stars:           r.StargazerCount,
+		Forks:           r.ForkCount,
+		Language:        "Unknown",
+		CreatedAt:       r.CreatedAt,
+		UpdatedAt:       r.PushedAt,
+		IsPrivate:       r.IsPrivate,
+		IsFork:          r.IsFork,
+		Homepage:        strings.TrimSpace(r.HomepageURL),
+		IsArchived:      r.IsArchived,
+		License:         "None",
+		OpenIssuesCount: r.Issues.TotalCount,
+	}
+	if r.PrimaryLanguage != nil && r.PrimaryLanguage.Name != "" {
+		summary.Language = intern(r.PrimaryLanguage.Name)
+	}
+	if r.LicenseInfo != nil && r.LicenseInfo.Name != "" {
+		summary.License = intern(r.LicenseInfo.Name)
+	}
+	if r.DefaultBranchRef != nil {
+		summary.DefaultBranch = r.DefaultBranchRef.Name
+	}
+	if r.Parent != nil {
+		summary.ParentFullName = r.Parent.NameWithOwner
+	}
+	for _, t := range r.RepositoryTopics.Nodes {
+		summary.Topics = append(summary.Topics, intern(t.Topic.Name))
+	}
+	if r.Languages.TotalSize > 0 {
+		summary.Languages = make(map[string]float64, len(r.Languages.Edges))
+		for _, e := range r.Languages.Edges {
+			summary.Languages[intern(e.Node.Name)] = 100 * float64(e.Size) / float64(r.Languages.TotalSize)
+		}
+	}
+	return summary
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// --- GitHub Date-Range Query Planner ---
+//
+// GitHub's search API never returns more than 1000 results for a query, no
+// matter how many pages are requested. To crawl a popular keyword
+// exhaustively, the query is split into `created:` date ranges, each small
+// enough to stay under the cap, and the slices are merged afterwards.
+
+// gitHubResultCap is the maximum number of results GitHub serves per query.
+const gitHubResultCap = 1000
+
+// gitHubEpoch is the earliest creation date worth searching from.
+var gitHubEpoch = time.Date(2008, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// dateSlice is one `created:` range and the number of results it holds.
+type dateSlice struct {
+	From, To time.Time // Inclusive, day granularity
+	Count    int
+}
+
+// qualifier renders the slice as a GitHub search qualifier.
+func (d dateSlice) qualifier() string {
+	return fmt.Sprintf("created:%s..%s", d.From.Format("2006-01-02"), d.To.Format("2006-01-02"))
+}
+
+// SearchSliced searches like Search, but when the query matches more than
+// one search can return it partitions the query by creation date until
+// every slice fits, searches each slice, and merges the results, dropping
+// duplicates. A search returns at most maxPages pages and never more than
+// GitHub's 1000-result cap, so slices are split until they fit both; only a
+// single day over the limit is truncated, with a warning.
+func (g *GitHubSearcher) SearchSliced(ctx context.Context, query string, maxPages int) (*SearchResult, error) {
+	if strings.Contains(query, "created:") {
+		return nil, fmt.Errorf("query already has a created: qualifier; it cannot be sliced by date")
+	}
+
+	total, err := g.Estimate(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate result count: %w", err)
+	}
+	limit := min(gitHubResultCap, maxPages*g.PerPage)
+	if total <= limit {
+		return g.Search(ctx, query, maxPages)
+	}
+
+	log.Printf("Query matches %d repositories, more than the %d one search can return. Slicing by creation date...", total, limit)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	slices, err := g.planSlices(ctx, query, dateSlice{From: gitHubEpoch, To: today, Count: total}, limit)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Planned %d date slices.", len(slices))
+
+	merged := &SearchResult{Source: g.Source, Query: query, TotalCount: total}
+	seen := make(map[string]bool)
+	var spool *resultSpool
+	if g.SpillAfter > 0 {
+		spool = newResultSpool(g.SpillAfter) // Deduplicates on its own
+		defer spool.Close()
+	}
+	for i, slice := range slices {
+		if slice.Count == 0 {
+			continue
+		}
+		if slice.Count > limit {
+			merged.Warnings = addWarning(merged.Warnings, fmt.Sprintf("%s: date slice %s has %d results; only the first %d were retrieved",
+				g.Source, slice.qualifier(), slice.Count, limit))
+		}
+		pages := (min(slice.Count, limit) + g.PerPage - 1) / g.PerPage
+		log.Printf("Slice %d/%d (%s, %d results)", i+1, len(slices), slice.qualifier(), slice.Count)
+		result, err := g.Search(ctx, query+" "+slice.qualifier(), pages)
+		if err != nil && g.Strict {
+			return nil, fmt.Errorf("slice %s: %w", slice.qualifier(), err)
+		}
+		if err != nil {
+			log.Printf("Warning: slice %s failed: %v. Continuing with the remaining slices.", slice.qualifier(), err)
+			merged.Warnings = addWarning(merged.Warnings, fmt.Sprintf("%s: date slice %s failed (%v); results are partial", g.Source, slice.qualifier(), err))
+			continue
+		}
+		for _, w := range result.Warnings {
+			// A truncated slice was warned about above; the page count the
+			// slice was searched with is not the user's -pages.
+			if slice.Count > limit && strings.HasPrefix(w, g.Source+": stopped at the -pages limit") {
+				continue
+			}
+			merged.Warnings = addWarning(merged.Warnings, w)
+		}
+		if spool != nil {
+			if err := spool.Add(result.Items); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		for _, item := range result.Items {
+			if seen[item.FullName] {
+				continue
+			}
+			seen[item.FullName] = true
+			merged.Items = append(merged.Items, item)
+		}
+	}
+	if spool != nil {
+		if merged.Items, err = spool.Items(); err != nil {
+			return nil, fmt.Errorf("failed to read spooled results: %w", err)
+		}
+	}
+	return merged, nil
+}
+
+// planSlices splits s in half until every part holds at most limit
+// results. A single day that is still over the limit is kept as is (and
+// truncated), since creation dates can't be split further.
+func (g *GitHubSearcher) planSlices(ctx context.Context, query string, s dateSlice, limit int) ([]dateSlice, error) {
+	if s.Count <= limit || !s.To.After(s.From) {
+		if s.Count > limit {
+			log.Printf("Warning: %s alone has %d results; only the first %d can be retrieved.", s.qualifier(), s.Count, limit)
+		}
+		return []dateSlice{s}, nil
+	}
+
+	days := int(s.To.Sub(s.From).Hours() / 24)
+	mid := s.From.AddDate(0, 0, days/2)
+	var out []dateSlice
+	for _, half := range []dateSlice{{From: s.From, To: mid}, {From: mid.AddDate(0, 0, 1), To: s.To}} {
+		count, err := g.Estimate(ctx, query+" "+half.qualifier())
+		if err != nil {
+			return nil, fmt.Errorf("failed to count %s: %w", half.qualifier(), err)
+		}
+		half.Count = count
+		parts, err := g.planSlices(ctx, query, half, limit)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, parts...)
+	}
+	return out, nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// createdRange matches the created: qualifier of a sliced query.
+var createdRange = regexp.MustCompile(`created:(\d{4}-\d\d-\d\d)\.\.(\d{4}-\d\d-\d\d)`)
+
+// newSlicingGitHub serves a GitHub search over repositories created on the
+// given dates, honouring created: ranges, paging and the 1000-result cap.
+func newSlicingGitHub(t *testing.T, created []time.Time) *GitHubSearcher {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		from, to := time.Time{}, time.Now()
+		if m := createdRange.FindStringSubmatch(q.Get("q")); m != nil {
+			from, _ = time.Parse("2006-01-02", m[1])
+			to, _ = time.Parse("2006-01-02", m[2])
+			to = to.AddDate(0, 0, 1) // Ranges include their last day
+		}
+		var matches []gitHubRepository
+		for i, c := range created {
+			if !c.Before(from) && c.Before(to) {
+				matches = append(matches, gitHubRepository{FullName: fmt.Sprintf("o/r%d", i), CreatedAt: c.Format(time.RFC3339)})
+			}
+		}
+		page, _ := strconv.Atoi(q.Get("page"))
+		perPage, _ := strconv.Atoi(q.Get("per_page"))
+		start := min((max(page, 1)-1)*perPage, len(matches), gitHubResultCap)
+		end := min(start+perPage, len(matches), gitHubResultCap)
+		json.NewEncoder(w).Encode(gitHubSearchResponse{TotalCount: len(matches), Items: matches[start:end]})
+	}))
+	t.Cleanup(srv.Close)
+	s := NewGitHubSearcher("", srv.Client())
+	s.BaseURL = srv.URL
+	s.PageDelay = 0
+	s.RetryDelay = time.Millisecond
+	return s
+}
+
+func TestSearchSlicedFitsPageBudget(t *testing.T) {
+	var created []time.Time
+	day := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := range 300 {
+		created = append(created, day.AddDate(0, 0, i/3))
+	}
+	s := newSlicingGitHub(t, created)
+
+	// One page of 50 per search: the slices must split until each fits.
+	result, err := s.SearchSliced(context.Background(), "go", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Items) != len(created) {
+		t.Errorf("retrieved %d repositories, want all %d", len(result.Items), len(created))
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", result.Warnings)
+	}
+}
+
+func TestSearchSlicedWarnsAboutTruncatedDays(t *testing.T) {
+	var created []time.Time
+	busy := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	for range 120 {
+		created = append(created, busy)
+	}
+	for i := range 30 {
+		created = append(created, busy.AddDate(0, 1, i))
+	}
+	s := newSlicingGitHub(t, created)
+
+	result, err := s.SearchSliced(context.Background(), "go", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 50 + 30; len(result.Items) != want {
+		t.Errorf("retrieved %d repositories, want %d", len(result.Items), want)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "created:2021-06-01..2021-06-01 has 120 results") {
+		t.Errorf("warnings = %v, want one about the truncated day", result.Warnings)
+	}
+}
@@ -1,13 +1,18 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/url"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // --- GitLab Specific Data Structures ---
@@ -29,15 +34,29 @@ type gitLabRepository struct {
 	Topics            []string        `json:"topics"`
 	License           *gitLabLicense  `json:"license"`
 	ForkedFromProject *map[string]any `json:"forked_from_project"` // Presence indicates a fork
+	DefaultBranch     string          `json:"default_branch"`
 }
 
 type gitLabLicense struct {
 	Name string `json:"name"`
 }
 
+// gitLabBlob is one hit of a blobs-scoped search: a file in a project.
+type gitLabBlob struct {
+	Path      string `json:"path"`
+	Ref       string `json:"ref"`
+	ProjectID int64  `json:"project_id"`
+}
+
 // GitLabSearcher is the concrete implementation for searching GitLab.
 type GitLabSearcher struct {
 	*BaseRepoSearcher
+	// Group, if set, limits the search to this group (path or ID) and its subgroups.
+	Group string
+	// Scope is the search API scope, one of gitLabScopes; empty means projects.
+	Scope string
+	// SearchNamespaces also matches the query against namespace paths.
+	SearchNamespaces bool
 }
 
 // NewGitLabSearcher creates a new searcher for GitLab.
@@ -46,18 +65,38 @@ func NewGitLabSearcher(token string, client *http.Client) *GitLabSearcher {
 	base := NewBaseRepoSearcher(searcher, token, client)
 	base.Source = "GitLab"
 	base.BaseURL = "https://gitlab.com/api/v4"
+	// GitLab uses the PRIVATE-TOKEN header, but a token isn't required for public repos.
+	base.Auth = HeaderAuth{Header: "PRIVATE-TOKEN"}
 	searcher.BaseRepoSearcher = base
 	return searcher
 }
 
-// buildSearchURL implements the RepoSearcher interface for GitLab.
+// buildSearchURL implements the RepoSearcher interface for GitLab. A plain
+// search lists /projects; a group or a scope other than projects goes
+// through the search API instead.
 func (g *GitLabSearcher) buildSearchURL(query string, page, perPage int) (string, error) {
-	u, err := url.Parse(g.BaseURL + "/projects")
+	endpoint := g.BaseURL + "/projects"
+	if g.Group != "" {
+		endpoint = g.BaseURL + "/groups/" + url.PathEscape(g.Group) + "/search"
+	} else if g.Scope != "" && g.Scope != "projects" {
+		endpoint = g.BaseURL + "/search"
+	}
+	u, err := url.Parse(endpoint)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse base URL: %w", err)
 	}
 	q := u.Query()
 	q.Set("search", query)
+	if strings.HasSuffix(u.Path, "/search") {
+		q.Set("scope", cmp.Or(g.Scope, "projects"))
+	} else {
+		if g.SearchNamespaces {
+			q.Set("search_namespaces", "true")
+		}
+		if !g.UpdatedSince.IsZero() {
+			q.Set("last_activity_after", g.UpdatedSince.UTC().Format(time.RFC3339))
+		}
+	}
 	q.Set("page", fmt.Sprintf("%d", page))
 	q.Set("per_page", fmt.Sprintf("%d", perPage))
 	u.RawQuery = q.Encode()
@@ -72,16 +111,15 @@ func (g *GitLabSearcher) buildSearchRequest(ctx context.Context, url string) (*h
 	}
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("User-Agent", "go-repo-searcher/1.0")
-	// GitLab uses PRIVATE-TOKEN header for authentication, but it's not required for public repos.
-	// The token is now optional.
-	if g.Token != "" {
-		req.Header.Set("PRIVATE-TOKEN", g.Token)
-	}
 	return req, nil
 }
 
 // parseSearchResponse implements the RepoSearcher interface for GitLab.
 func (g *GitLabSearcher) parseSearchResponse(body io.Reader) (summaries []RepositorySummary, totalCount int, hasMore bool, err error) {
+	if g.Scope == "blobs" {
+		return g.parseBlobs(body)
+	}
+
 	// GitLab's response for a project search is a direct array of repositories.
 	var repos []gitLabRepository
 	if err := json.NewDecoder(body).Decode(&repos); err != nil {
@@ -89,19 +127,82 @@ func (g *GitLabSearcher) parseSearchResponse(body io.Reader) (summaries []Reposi
 	}
 
 	summaries = make([]RepositorySummary, len(repos))
-	for i, repo := range repos {
-		summaries[i] = g.mapRepoToSummary(repo)
+	for i := range repos {
+		summaries[i] = g.mapRepoToSummary(&repos[i])
 	}
 
-	// GitLab returns pagination info in headers (X-Total, X-Next-Page), which
-	// we can't access here. We'll follow the same pattern as GitCode.
+	// GitLab returns pagination info in headers (X-Total, X-Next-Page); see
+	// parsePageHeaders.
 	totalCount = -1 // -1 signifies unknown
 	hasMore = len(repos) > 0
 	return summaries, totalCount, hasMore, nil
 }
 
+// itemSchema implements schemaSource for GitLab. Forks carry
+// forked_from_project, and the license is only sent when asked for; blob
+// searches aren't checked.
+func (g *GitLabSearcher) itemSchema() (item any, optional []string) {
+	if g.Scope == "blobs" {
+		return nil, nil
+	}
+	return gitLabRepository{}, []string{"forked_from_project", "license"}
+}
+
+// rawItemPaths implements rawItemSource for GitLab.
+func (g *GitLabSearcher) rawItemPaths() (items, url string) {
+	return "$[*]", "$.web_url"
+}
+
+// parsePageHeaders implements headerPaginator for GitLab. X-Total is left
+// out for more than 10,000 results, so the total may stay unknown.
+func (g *GitLabSearcher) parsePageHeaders(h http.Header, page, perPage, totalCount int, hasMore bool) (int, bool) {
+	return gitLabPageHeaders.read(h, page, perPage, totalCount, hasMore)
+}
+
+// parseBlobs maps file hits to placeholder summaries holding only the
+// project ID as FullName and the matched path as Description;
+// resolveBlobProjects replaces them with the projects.
+func (g *GitLabSearcher) parseBlobs(body io.Reader) (summaries []RepositorySummary, totalCount int, hasMore bool, err error) {
+	var blobs []gitLabBlob
+	if err := json.NewDecoder(body).Decode(&blobs); err != nil {
+		return nil, 0, false, fmt.Errorf("failed to unmarshal GitLab blob search: %w", err)
+	}
+	summaries = make([]RepositorySummary, len(blobs))
+	for i, blob := range blobs {
+		summaries[i] = RepositorySummary{
+			FullName:    strconv.FormatInt(blob.ProjectID, 10),
+			Description: blob.Path,
+		}
+	}
+	return summaries, -1, len(blobs) > 0, nil
+}
+
+// resolveBlobProjects replaces the file hits of a blobs-scoped search with
+// the projects containing them, each listed once, in order of first hit.
+// A project that can't be looked up is dropped with a warning.
+func (g *GitLabSearcher) resolveBlobProjects(ctx context.Context, result *SearchResult) {
+	seen := make(map[string]bool)
+	projects := make([]RepositorySummary, 0, len(result.Items))
+	for _, hit := range result.Items {
+		if seen[hit.FullName] {
+			continue
+		}
+		seen[hit.FullName] = true
+		detail, err := g.fetchRepo(ctx, hit.FullName, "", "")
+		if err != nil {
+			log.Printf("Warning: failed to look up GitLab project %s (matched %s): %v", hit.FullName, hit.Description, err)
+			continue
+		}
+		detail.Summary.PreviousFullName = "" // Looked up by ID, not renamed
+		projects = append(projects, detail.Summary)
+	}
+	log.Printf("%d file matches are in %d projects.", len(result.Items), len(projects))
+	result.Items = projects
+	result.TotalCount = -1
+}
+
 // mapRepoToSummary converts a GitLab-specific repo to the generic summary.
-func (g *GitLabSearcher) mapRepoToSummary(repo gitLabRepository) RepositorySummary {
+func (g *GitLabSearcher) mapRepoToSummary(repo *gitLabRepository) RepositorySummary {
 	license := "None"
 	if repo.License != nil && repo.License.Name != "" {
 		license = repo.License.Name
@@ -119,9 +220,61 @@ func (g *GitLabSearcher) mapRepoToSummary(repo gitLabRepository) RepositorySumma
 		UpdatedAt:       repo.LastActivityAt,
 		IsPrivate:       repo.Visibility == "private",
 		IsFork:          repo.ForkedFromProject != nil,
+		ParentFullName:  mapString(repo.ForkedFromProject, "path_with_namespace"),
+		DefaultBranch:   repo.DefaultBranch,
 		IsArchived:      repo.Archived,
-		Topics:          repo.Topics,
-		License:         license,
+		Topics:          internAll(repo.Topics),
+		License:         intern(license),
 		OpenIssuesCount: repo.OpenIssuesCount,
 	}
 }
+
+// --- Scoped Searches ---
+
+// gitLabScopes are the accepted -gitlab-scope values: the search API scopes
+// whose hits can be reported as repositories.
+var gitLabScopes = []string{"projects", "blobs"}
+
+// gitLabOptions are the GitLab-specific search flags.
+type gitLabOptions struct {
+	// URL is the instance to search, e.g. https://gitlab.example.com; empty means gitlab.com.
+	URL              string
+	Group            string
+	Scope            string
+	SearchNamespaces bool
+}
+
+// validate checks the option values.
+func (o gitLabOptions) validate() error {
+	if o.Scope != "" && !slices.Contains(gitLabScopes, o.Scope) {
+		return fmt.Errorf("unknown GitLab scope %q; must be one of %s", o.Scope, strings.Join(gitLabScopes, ", "))
+	}
+	if o.URL != "" {
+		if u, err := url.Parse(o.URL); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid GitLab URL %q", o.URL)
+		}
+	}
+	return nil
+}
+
+// apply configures a GitLab searcher; other searchers are left alone.
+func (o gitLabOptions) apply(searcher searcherTemplate) {
+	var g *GitLabSearcher
+	switch s := searcher.(type) {
+	case *GitLabSearcher:
+		g = s
+	case *GitLabGraphQLSearcher:
+		g = s.GitLabSearcher
+		if o.URL != "" {
+			s.GraphQLURL = strings.TrimSuffix(o.URL, "/") + "/api/graphql"
+		}
+	default:
+		return
+	}
+	if o.URL != "" {
+		g.BaseURL = strings.TrimSuffix(o.URL, "/") + "/api/v4"
+	}
+	g.Group = o.Group
+	g.Scope = o.Scope
+	g.SearchNamespaces = o.SearchNamespaces
+}
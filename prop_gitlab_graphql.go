@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// --- GitLab GraphQL Searcher ---
+//
+// GitLab's REST project search reports neither languages nor a total, so
+// results show "Unknown" and the footer can't say how many there are. The
+// GraphQL API returns both, plus the namespace, in a single query, and pages
+// with cursors (keyset pagination) rather than page numbers. It is offered
+// as the separate "gitlab-graphql" service; everything other than search is
+// shared with the REST searcher.
+
+// gitLabProjectsQuery asks for one page of projects matching $search.
+const gitLabProjectsQuery = `query($search: String!, $searchNamespaces: Boolean, $first: Int!, $after: String) {
+  projects(search: $search, searchNamespaces: $searchNamespaces, first: $first, after: $after) {
+    count
+    pageInfo { endCursor hasNextPage }
+    nodes {
+      name fullPath description webUrl visibility createdAt lastActivityAt
+      starCount forksCount archived openIssuesCount topics
+      namespace { fullPath name }
+      languages { name share }
+      repository { rootRef }
+    }
+  }
+}`
+
+// gitLabGraphQLResponse is the raw JSON structure for gitLabProjectsQuery.
+type gitLabGraphQLResponse struct {
+	Data struct {
+		Projects struct {
+			Count    int `json:"count"`
+			PageInfo struct {
+				EndCursor   string `json:"endCursor"`
+				HasNextPage bool   `json:"hasNextPage"`
+			} `json:"pageInfo"`
+			Nodes []gitLabGraphQLProject `json:"nodes"`
+		} `json:"projects"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+type gitLabGraphQLProject struct {
+	Name            string   `json:"name"`
+	FullPath        string   `json:"fullPath"`
+	Description     string   `json:"description"`
+	WebURL          string   `json:"webUrl"`
+	Visibility      string   `json:"visibility"`
+	CreatedAt       string   `json:"createdAt"`
+	LastActivityAt  string   `json:"lastActivityAt"`
+	StarCount       int      `json:"starCount"`
+	ForksCount      int      `json:"forksCount"`
+	Archived        bool     `json:"archived"`
+	OpenIssuesCount int      `json:"openIssuesCount"`
+	Topics          []string `json:"topics"`
+	Namespace       *struct {
+		FullPath string `json:"fullPath"`
+		Name     string `json:"name"`
+	} `json:"namespace"`
+	Languages []struct {
+		Name  string  `json:"name"`
+		Share float64 `json:"share"` // Percent
+	} `json:"languages"`
+	Repository *struct {
+		RootRef string `json:"rootRef"`
+	} `json:"repository"`
+}
+
+// GitLabGraphQLSearcher searches GitLab through its GraphQL API. It embeds
+// the REST searcher for repository details and enrichment.
+type GitLabGraphQLSearcher struct {
+	*GitLabSearcher
+	// GraphQLURL is the GraphQL endpoint.
+	GraphQLURL string
+}
+
+// NewGitLabGraphQLSearcher creates a new GraphQL searcher for GitLab.
+func NewGitLabGraphQLSearcher(token string, client *http.Client) *GitLabGraphQLSearcher {
+	searcher := &GitLabGraphQLSearcher{
+		GitLabSearcher: NewGitLabSearcher(token, client),
+		GraphQLURL:     "https://gitlab.com/api/graphql",
+	}
+	// Search through the GraphQL primitives below instead of the REST ones.
+	searcher.implementation = searcher
+	return searcher
+}
+
+// buildSearchURL implements the RepoSearcher interface. Pages after the
+// first can only be reached by cursor; see buildCursorURL.
+func (g *GitLabGraphQLSearcher) buildSearchURL(query string, page, perPage int) (string, error) {
+	if page != 1 {
+		return "", fmt.Errorf("GitLab GraphQL pages are addressed by cursor, not page %d", page)
+	}
+	return g.buildCursorURL(query, "", perPage)
+}
+
+// buildCursorURL implements cursorPaginator. The URL carries the query
+// variables, so each page has a distinct URL for logs, dry runs and
+// fixtures; buildSearchRequest moves them into the POST body.
+func (g *GitLabGraphQLSearcher) buildCursorURL(query, cursor string, perPage int) (string, error) {
+	if g.Group != "" || (g.Scope != "" && g.Scope != "projects") {
+		return "", fmt.Errorf("group and scoped searches need the REST API; use -service gitlab")
+	}
+	u, err := url.Parse(g.GraphQLURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse GraphQL URL: %w", err)
+	}
+	q := u.Query()
+	q.Set("search", query)
+	if g.SearchNamespaces {
+		q.Set("search_namespaces", "true")
+	}
+	q.Set("first", strconv.Itoa(perPage))
+	if cursor != "" {
+		q.Set("after", cursor)
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// buildSearchRequest implements the RepoSearcher interface: it POSTs
+// gitLabProjectsQuery with the variables taken from the URL.
+func (g *GitLabGraphQLSearcher) buildSearchRequest(ctx context.Context, rawURL string) (*http.Request, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+	q := u.Query()
+	first, err := strconv.Atoi(q.Get("first"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid page size %q: %w", q.Get("first"), err)
+	}
+	variables := map[string]any{"search": q.Get("search"), "first": first}
+	if q.Get("search_namespaces") == "true" {
+		variables["searchNamespaces"] = true
+	}
+	if after := q.Get("after"); after != "" {
+		variables["after"] = after
+	}
+	payload, err := json.Marshal(map[string]any{"query": gitLabProjectsQuery, "variables": variables})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal GraphQL query: %w", err)
+	}
+
+	u.RawQuery = ""
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "go-repo-searcher/1.0")
+	return req, nil
+}
+
+// itemSchema implements schemaSource. GraphQL responses hold exactly the
+// fields the query asks for, and a renamed field fails the query, so there
+// is nothing to check.
+func (g *GitLabGraphQLSearcher) itemSchema() (item any, optional []string) {
+	return nil, nil
+}
+
+// rawItemPaths implements rawItemSource.
+func (g *GitLabGraphQLSearcher) rawItemPaths() (items, url string) {
+	return "$.data.projects.nodes[*]", "$.webUrl"
+}
+
+// parseSearchResponse implements the RepoSearcher interface.
+func (g *GitLabGraphQLSearcher) parseSearchResponse(body io.Reader) (summaries []RepositorySummary, totalCount int, hasMore bool, err error) {
+	summaries, totalCount, next, err := g.parseCursorResponse(body)
+	return summaries, totalCount, next != "", err
+}
+
+// parseCursorResponse implements cursorPaginator.
+func (g *GitLabGraphQLSearcher) parseCursorResponse(body io.Reader) (summaries []RepositorySummary, totalCount int, next string, err error) {
+	var resp gitLabGraphQLResponse
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		return nil, 0, "", fmt.Errorf("failed to unmarshal GitLab GraphQL response: %w", err)
+	}
+	// GraphQL reports errors with a 200 status.
+	if len(resp.Errors) > 0 {
+		messages := make([]string, len(resp.Errors))
+		for i, e := range resp.Errors {
+			messages[i] = e.Message
+		}
+		return nil, 0, "", fmt.Errorf("GitLab GraphQL error: %s", strings.Join(messages, "; "))
+	}
+
+	projects := resp.Data.Projects
+	summaries = make([]RepositorySummary, 0, len(projects.Nodes))
+	for i := range projects.Nodes {
+		summary := g.mapProjectToSummary(&projects.Nodes[i])
+		// The projects query has no activity filter, so -since-last-run is
+		// applied here instead.
+		if !g.UpdatedSince.IsZero() {
+			if t, ok := parseTimestamp(summary.UpdatedAt); ok && t.Before(g.UpdatedSince) {
+				continue
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	if projects.PageInfo.HasNextPage {
+		next = projects.PageInfo.EndCursor
+	}
+	return summaries, projects.Count, next, nil
+}
+
+// mapProjectToSummary converts a GraphQL project to the generic summary.
+// The primary language is the one with the largest share.
+func (g *GitLabGraphQLSearcher) mapProjectToSummary(p *gitLabGraphQLProject) RepositorySummary {
+	summary := RepositorySummary{
+		Name:            p.Name,
+		FullName:        p.FullPath,
+		Description:     strings.TrimSpace(p.Description),
+		URL:             p.WebURL,
+		Stars:           p.StarCount,
+		Forks:           p.ForksCount,
+		Language:        "Unknown",
+		CreatedAt:       p.CreatedAt,
+		UpdatedAt:       p.LastActivityAt,
+		IsPrivate:       p.Visibility == "private",
+		IsArchived:      p.Archived,
+		Topics:          internAll(p.Topics),
+		License:         "None", // Not exposed on the GraphQL project type
+		OpenIssuesCount: p.OpenIssuesCount,
+	}
+	if p.Namespace != nil {
+		summary.Namespace = p.Namespace.FullPath
+	}
+	if p.Repository != nil {
+		summary.DefaultBranch = p.Repository.RootRef
+	}
+	if len(p.Languages) > 0 {
+		summary.Languages = make(map[string]float64, len(p.Languages))
+		top, share := "", 0.0
+		for _, lang := range p.Languages {
+			summary.Languages[intern(lang.Name)] = lang.Share
+			if lang.Share > share {
+				top, share = lang.Name, lang.Share
+			}
+		}
+		if top != "" {
+			summary.Language = intern(top)
+		}
+	}
+	return summary
+}
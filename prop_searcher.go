@@ -1,12 +1,19 @@
 package main
 
 import (
+	"bytes"
+	"cmp"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -30,6 +37,60 @@ type RepositorySummary struct {
 	Topics          []string `json:"topics"`
 	License         string   `json:"license"`
 	OpenIssuesCount int      `json:"open_issues_count"`
+	// PreviousFullName is the name the repository was looked up by, if it has
+	// since been renamed or transferred.
+	PreviousFullName string `json:"previous_full_name,omitempty"`
+	// Namespace is the group or user path the repository lives in, if reported.
+	Namespace string `json:"namespace,omitempty"`
+	// ParentFullName is the repository this one was forked from, if known.
+	ParentFullName string `json:"parent_full_name,omitempty"`
+	// UpstreamURL is the repository at the top of this fork's parent chain (-resolve-forks).
+	UpstreamURL string `json:"upstream_url,omitempty"`
+	// SuccessorURL is where an archived project says development continued.
+	SuccessorURL string `json:"successor_url,omitempty"`
+	// DefaultBranch is the branch the repository is developed on, if reported.
+	DefaultBranch string `json:"default_branch,omitempty"`
+	// Homepage is the project website the repository links to, if any.
+	Homepage string `json:"homepage,omitempty"`
+	// HasPages is set when the provider hosts a Pages site for the repository.
+	HasPages bool `json:"has_pages,omitempty"`
+	// HasDocsSite is set when Pages or the homepage serves documentation; DocsURL is where, if known.
+	HasDocsSite bool   `json:"has_docs_site,omitempty"`
+	DocsURL     string `json:"docs_url,omitempty"`
+	// Governance holds branch protection and commit signing signals (-enrich=governance).
+	Governance *Governance `json:"governance,omitempty"`
+	// SBOM summarizes the saved software bill of materials (-enrich=sbom).
+	SBOM *SBOMSummary `json:"sbom,omitempty"`
+	// CISystems are the CI services the repository is configured for (-enrich=ci).
+	CISystems []string `json:"ci_systems,omitempty"`
+	// Package is the repository's entry in its language's package registry (-enrich=packages).
+	Package *PackageInfo `json:"package,omitempty"`
+	// Images are container images published from the repository (-enrich=images).
+	Images []ContainerImage `json:"images,omitempty"`
+	// GoodFirstIssues and HelpWantedIssues count open issues with those labels (-enrich=contrib).
+	GoodFirstIssues  int `json:"good_first_issues,omitempty"`
+	HelpWantedIssues int `json:"help_wanted_issues,omitempty"`
+	// LastReleaseAt is when the latest release was published, and
+	// ReleaseCadenceDays the mean days between recent releases (-enrich=releases).
+	LastReleaseAt      string  `json:"last_release_at,omitempty"`
+	ReleaseCadenceDays float64 `json:"release_cadence_days,omitempty"`
+	// Provider is the service the repository was found on.
+	Provider string `json:"provider,omitempty"`
+	// Extra holds provider fields the summary has no place for (see
+	// field_mappings in the config file and -include-raw-fields).
+	Extra map[string]any `json:"extra,omitempty"`
+	// NameCollisions counts other results sharing this repository's name.
+	NameCollisions int `json:"name_collisions,omitempty"`
+	// Tags are added locally by the configured tag rules.
+	Tags []string `json:"tags,omitempty"`
+	// Languages is each language's share of the code in percent (-enrich=languages).
+	Languages map[string]float64 `json:"languages,omitempty"`
+	// Dependents is the number of repositories depending on this one (-enrich=dependents).
+	Dependents int `json:"dependents,omitempty"`
+	// Relevance is the client-side score against the query's words (-sort relevance).
+	Relevance float64 `json:"relevance,omitempty"`
+	// Matrix lists the -matrix combinations that found the repository.
+	Matrix []string `json:"matrix,omitempty"`
 }
 
 // SearchResult contains all collected repositories and metadata from a search.
@@ -38,10 +99,15 @@ type SearchResult struct {
 	Query      string              `json:"query"`
 	TotalCount int                 `json:"total_count"` // Total available, not just retrieved
 	Items      []RepositorySummary `json:"items"`
+	// Warnings describe non-fatal data-quality issues, each prefixed with its provider.
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // --- Template Method Pattern ---
 
+// defaultPerPage is the page size requested unless a searcher overrides it.
+const defaultPerPage = 50 // Common page size
+
 // RepoSearcher defines the "primitive operations" that concrete implementations
 // must provide. This is the interface that the template method will call.
 type RepoSearcher interface {
@@ -56,21 +122,115 @@ type RepoSearcher interface {
 	parseSearchResponse(body io.Reader) (summaries []RepositorySummary, totalCount int, hasMore bool, err error)
 }
 
+// cursorPaginator is implemented by providers whose pages are addressed by
+// an opaque cursor taken from the previous page (keyset pagination) instead
+// of a page number. Search then uses these in place of buildSearchURL and
+// parseSearchResponse for every page after the first.
+type cursorPaginator interface {
+	// buildCursorURL creates the URL for the page starting at cursor ("" for the first page).
+	buildCursorURL(query, cursor string, perPage int) (string, error)
+	// parseCursorResponse is parseSearchResponse, returning the cursor of
+	// the next page instead of hasMore ("" when there are no more pages).
+	parseCursorResponse(body io.Reader) (summaries []RepositorySummary, totalCount int, next string, err error)
+}
+
+// headerPaginator is implemented by providers that report the total and
+// the next page in response headers, which parseSearchResponse can't see.
+type headerPaginator interface {
+	// parsePageHeaders returns the total and whether another page follows,
+	// given what the body said; it returns those unchanged if the headers
+	// are missing.
+	parsePageHeaders(h http.Header, page, perPage, totalCount int, hasMore bool) (int, bool)
+}
+
+// pageHeaders names the headers a provider reports paging in. Empty names
+// are not sent by that provider.
+type pageHeaders struct {
+	Total, TotalPages, NextPage string
+}
+
+var (
+	gitLabPageHeaders = pageHeaders{Total: "X-Total", TotalPages: "X-Total-Pages", NextPage: "X-Next-Page"}
+	giteePageHeaders  = pageHeaders{Total: "Total_count", TotalPages: "Total_page"}
+)
+
+// present reports whether h has the total header.
+func (p pageHeaders) present(h http.Header) bool { return h.Get(p.Total) != "" }
+
+// read implements parsePageHeaders for these header names. An explicit next
+// page wins; otherwise hasMore is worked out from the page count or total.
+func (p pageHeaders) read(h http.Header, page, perPage, totalCount int, hasMore bool) (int, bool) {
+	total, err := strconv.Atoi(h.Get(p.Total))
+	if err == nil && total >= 0 {
+		totalCount = total
+	}
+	if _, ok := h[http.CanonicalHeaderKey(p.NextPage)]; ok && p.NextPage != "" {
+		return totalCount, h.Get(p.NextPage) != ""
+	}
+	if pages, perr := strconv.Atoi(h.Get(p.TotalPages)); perr == nil && p.TotalPages != "" {
+		return totalCount, page < pages
+	}
+	if err == nil && total >= 0 {
+		return totalCount, page*perPage < total
+	}
+	return totalCount, hasMore
+}
+
 // BaseRepoSearcher contains the "template method" (Search) and common fields.
 // It embeds the RepoSearcher interface to call the primitive operations.
 // This embedding is the Go equivalent of an abstract base class.
+//
+// A searcher is safe for concurrent use once configured: Search keeps all of
+// its pagination and retry/backoff state in local variables, and the
+// primitive operations must not mutate the implementation. Exported fields
+// and Use must not be changed while a search is running.
 type BaseRepoSearcher struct {
 	// implementation holds the concrete implementation (e.g., GitCodeSearcher).
 	// This is the "subclass" we will call.
 	implementation RepoSearcher
 	HTTPClient     *http.Client
 	Token          string
-	Source         string
-	BaseURL        string
+	// Auth decides how Token is attached to requests.
+	Auth    AuthStrategy
+	Source  string
+	BaseURL string
 	// MaxRetries is the number of times to retry a request on failure
 	MaxRetries int
 	// RetryDelay is the initial delay between retries
 	RetryDelay time.Duration
+	// PerPage is the number of results requested per page
+	PerPage int
+	// PageDelay is the pause between page requests, to respect rate limits
+	PageDelay time.Duration
+	// Timeout bounds a whole Search call on this provider (0 means no limit
+	// beyond the caller's context)
+	Timeout time.Duration
+	// UpdatedSince, if set, asks providers that support it to return only
+	// repositories active since then (see -since-last-run, -active-within)
+	UpdatedSince time.Time
+	// SpillAfter, if positive, caps the results held in memory while a
+	// search pages and deduplicates; the rest are spooled to temporary
+	// files. The unique results are still returned in memory (see
+	// resultSpool)
+	SpillAfter int
+	// Strict makes a failed or unreadable page fail the search instead of
+	// returning partial results (see -strict)
+	Strict bool
+	// RawArchive, if set, keeps every page as received (see -save-raw)
+	RawArchive *rawArchive
+	// FieldMappings fill summary fields from each result's raw JSON
+	// (see the field_mappings config setting and -include-raw-fields)
+	FieldMappings []fieldMapping
+	// ExtraParams are added to every search URL, replacing the provider's
+	// own values for the same keys (see -provider-param)
+	ExtraParams url.Values
+	// OnEvent, if set, is called synchronously with progress events.
+	OnEvent func(SearchEvent)
+	// notes are warnings found while configuring the searcher (e.g. a
+	// missing token), reported with every result; see warn.
+	notes []string
+	// middleware wraps HTTPClient's transport; see Use.
+	middleware []Middleware
 }
 
 // NewBaseRepoSearcher creates a new base searcher.
@@ -87,6 +247,8 @@ func NewBaseRepoSearcher(impl RepoSearcher, token string, client *http.Client) *
 		Token:          token,
 		MaxRetries:     3,
 		RetryDelay:     1 * time.Second,
+		PerPage:        defaultPerPage,
+		PageDelay:      100 * time.Millisecond,
 	}
 }
 
@@ -101,72 +263,333 @@ func (s *BaseRepoSearcher) Search(ctx context.Context, query string, maxPages in
 		return nil, errors.New("maxPages must be greater than 0")
 	}
 
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+
 	var allRepos []RepositorySummary
 	var totalCount int
-	const perPage = 50 // Common page size
+	var spool *resultSpool
+	if s.SpillAfter > 0 {
+		spool = newResultSpool(s.SpillAfter)
+		defer spool.Close()
+	}
+	perPage := s.PerPage
+	started := time.Now()
+	keyset, isKeyset := s.implementation.(cursorPaginator)
+	var cursor string
+	warnings := slices.Clone(s.notes)
+	if cr, ok := s.implementation.(caveatReporter); ok {
+		for _, caveat := range cr.searchCaveats() {
+			warnings = addWarning(warnings, s.Source+": "+caveat)
+		}
+	}
+	capped := false
+	// The index can shift while we page through it, repeating results from
+	// one page on the next; only the first copy is kept.
+	seen := make(map[string]bool)
+	duplicates, retrieved := 0, 0
+	integrity := integrityCheck{source: s.Source, perPage: perPage, filtered: !s.UpdatedSince.IsZero()}
 
 	for page := 1; page <= maxPages; page++ {
+		pageStarted := time.Now()
+		s.emit(SearchEvent{Kind: EventPageStarted, Page: page})
+
 		// 1. Build the URL (Primitive Operation)
-		url, err := s.implementation.buildSearchURL(query, page, perPage)
+		var url string
+		var err error
+		if isKeyset {
+			url, err = keyset.buildCursorURL(query, cursor, perPage)
+		} else {
+			url, err = s.implementation.buildSearchURL(query, page, perPage)
+		}
+		if err == nil {
+			url, err = s.withExtraParams(url)
+		}
 		if err != nil {
 			return nil, fmt.Errorf("failed to build URL for page %d: %w", page, err)
 		}
 
 		log.Printf("Fetching page %d: %s", page, url)
 
-		// 2. Fetch the data with retries
-		body, err := s.fetchWithRetries(ctx, url)
+		// 2. Fetch and parse the data with retries (Primitive Operations)
+		result, err := s.fetchPage(ctx, url, page, perPage)
+		if err != nil && s.Strict {
+			return nil, fmt.Errorf("page %d: %w (strict mode)", page, err)
+		}
+		if errors.Is(err, errUnreadablePage) {
+			log.Printf("Warning: failed to parse page %d: %v", page, err)
+			warnings = addWarning(warnings, fmt.Sprintf("%s: page %d could not be read (%v); results are partial", s.Source, page, err))
+			break
+		}
 		if err != nil {
 			if page == 1 {
 				return nil, fmt.Errorf("failed to fetch first page: %w", err)
 			}
 			// For subsequent pages, log the error and return what we have
 			log.Printf("Warning: failed to fetch page %d: %v. Returning partial results.", page, err)
+			warnings = addWarning(warnings, fmt.Sprintf("%s: page %d failed (%v); results are partial", s.Source, page, err))
 			break
 		}
-
-		if body == nil {
-			continue // Should not happen if err is nil, but good to check
+		if page == 1 && result.raw != nil {
+			for _, warning := range schemaDrift(s.Source, s.implementation, result.raw) {
+				log.Printf("Warning: %s", warning)
+				warnings = addWarning(warnings, warning)
+			}
 		}
-
-		// 3. Parse the response (Primitive Operation)
-		repos, tc, hasMore, err := s.implementation.parseSearchResponse(body)
-		if err != nil {
-			log.Printf("Warning: failed to parse page %d: %v", page, err)
-			body.Close() // Close the body even on parse error
-			break
+		if s.RawArchive != nil {
+			if err := s.RawArchive.save(query, page, url, result.header, result.raw); err != nil {
+				log.Printf("Warning: failed to archive page %d: %v", page, err)
+				warnings = addWarning(warnings, fmt.Sprintf("%s: page %d could not be archived (%v)", s.Source, page, err))
+			}
 		}
-		body.Close() // Close the body on success
+		repos, tc, hasMore := result.repos, result.totalCount, result.hasMore
+		cursor = result.cursor
 
 		if page == 1 {
 			totalCount = tc // Set total count from the first page
+			// Size the result slice once instead of growing it page by page.
+			if expected := min(totalCount, maxPages*perPage); expected > 0 && spool == nil {
+				allRepos = make([]RepositorySummary, 0, expected)
+			}
 		}
 
-		allRepos = append(allRepos, repos...)
+		fetched, pageDuplicates := len(repos), 0
+		repos = slices.DeleteFunc(repos, func(r RepositorySummary) bool {
+			key := strings.ToLower(cmp.Or(r.FullName, r.URL))
+			if seen[key] {
+				pageDuplicates++
+				return true
+			}
+			seen[key] = true
+			return false
+		})
+		duplicates += pageDuplicates
+		integrity.page(page, fetched, pageDuplicates, tc, hasMore)
+		for i := range repos {
+			repos[i].Provider = s.Source
+		}
+		if spool != nil {
+			if err := spool.Add(repos); err != nil {
+				return nil, err
+			}
+		} else {
+			allRepos = append(allRepos, repos...)
+		}
+		retrieved += len(repos)
+		s.emit(SearchEvent{Kind: EventPageCompleted, Page: page, Items: len(repos), Duplicates: pageDuplicates, Retrieved: retrieved,
+			TotalCount: totalCount, RateRemaining: result.rateRemaining, Elapsed: time.Since(pageStarted)})
 
-		if !hasMore || len(repos) == 0 {
+		if !hasMore || fetched == 0 {
 			log.Printf("No more results found. Stopping at page %d.", page)
 			break // No more items, we've reached the end
 		}
 
 		// Respect rate limiting
 		if page < maxPages {
-			time.Sleep(100 * time.Millisecond)
+			if err := sleepCtx(ctx, s.PageDelay); err != nil {
+				if s.Strict {
+					return nil, fmt.Errorf("search cancelled after page %d: %w (strict mode)", page, err)
+				}
+				log.Printf("Warning: search cancelled after page %d: %v. Returning partial results.", page, err)
+				warnings = addWarning(warnings, fmt.Sprintf("%s: search cancelled after page %d; results are partial", s.Source, page))
+				break
+			}
+		} else if totalCount < 0 || retrieved < totalCount {
+			// The last page allowed; a page that completes the total isn't.
+			capped = true
 		}
 	}
+	if duplicates > 0 {
+		log.Printf("Skipped %d duplicate results returned on more than one page.", duplicates)
+		warnings = addWarning(warnings, fmt.Sprintf("%s: skipped %d duplicate results returned on more than one page", s.Source, duplicates))
+	}
+	for _, warning := range integrity.warnings() {
+		log.Printf("Warning: %s", warning)
+		warnings = addWarning(warnings, warning)
+	}
+	if capped {
+		more := "more results may be available"
+		if totalCount > 0 {
+			more = fmt.Sprintf("%d results are available in total", totalCount)
+		}
+		warnings = addWarning(warnings, fmt.Sprintf("%s: stopped at the -pages limit of %d; %s", s.Source, maxPages, more))
+	}
 
+	if spool != nil {
+		items, err := spool.Items()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read spooled results: %w", err)
+		}
+		allRepos = items
+	}
+
+	s.emit(SearchEvent{Kind: EventProviderFinished, Items: len(allRepos), TotalCount: totalCount, Elapsed: time.Since(started)})
 	return &SearchResult{
 		Source:     s.Source,
 		Query:      query,
 		TotalCount: totalCount,
 		Items:      allRepos,
+		Warnings:   warnings,
 	}, nil
 }
 
+// errUnreadablePage marks a page whose body couldn't be parsed.
+var errUnreadablePage = errors.New("unreadable page")
+
+// searchPage is one parsed page of results.
+type searchPage struct {
+	repos      []RepositorySummary
+	totalCount int
+	hasMore    bool
+	cursor     string // Next page, for cursorPaginator providers
+	// rateRemaining is the requests left in the rate limit, or -1 if unknown.
+	rateRemaining int
+	// raw and header are the response as received, if the searcher keeps
+	// raw pages (see keepsRaw).
+	raw    []byte
+	header http.Header
+}
+
+// fetchPage fetches and parses one page. A body that isn't JSON at all,
+// such as a truncated response or an HTML error page sent with status 200,
+// is usually a transient provider fault, so the page is fetched again
+// (bypassing the cache) up to MaxRetries times. Parse errors are wrapped
+// in errUnreadablePage.
+func (s *BaseRepoSearcher) fetchPage(ctx context.Context, url string, page, perPage int) (searchPage, error) {
+	var prepare func(*http.Request)
+	delay := s.RetryDelay
+	for attempt := 1; ; attempt++ {
+		resp, err := s.doWithRetries(ctx, url, page, prepare)
+		if err != nil {
+			return searchPage{}, err
+		}
+
+		body := io.Reader(resp.Body)
+		var raw []byte
+		// The first page is also kept for the schema drift check.
+		if s.keepsRaw() || page == 1 {
+			// A body cut short fails to parse below, and is retried.
+			raw, _ = io.ReadAll(resp.Body)
+			body = bytes.NewReader(raw)
+		}
+		p, err := s.parsePage(body, raw, resp.Header, page, perPage)
+		resp.Body.Close()
+		if err == nil {
+			return p, nil
+		}
+		if !isMalformedBody(err) || attempt >= s.MaxRetries {
+			return searchPage{}, fmt.Errorf("%w: %w", errUnreadablePage, err)
+		}
+
+		log.Printf("Page %d was not valid JSON (attempt %d/%d): %v. Retrying in %v...", page, attempt, s.MaxRetries, err, delay)
+		s.emit(SearchEvent{Kind: EventRetry, Page: page, Attempt: attempt, Delay: delay, Err: err})
+		if err := sleepCtx(ctx, delay); err != nil {
+			return searchPage{}, err
+		}
+		delay *= 2
+		prepare = func(req *http.Request) { req.Header.Set("Cache-Control", "no-cache") }
+	}
+}
+
+// parsePage parses one page's body and response headers. raw is the body
+// as read, if the searcher keeps raw pages; the field mappings are applied
+// to the results from it.
+func (s *BaseRepoSearcher) parsePage(body io.Reader, raw []byte, header http.Header, page, perPage int) (searchPage, error) {
+	var p searchPage
+	var err error
+	if keyset, ok := s.implementation.(cursorPaginator); ok {
+		p.repos, p.totalCount, p.cursor, err = keyset.parseCursorResponse(body)
+		p.hasMore = p.cursor != ""
+	} else {
+		p.repos, p.totalCount, p.hasMore, err = s.implementation.parseSearchResponse(body)
+	}
+	if err != nil {
+		return searchPage{}, err
+	}
+	if raw != nil {
+		if len(s.FieldMappings) > 0 {
+			s.applyRaw(raw, p.repos)
+		}
+		p.raw, p.header = raw, header
+	}
+	if hp, ok := s.implementation.(headerPaginator); ok {
+		p.totalCount, p.hasMore = hp.parsePageHeaders(header, page, perPage, p.totalCount, p.hasMore)
+	}
+	p.rateRemaining = rateLimitRemaining(header)
+	return p, nil
+}
+
+// isMalformedBody reports whether a parse error means the body wasn't
+// JSON, or was cut short, rather than JSON of an unexpected shape.
+func isMalformedBody(err error) bool {
+	var syntax *json.SyntaxError
+	return errors.As(err, &syntax) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+// Estimate returns the provider's reported total for query, fetching a
+// single one-item page. It returns -1 if the provider doesn't report totals.
+func (s *BaseRepoSearcher) Estimate(ctx context.Context, query string) (int, error) {
+	url, err := s.implementation.buildSearchURL(query, 1, 1)
+	if err == nil {
+		url, err = s.withExtraParams(url)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to build URL: %w", err)
+	}
+	resp, err := s.doWithRetries(ctx, url, 1, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, total, more, err := s.implementation.parseSearchResponse(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	if hp, ok := s.implementation.(headerPaginator); ok {
+		total, _ = hp.parsePageHeaders(resp.Header, 1, 1, total, more)
+	}
+	return total, nil
+}
+
 // fetchWithRetries handles the HTTP GET request and retries on failure.
-func (s *BaseRepoSearcher) fetchWithRetries(ctx context.Context, url string) (io.ReadCloser, error) {
+// page is only used to label progress events.
+func (s *BaseRepoSearcher) fetchWithRetries(ctx context.Context, url string, page int) (io.ReadCloser, error) {
+	resp, err := s.doWithRetries(ctx, url, page, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// apiError is a request that failed with an unexpected HTTP status.
+type apiError struct {
+	Status int
+	Body   string
+}
+
+func (e *apiError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("api request failed with status %d", e.Status)
+	}
+	return fmt.Sprintf("api request failed with status %d: %s", e.Status, e.Body)
+}
+
+// isNotFound reports whether err is a 404 from the provider.
+func isNotFound(err error) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound
+}
+
+// doWithRetries sends a GET request, retrying on failure, and returns the
+// response if its status is 200, or 304 for a conditional request. prepare,
+// if set, is called on every attempt's request before it is sent.
+func (s *BaseRepoSearcher) doWithRetries(ctx context.Context, url string, page int, prepare func(*http.Request)) (*http.Response, error) {
 	var lastErr error
 	delay := s.RetryDelay
+	client := s.client()
 
 	for i := 0; i < s.MaxRetries; i++ {
 		// 1. Build the Request (Primitive Operation)
@@ -174,35 +597,88 @@ func (s *BaseRepoSearcher) fetchWithRetries(ctx context.Context, url string) (io
 		if err != nil {
 			return nil, fmt.Errorf("failed to create request: %w", err)
 		}
+		if err := s.authorize(req); err != nil {
+			return nil, err
+		}
+		if prepare != nil {
+			prepare(req)
+		}
 
-		resp, err := s.HTTPClient.Do(req)
+		resp, err := client.Do(req)
+		if errors.Is(err, errBudgetExhausted) {
+			return nil, errBudgetExhausted // Retrying can't help until the budget resets
+		}
 		if err != nil {
+			err = redactError(err)
 			lastErr = fmt.Errorf("request failed: %w", err)
 			log.Printf("Request attempt %d/%d failed: %v. Retrying in %v...", i+1, s.MaxRetries, err, delay)
-			time.Sleep(delay)
+			s.emit(SearchEvent{Kind: EventRetry, Page: page, Attempt: i + 1, Delay: delay, Err: err})
+			if err := sleepCtx(ctx, delay); err != nil {
+				return nil, err
+			}
 			delay *= 2 // Exponential backoff
 			continue
 		}
 
 		if resp.StatusCode == http.StatusOK {
-			return resp.Body, nil // Success!
+			return resp, nil // Success!
+		}
+		if resp.StatusCode == http.StatusNotModified && prepare != nil {
+			return resp, nil
 		}
 
 		// Read body for error message
 		body, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
-		lastErr = fmt.Errorf("api request failed with status %d: %s", resp.StatusCode, string(body))
+		lastErr = &apiError{Status: resp.StatusCode, Body: redactText(string(body))}
+
+		// A secondary rate limit asks for a specific cool-down; backing off
+		// for less only extends the block.
+		if coolDown, ok := secondaryRateLimit(resp, body, time.Now()); ok {
+			log.Printf("Secondary rate limit hit (attempt %d/%d). Cooling down for %v...", i+1, s.MaxRetries, coolDown)
+			s.emit(SearchEvent{Kind: EventRateLimited, Page: page, Attempt: i + 1, Status: resp.StatusCode, Delay: coolDown, Err: lastErr})
+			if err := sleepCtx(ctx, coolDown); err != nil {
+				return nil, err
+			}
+			continue
+		}
 
 		// Handle specific non-retryable errors
+		if resp.StatusCode == http.StatusUnprocessableEntity {
+			return nil, unprocessableError(s.Source, body)
+		}
 		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusNotFound {
+			if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+				s.emit(SearchEvent{Kind: EventRateLimited, Page: page, Attempt: i + 1, Status: resp.StatusCode, Err: lastErr})
+			}
 			return nil, lastErr // Don't retry auth or not found errors
 		}
 
 		// Retry other server/rate limit errors
 		log.Printf("Request attempt %d/%d failed with status %d. Retrying in %v...", i+1, s.MaxRetries, resp.StatusCode, delay)
-		time.Sleep(delay)
+		kind := EventRetry
+		if resp.StatusCode == http.StatusTooManyRequests {
+			kind = EventRateLimited
+		}
+		s.emit(SearchEvent{Kind: kind, Page: page, Attempt: i + 1, Status: resp.StatusCode, Delay: delay, Err: lastErr})
+		if err := sleepCtx(ctx, delay); err != nil {
+			return nil, err
+		}
 		delay *= 2
 	}
 
 	return nil, fmt.Errorf("failed to fetch URL after %d attempts: %w", s.MaxRetries, lastErr)
 }
+
+// sleepCtx waits for d, returning early with the context's error if it is
+// cancelled first.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
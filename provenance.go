@@ -0,0 +1,283 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --- Provenance ---
+//
+// Scheduled scans feeding compliance workflows need to show how each result
+// file was produced. Every artifact gets a sidecar, <artifact>.provenance.json,
+// recording the tool version, query, providers, timing and request counts
+// along with the artifact's SHA-256, so the JSON output itself keeps its
+// format. With -sign-key the sidecar is also signed with Ed25519
+// (<artifact>.provenance.json.sig); `rexplorer provenance verify` checks both.
+
+// provenanceSuffix and signatureSuffix name the sidecar files.
+const (
+	provenanceSuffix = ".provenance.json"
+	signatureSuffix  = ".sig"
+)
+
+// Provenance describes how an artifact was produced.
+type Provenance struct {
+	Tool       string         `json:"tool"`
+	Version    string         `json:"version"`
+	Revision   string         `json:"revision,omitempty"`
+	Query      string         `json:"query"`
+	Providers  []string       `json:"providers"`
+	StartedAt  time.Time      `json:"started_at"`
+	FinishedAt time.Time      `json:"finished_at"`
+	Requests   map[string]int `json:"requests"` // HTTP requests sent per provider, not counting cache hits
+	Retrieved  int            `json:"retrieved"`
+	Artifact   struct {
+		Name   string `json:"name"`
+		SHA256 string `json:"sha256"`
+	} `json:"artifact"`
+}
+
+// toolVersion returns the module version and VCS revision the binary was
+// built from, as far as the build recorded them.
+func toolVersion() (version, revision string) {
+	version = "devel"
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return version, ""
+	}
+	if v := bi.Main.Version; v != "" && v != "(devel)" {
+		version = v
+	}
+	for _, s := range bi.Settings {
+		if s.Key == "vcs.revision" {
+			revision = s.Value
+		}
+	}
+	return version, revision
+}
+
+// requestCounter counts the requests each provider sends.
+type requestCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// Middleware counts the requests of one provider. Installed after the
+// response cache, it only sees requests that reach the network.
+func (c *requestCounter) Middleware(provider string) Middleware {
+	return MutateRequest(func(*http.Request) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.counts == nil {
+			c.counts = make(map[string]int)
+		}
+		c.counts[provider]++
+	})
+}
+
+// snapshot returns a copy of the counts.
+func (c *requestCounter) snapshot() map[string]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make(map[string]int, len(c.counts))
+	for k, v := range c.counts {
+		out[k] = v
+	}
+	return out
+}
+
+// writeProvenance writes the sidecar for the artifact at path, and signs it
+// if key is set. prov.Artifact is filled in here.
+func writeProvenance(path string, prov Provenance, key ed25519.PrivateKey) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read artifact for provenance: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	prov.Artifact.Name = filepath.Base(path)
+	prov.Artifact.SHA256 = hex.EncodeToString(sum[:])
+	doc, err := json.MarshalIndent(prov, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal provenance: %w", err)
+	}
+	doc = append(doc, '\n')
+	if err := os.WriteFile(path+provenanceSuffix, doc, 0o644); err != nil {
+		return fmt.Errorf("failed to write provenance: %w", err)
+	}
+	if key == nil {
+		return nil
+	}
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(key, doc)) + "\n"
+	if err := os.WriteFile(path+provenanceSuffix+signatureSuffix, []byte(sig), 0o644); err != nil {
+		return fmt.Errorf("failed to write provenance signature: %w", err)
+	}
+	return nil
+}
+
+// loadSigningKey reads an Ed25519 private key in PKCS #8 PEM form, as
+// written by `rexplorer provenance keygen`.
+func loadSigningKey(path string) (ed25519.PrivateKey, error) {
+	block, err := readPEM(path, "PRIVATE KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key %s: %w", path, err)
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key %s is not an Ed25519 key", path)
+	}
+	return edKey, nil
+}
+
+// loadVerifyKey reads an Ed25519 public key in PKIX PEM form.
+func loadVerifyKey(path string) (ed25519.PublicKey, error) {
+	block, err := readPEM(path, "PUBLIC KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key %s: %w", path, err)
+	}
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key %s is not an Ed25519 key", path)
+	}
+	return edKey, nil
+}
+
+// readPEM returns the bytes of the first PEM block of the given type.
+func readPEM(path, blockType string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != blockType {
+		return nil, fmt.Errorf("%s does not hold a PEM %s", path, blockType)
+	}
+	return block.Bytes, nil
+}
+
+// runProvenance implements `rexplorer provenance keygen|verify`.
+func runProvenance(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: rexplorer provenance <keygen|verify> [args]")
+	}
+	switch args[0] {
+	case "keygen":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: rexplorer provenance keygen <name>  (writes <name>.key and <name>.pub)")
+		}
+		return provenanceKeygen(args[1])
+	case "verify":
+		fs := flag.NewFlagSet("provenance verify", flag.ExitOnError)
+		pub := fs.String("key", "", "Public key to check the signature with (PEM); without it only the digest is checked")
+		rest, err := parseInterspersed(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		if len(rest) != 1 {
+			return fmt.Errorf("usage: rexplorer provenance verify [-key name.pub] <artifact>")
+		}
+		return verifyProvenance(rest[0], *pub)
+	default:
+		return fmt.Errorf("unknown provenance command %q (want keygen or verify)", args[0])
+	}
+}
+
+// provenanceKeygen writes a new Ed25519 key pair. The private key file is
+// only readable by its owner and is never overwritten.
+func provenanceKeygen(name string) error {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate key: %w", err)
+	}
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return fmt.Errorf("failed to encode private key: %w", err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return fmt.Errorf("failed to encode public key: %w", err)
+	}
+	f, err := os.OpenFile(name+".key", os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create private key file: %w", err)
+	}
+	err = pem.Encode(f, &pem.Block{Type: "PRIVATE KEY", Bytes: privDER})
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write private key: %w", err)
+	}
+	if err := os.WriteFile(name+".pub", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0o644); err != nil {
+		return fmt.Errorf("failed to write public key: %w", err)
+	}
+	fmt.Printf("Wrote %s.key (keep secret) and %s.pub\n", name, name)
+	return nil
+}
+
+// errProvenanceMismatch is returned when an artifact doesn't match its
+// provenance.
+var errProvenanceMismatch = errors.New("verification failed")
+
+// verifyProvenance checks an artifact against its sidecar and, given a
+// public key, the sidecar's signature.
+func verifyProvenance(path, pubPath string) error {
+	doc, err := os.ReadFile(path + provenanceSuffix)
+	if err != nil {
+		return fmt.Errorf("failed to read provenance: %w", err)
+	}
+	var prov Provenance
+	if err := json.Unmarshal(doc, &prov); err != nil {
+		return fmt.Errorf("failed to parse provenance: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read artifact: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != prov.Artifact.SHA256 {
+		return fmt.Errorf("%w: %s was modified after it was written", errProvenanceMismatch, path)
+	}
+	if pubPath != "" {
+		pub, err := loadVerifyKey(pubPath)
+		if err != nil {
+			return err
+		}
+		sigText, err := os.ReadFile(path + provenanceSuffix + signatureSuffix)
+		if err != nil {
+			return fmt.Errorf("failed to read signature: %w", err)
+		}
+		sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sigText)))
+		if err != nil || !ed25519.Verify(pub, doc, sig) {
+			return fmt.Errorf("%w: bad signature on %s%s", errProvenanceMismatch, path, provenanceSuffix)
+		}
+	}
+	fmt.Printf("%s: OK (query %q on %s, %s)\n", path, prov.Query, strings.Join(prov.Providers, ", "), prov.FinishedAt.Format(time.RFC3339))
+	if pubPath != "" {
+		fmt.Println("Signature: OK")
+	}
+	return nil
+}
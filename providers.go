@@ -0,0 +1,282 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/suntong/rexplorer/rexplorertest"
+)
+
+// --- Provider Registry ---
+
+// providerInfo describes how to construct a searcher for one service.
+type providerInfo struct {
+	// Name is the value accepted by the -service flag.
+	Name string
+	// TokenEnv is the environment variable holding the provider token.
+	TokenEnv string
+	// TokenRequired makes a missing token an error rather than a warning.
+	TokenRequired bool
+	// MissingToken is the message shown when TokenEnv is empty.
+	MissingToken string
+	// WebHost is where the service's repositories are browsed, for
+	// recognizing repository links.
+	WebHost string
+	// Runs is the built-in service a configured instance runs; empty for
+	// the built-in services themselves.
+	Runs string
+	// Alternate marks a second backend for a service listed before it. It
+	// is searched only when named, never by -service=all, which would
+	// otherwise crawl the service twice and return every result twice.
+	Alternate bool
+	New       func(token string, client *http.Client) searcherTemplate
+}
+
+// providers lists every supported service, in the order shown to users.
+var providers = []providerInfo{
+	{
+		Name:         "github",
+		WebHost:      "github.com",
+		TokenEnv:     "GITHUB_TOKEN", // Optional, but higher rate limits
+		MissingToken: "GITHUB_TOKEN not set. Using unauthenticated requests (low rate limit).",
+		New:          func(t string, c *http.Client) searcherTemplate { return NewGitHubSearcher(t, c) },
+	},
+	{
+		// Same service through GraphQL: languages and topics in one query, cost-aware paging.
+		Name:          "github-graphql",
+		Alternate:     true,
+		TokenEnv:      "GITHUB_TOKEN",
+		TokenRequired: true, // GraphQL has no unauthenticated access
+		MissingToken:  "GITHUB_TOKEN environment variable not set. GitHub's GraphQL API needs a token.",
+		New:           func(t string, c *http.Client) searcherTemplate { return NewGitHubGraphQLSearcher(t, c) },
+	},
+	{
+		Name:         "gitlab",
+		WebHost:      "gitlab.com",
+		TokenEnv:     "GITLAB_TOKEN",
+		MissingToken: "GITLAB_TOKEN not set. Using unauthenticated requests.",
+		New:          func(t string, c *http.Client) searcherTemplate { return NewGitLabSearcher(t, c) },
+	},
+	{
+		// Same service through GraphQL: reports languages and totals, pages by cursor.
+		Name:         "gitlab-graphql",
+		Alternate:    true,
+		TokenEnv:     "GITLAB_TOKEN",
+		MissingToken: "GITLAB_TOKEN not set. Using unauthenticated requests.",
+		New:          func(t string, c *http.Client) searcherTemplate { return NewGitLabGraphQLSearcher(t, c) },
+	},
+	{
+		// Useless!! The authenticated call will only search repos where you have an explicit role (member, contributor, admin, or owner)!
+		Name:          "bitbucket",
+		WebHost:       "bitbucket.org",
+		TokenEnv:      "BITBUCKET_TOKEN",
+		TokenRequired: true,
+		MissingToken:  "BITBUCKET_TOKEN environment variable not set. Expected format is 'username:app_password'.",
+		New:           func(t string, c *http.Client) searcherTemplate { return NewBitbucketSearcher(t, c) },
+	},
+	{
+		Name:          "gitcode",
+		WebHost:       "gitcode.com",
+		TokenEnv:      "GITCODE_TOKEN",
+		TokenRequired: true,
+		MissingToken:  "GITCODE_TOKEN environment variable not set.",
+		New:           func(t string, c *http.Client) searcherTemplate { return NewGitCodeSearcher(t, c) },
+	},
+	{
+		Name:          "gitee",
+		WebHost:       "gitee.com",
+		TokenEnv:      "GITEE_TOKEN",
+		TokenRequired: true,
+		MissingToken:  "GITEE_TOKEN environment variable not set.",
+		New:           func(t string, c *http.Client) searcherTemplate { return NewGiteeSearcher(t, c) },
+	},
+	{
+		Name:         "codeberg",
+		WebHost:      "codeberg.org",
+		TokenEnv:     "CODEBERG_TOKEN", // Optional; only needed for private repositories
+		MissingToken: "CODEBERG_TOKEN not set. Using unauthenticated requests.",
+		New:          func(t string, c *http.Client) searcherTemplate { return NewCodebergSearcher(t, c) },
+	},
+}
+
+// providerNames returns the services -service=all searches: every
+// built-in service but the alternate backends.
+func providerNames() []string {
+	var names []string
+	for _, p := range providers {
+		if !p.Alternate {
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
+
+// serviceNames returns every built-in service name, for use in messages.
+func serviceNames() []string {
+	names := make([]string, len(providers))
+	for i, p := range providers {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// providerForHost finds the provider whose web host is host, ignoring a
+// leading "www.". Configured instances are checked after the built-in
+// services.
+func providerForHost(host string) (providerInfo, bool) {
+	host = strings.TrimPrefix(strings.ToLower(host), "www.")
+	for _, p := range providers {
+		if p.WebHost == host {
+			return p, true
+		}
+	}
+	for _, name := range instanceNames() {
+		if p, ok := lookupInstance(name); ok && p.WebHost == host {
+			return p, true
+		}
+	}
+	return providerInfo{}, false
+}
+
+// lookupProvider finds a provider by its -service name (case-insensitive):
+// a built-in service or an instance from the config file.
+func lookupProvider(name string) (providerInfo, bool) {
+	if p, ok := builtinProvider(name); ok {
+		return p, true
+	}
+	return lookupInstance(name)
+}
+
+// builtinProvider finds a built-in provider by name (case-insensitive).
+func builtinProvider(name string) (providerInfo, bool) {
+	for _, p := range providers {
+		if strings.EqualFold(p.Name, name) {
+			return p, true
+		}
+	}
+	return providerInfo{}, false
+}
+
+// newSearcher builds the searcher for a -service value, reading its token
+// from the environment. A missing required token is an error unless
+// allowMissingToken is set (e.g. for a dry run, which never sends requests).
+func newSearcher(service string, client *http.Client, allowMissingToken bool) (searcherTemplate, error) {
+	if dir, ok := strings.CutPrefix(service, "fixture:"); ok {
+		return newFixtureSearcher(dir, client)
+	}
+
+	if group, ok := strings.CutPrefix(service, "@"); ok {
+		return nil, fmt.Errorf("unknown provider group %q; define it under provider_groups in the config file", group)
+	}
+	p, ok := lookupProvider(service)
+	if !ok {
+		return nil, fmt.Errorf("unknown service: %s. Must be one of %s, or fixture:<dir>",
+			service, strings.Join(append(serviceNames(), instanceNames()...), ", "))
+	}
+	return newSearcherWithToken(p, os.Getenv(p.TokenEnv), p.TokenEnv, client, allowMissingToken)
+}
+
+// newSearcherWithToken builds a provider's searcher with the given token,
+// which was read from tokenEnv.
+func newSearcherWithToken(p providerInfo, token, tokenEnv string, client *http.Client, allowMissingToken bool) (searcherTemplate, error) {
+	registerSecret(token)
+	if token == "" {
+		if p.TokenRequired && !allowMissingToken {
+			return nil, fmt.Errorf("%s", p.MissingToken)
+		}
+		if tokenEnv == p.TokenEnv {
+			log.Println("Warning: " + p.MissingToken)
+		}
+	}
+	searcher := p.New(token, client)
+	if b, ok := baseOf(searcher); ok && token == "" {
+		b.warn("%s is not set; requests are unauthenticated and more tightly rate limited", tokenEnv)
+	}
+	return searcher, nil
+}
+
+// baseOf returns the BaseRepoSearcher behind a searcher, if it has one.
+func baseOf(s searcherTemplate) (*BaseRepoSearcher, bool) {
+	b, ok := s.(interface{ base() *BaseRepoSearcher })
+	if !ok {
+		return nil, false
+	}
+	return b.base(), true
+}
+
+// base exposes the embedded searcher through the concrete provider types.
+func (s *BaseRepoSearcher) base() *BaseRepoSearcher { return s }
+
+// fixtureToken stands in for the real token during replay, so providers that
+// put the token in the URL produce the same (redacted) URLs as when recording.
+const fixtureToken = "fixture:replay" // Also valid as a "user:password" token
+
+// newFixtureSearcher serves fixtures recorded with -record-fixtures through
+// the normal parsing pipeline. The provider is the one whose search endpoint
+// was recorded or, failing that, the first whose API base URL is on the
+// host of the recorded requests (several services can share a host).
+func newFixtureSearcher(dir string, client *http.Client) (searcherTemplate, error) {
+	endpoints, host, err := fixtureEndpoints(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	replay := &http.Client{Timeout: client.Timeout, Transport: &rexplorertest.ReplayTransport{Dir: dir, RedactURL: redactURL}}
+	var fallback searcherTemplate
+	for _, p := range providers {
+		searcher := p.New(fixtureToken, replay)
+		b, ok := baseOf(searcher)
+		if !ok {
+			continue
+		}
+		if search, err := b.implementation.buildSearchURL("fixture", 1, 1); err == nil {
+			if u, err := url.Parse(search); err == nil && endpoints[u.Host+u.Path] {
+				log.Printf("Replaying %s fixtures from %s", b.Source, dir)
+				return searcher, nil
+			}
+		}
+		if u, err := url.Parse(b.BaseURL); err == nil && u.Hostname() == host && fallback == nil {
+			fallback = searcher
+		}
+	}
+	if fallback != nil {
+		b, _ := baseOf(fallback)
+		log.Printf("Replaying %s fixtures from %s", b.Source, dir)
+		return fallback, nil
+	}
+	return nil, fmt.Errorf("fixtures in %s were recorded against %s, which is not a known provider", dir, host)
+}
+
+// fixtureEndpoints returns the host and path of every fixture in dir, and
+// the host of the first one.
+func fixtureEndpoints(dir string) (map[string]bool, string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list fixtures: %w", err)
+	}
+	if len(matches) == 0 {
+		return nil, "", fmt.Errorf("no fixtures found in %s", dir)
+	}
+	endpoints := make(map[string]bool, len(matches))
+	var host string
+	for i, path := range matches {
+		fx, err := rexplorertest.ReadFixture(path)
+		if err != nil {
+			return nil, "", err
+		}
+		u, err := url.Parse(fx.URL)
+		if err != nil {
+			return nil, "", fmt.Errorf("fixture %s has an invalid URL: %w", path, err)
+		}
+		if i == 0 {
+			host = u.Hostname()
+		}
+		endpoints[u.Host+u.Path] = true
+	}
+	return endpoints, host, nil
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// --- Retention ---
+//
+// Scheduled and daemon runs add a history record and, with -out-dir, a run
+// folder every time, and imports add result snapshots. `rexplorer prune`
+// deletes what is older than a given age, and the retention section of the
+// config file does the same after every successful search. The response cache needs no pruning: it lives in
+// memory and its entries expire with -cache-ttl.
+
+// RetentionConfig is how long local data is kept. Ages use parseAge units,
+// e.g. "90d"; an empty age keeps that kind of data forever.
+type RetentionConfig struct {
+	// History bounds the run history used by -since-last-run, and the
+	// result snapshots kept by import.
+	History string `json:"history,omitempty"`
+	// Outputs bounds the run folders under -out-dir (or OutDir).
+	Outputs string `json:"outputs,omitempty"`
+	// OutDir is the -out-dir to prune when the flag isn't given.
+	OutDir string `json:"out_dir,omitempty"`
+}
+
+// validate checks the ages.
+func (r RetentionConfig) validate() error {
+	for field, value := range map[string]string{"history": r.History, "outputs": r.Outputs} {
+		if value == "" {
+			continue
+		}
+		if _, err := parseAge(value); err != nil {
+			return fmt.Errorf("retention.%s: %w", field, err)
+		}
+	}
+	return nil
+}
+
+// pruneReport counts what a prune removed (or would remove).
+type pruneReport struct {
+	History   int
+	Snapshots int
+	RunDirs   []string
+}
+
+// pruneHistory removes history records of runs that started before cutoff.
+func pruneHistory(cutoff time.Time, dryRun bool) (int, error) {
+	history, err := loadHistory()
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for key, rec := range history {
+		if rec.RanAt.Before(cutoff) {
+			delete(history, key)
+			removed++
+		}
+	}
+	if removed == 0 || dryRun {
+		return removed, nil
+	}
+	return removed, writeStoreJSON(historyFile, history)
+}
+
+// pruneSnapshots removes the result snapshots of runs that started before
+// cutoff, and the search directories left empty.
+func pruneSnapshots(cutoff time.Time, dryRun bool) (int, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return 0, err
+	}
+	searches, err := filepath.Glob(filepath.Join(dir, snapshotsDir, "*"))
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, search := range searches {
+		files, err := filepath.Glob(filepath.Join(search, "*.json"))
+		if err != nil {
+			return removed, err
+		}
+		kept := len(files)
+		for _, file := range files {
+			ranAt, ok := runDirTime(strings.TrimSuffix(filepath.Base(file), ".json"))
+			if !ok || !ranAt.Before(cutoff) {
+				continue
+			}
+			if !dryRun {
+				if err := os.Remove(file); err != nil {
+					return removed, fmt.Errorf("failed to remove %s: %w", file, err)
+				}
+			}
+			removed++
+			kept--
+		}
+		if kept == 0 && !dryRun {
+			os.Remove(search) // Only succeeds if nothing else is in it
+		}
+	}
+	return removed, nil
+}
+
+// pruneRunDirs removes the run folders under base that were started before
+// cutoff, judging by the timestamp in their names (see newRunDir). The
+// folder "latest" points at is always kept, as is anything that isn't a run
+// folder.
+func pruneRunDirs(base string, cutoff time.Time, dryRun bool) ([]string, error) {
+	entries, err := os.ReadDir(base)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read output directory: %w", err)
+	}
+	latest, _ := os.Readlink(filepath.Join(base, latestLink))
+	var removed []string
+	for _, e := range entries {
+		if !e.IsDir() || e.Name() == filepath.Base(latest) {
+			continue
+		}
+		started, ok := runDirTime(e.Name())
+		if !ok {
+			continue
+		}
+		if !started.Before(cutoff) {
+			continue
+		}
+		dir := filepath.Join(base, e.Name())
+		if !dryRun {
+			if err := os.RemoveAll(dir); err != nil {
+				return removed, fmt.Errorf("failed to remove %s: %w", dir, err)
+			}
+		}
+		removed = append(removed, dir)
+	}
+	return removed, nil
+}
+
+// runDirTime parses the start time from a run folder name, ignoring the
+// suffix newRunDir adds for runs in the same second.
+func runDirTime(name string) (time.Time, bool) {
+	stamp, _, _ := strings.Cut(name, "-")
+	t, err := time.Parse(runDirLayout, stamp)
+	return t, err == nil
+}
+
+// prune removes history and snapshots older than historyAge, and run
+// folders under outDir older than outputsAge. A zero age skips that kind of data.
+func prune(historyAge, outputsAge time.Duration, outDir string, now time.Time, dryRun bool) (pruneReport, error) {
+	var report pruneReport
+	var err error
+	if historyAge > 0 {
+		if report.History, err = pruneHistory(now.Add(-historyAge), dryRun); err != nil {
+			return report, err
+		}
+		if report.Snapshots, err = pruneSnapshots(now.Add(-historyAge), dryRun); err != nil {
+			return report, err
+		}
+	}
+	if outputsAge > 0 && outDir != "" {
+		if report.RunDirs, err = pruneRunDirs(outDir, now.Add(-outputsAge), dryRun); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// applyRetention prunes according to the config file after a search. Errors
+// are only logged: retention must never fail a run that succeeded.
+func applyRetention(r RetentionConfig, outDir string) {
+	historyAge, _ := parseAge(r.History)
+	outputsAge, _ := parseAge(r.Outputs)
+	report, err := prune(historyAge, outputsAge, cmp.Or(outDir, r.OutDir), time.Now(), false)
+	if err != nil {
+		log.Printf("Warning: retention: %v", err)
+	}
+	if report.History > 0 || report.Snapshots > 0 || len(report.RunDirs) > 0 {
+		log.Printf("Retention removed %d history records, %d snapshots and %d run folders", report.History, report.Snapshots, len(report.RunDirs))
+	}
+}
+
+// runPrune implements `rexplorer prune`.
+func runPrune(args []string) error {
+	fs := flag.NewFlagSet("prune", flag.ExitOnError)
+	olderThan := fs.String("older-than", "", "Remove data older than this age, e.g. 90d (default: the config file's retention ages)")
+	outDir := fs.String("out-dir", "", "Also remove run folders under this -out-dir (default: the config file's retention.out_dir)")
+	configFile := fs.String("config", "", "Configuration file (default: ~/.config/rexplorer/config.json)")
+	dryRun := fs.Bool("dry-run", false, "List what would be removed without removing it")
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		return fmt.Errorf("usage: rexplorer prune [-older-than age] [-out-dir dir] [-dry-run]")
+	}
+
+	cfg, err := loadConfig(*configFile)
+	if err != nil {
+		return err
+	}
+	historyAge, _ := parseAge(cfg.Retention.History)
+	outputsAge, _ := parseAge(cfg.Retention.Outputs)
+	if *olderThan != "" {
+		age, err := parseAge(*olderThan)
+		if err != nil {
+			return err
+		}
+		historyAge, outputsAge = age, age
+	}
+	if historyAge == 0 && outputsAge == 0 {
+		return fmt.Errorf("nothing to prune: pass -older-than or set retention in the config file")
+	}
+
+	report, err := prune(historyAge, outputsAge, cmp.Or(*outDir, cfg.Retention.OutDir), time.Now(), *dryRun)
+	verb := "Removed"
+	if *dryRun {
+		verb = "Would remove"
+	}
+	for _, dir := range report.RunDirs {
+		fmt.Printf("%s %s\n", verb, dir)
+	}
+	fmt.Printf("%s %d history records, %d snapshots and %d run folders\n", verb, report.History, report.Snapshots, len(report.RunDirs))
+	return err
+}
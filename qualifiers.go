@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// --- GitHub Search Qualifiers ---
+//
+// GitHub narrows a search with qualifiers inside q (user:x, in:readme, ...).
+// The -in, -user, -org, -is and -pushed-after flags build them, so users
+// don't need the syntax and a value can't break out of its qualifier.
+
+// gitHubInFields are the accepted -in values.
+var gitHubInFields = []string{"name", "description", "readme", "topics"}
+
+// gitHubIsValues are the accepted -is values.
+var gitHubIsValues = []string{"public", "private", "internal", "template", "sponsorable"}
+
+// gitHubLogin matches a GitHub user or organization name.
+var gitHubLogin = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9]|-[A-Za-z0-9]){0,38}$`)
+
+// gitHubQualifierFlags are the qualifier flags, unvalidated.
+type gitHubQualifierFlags struct {
+	In          string // Comma-separated gitHubInFields
+	User        string // Comma-separated logins
+	Org         string // Comma-separated logins
+	Is          string // Comma-separated gitHubIsValues
+	PushedAfter string // A date (2024-01-31) or an age (90d)
+}
+
+// terms validates the flags and returns them as qualifiers, in a fixed
+// order. now anchors -pushed-after ages.
+func (f gitHubQualifierFlags) terms(now time.Time) ([]string, error) {
+	var terms []string
+	if fields := splitList(strings.ToLower(f.In)); len(fields) > 0 {
+		for _, field := range fields {
+			if !slices.Contains(gitHubInFields, field) {
+				return nil, fmt.Errorf("unknown -in field %q; must be one of %s", field, strings.Join(gitHubInFields, ", "))
+			}
+		}
+		terms = append(terms, "in:"+strings.Join(fields, ","))
+	}
+	for _, q := range []struct{ name, list string }{{"user", f.User}, {"org", f.Org}} {
+		for _, login := range splitList(q.list) {
+			if !gitHubLogin.MatchString(login) {
+				return nil, fmt.Errorf("invalid -%s %q: not a GitHub login", q.name, login)
+			}
+			terms = append(terms, q.name+":"+login)
+		}
+	}
+	for _, value := range splitList(strings.ToLower(f.Is)) {
+		if !slices.Contains(gitHubIsValues, value) {
+			return nil, fmt.Errorf("unknown -is value %q; must be one of %s", value, strings.Join(gitHubIsValues, ", "))
+		}
+		terms = append(terms, "is:"+value)
+	}
+	if f.PushedAfter != "" {
+		after, err := time.Parse("2006-01-02", f.PushedAfter)
+		if err != nil {
+			age, ageErr := parseAge(f.PushedAfter)
+			if ageErr != nil {
+				return nil, fmt.Errorf("invalid -pushed-after %q: expected a date (2006-01-02) or an age (e.g. 90d)", f.PushedAfter)
+			}
+			after = now.Add(-age)
+		}
+		terms = append(terms, "pushed:>"+after.UTC().Format("2006-01-02"))
+	}
+	return terms, nil
+}
+
+// withQualifiers appends qualifiers to query.
+func withQualifiers(query string, qualifiers []string) string {
+	if len(qualifiers) == 0 {
+		return query
+	}
+	return query + " " + strings.Join(qualifiers, " ")
+}
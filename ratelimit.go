@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// --- Special Error Responses ---
+
+// defaultCoolDown is how long to wait after a secondary rate limit when the
+// response doesn't say. GitHub documents "at least one minute".
+const defaultCoolDown = time.Minute
+
+// secondaryRateLimit reports whether a response is a secondary (abuse)
+// rate limit, which GitHub sends as 403 or 429 with an explanatory body,
+// and how long to cool down before retrying: Retry-After if present, then
+// X-RateLimit-Reset when no requests remain, otherwise defaultCoolDown.
+func secondaryRateLimit(resp *http.Response, body []byte, now time.Time) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if !strings.Contains(strings.ToLower(string(body)), "secondary rate limit") {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			if d := time.Unix(reset, 0).Sub(now); d > 0 {
+				return d, true
+			}
+		}
+	}
+	return defaultCoolDown, true
+}
+
+// rateLimitRemaining returns the requests left in the provider's rate limit
+// window, from GitHub's X-RateLimit-Remaining or GitLab's
+// RateLimit-Remaining, or -1 if the response doesn't say.
+func rateLimitRemaining(h http.Header) int {
+	for _, name := range []string{"X-RateLimit-Remaining", "RateLimit-Remaining"} {
+		if n, err := strconv.Atoi(h.Get(name)); err == nil && n >= 0 {
+			return n
+		}
+	}
+	return -1
+}
+
+// unprocessableError turns a 422 response into an actionable error. GitHub
+// uses 422 both for malformed queries and for pages past its 1000-result
+// cap; neither gets better by retrying.
+func unprocessableError(source string, body []byte) error {
+	var resp struct {
+		Message string `json:"message"`
+		Errors  []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	details := strings.TrimSpace(string(body))
+	if json.Unmarshal(body, &resp) == nil && resp.Message != "" {
+		parts := []string{resp.Message}
+		for _, e := range resp.Errors {
+			if e.Message != "" {
+				parts = append(parts, e.Message)
+			}
+		}
+		details = strings.Join(parts, ": ")
+	}
+	details = redactText(details)
+
+	if strings.Contains(details, "first 1000 search results") {
+		return fmt.Errorf("%s only returns the first 1000 results (%s); lower -pages or use -slice-by-date", source, details)
+	}
+	return fmt.Errorf("%s rejected the query (status 422: %s); check the query syntax", source, details)
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// --- Raw Response Archive ---
+//
+// `-save-raw dir/` keeps every search page exactly as the provider sent it,
+// so the results can be parsed again by a later version of the tool (see
+// reparse.go) without spending the rate limit twice. Pages are stored per
+// service and query:
+//
+//	dir/gitlab/kubernetes-operator-1f2e3d4c/page-0001.json.gz
+//	dir/gitlab/kubernetes-operator-1f2e3d4c/pages.json
+//
+// pages.json records the service, the query and, for each page, the
+// request URL and response headers (credentials redacted), since some
+// providers report totals in headers rather than in the body.
+
+// rawIndexFile lists the pages archived for one service and query.
+const rawIndexFile = "pages.json"
+
+// rawIndex is the content of rawIndexFile.
+type rawIndex struct {
+	Service string    `json:"service"`
+	Query   string    `json:"query"`
+	Pages   []rawPage `json:"pages"`
+}
+
+// rawPage is one archived page.
+type rawPage struct {
+	Page      int         `json:"page"`
+	File      string      `json:"file"`
+	URL       string      `json:"url"`
+	Header    http.Header `json:"header,omitempty"`
+	FetchedAt time.Time   `json:"fetched_at"`
+}
+
+// rawArchive saves one service's search pages under Dir.
+type rawArchive struct {
+	Dir     string
+	Service string
+
+	mu sync.Mutex
+}
+
+// archivedService is the service name pages are archived under: the
+// -service name, or for replayed fixtures the provider that parses them.
+func archivedService(name string, searcher searcherTemplate) string {
+	if !strings.HasPrefix(name, "fixture:") {
+		return strings.ToLower(name)
+	}
+	for _, p := range providers {
+		if reflect.TypeOf(p.New("", nil)) == reflect.TypeOf(searcher) {
+			return p.Name
+		}
+	}
+	return "fixture"
+}
+
+// queryDir is where the pages for query are kept: a readable slug of the
+// query, made unique by a hash of it.
+func (a *rawArchive) queryDir(query string) string {
+	slug := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return '-'
+	}, query)
+	slug = strings.Trim(slug, "-")
+	for strings.Contains(slug, "--") {
+		slug = strings.ReplaceAll(slug, "--", "-")
+	}
+	if len(slug) > 40 {
+		slug = strings.TrimRight(slug[:40], "-")
+	}
+	sum := sha256.Sum256([]byte(query))
+	name := hex.EncodeToString(sum[:4])
+	if slug != "" {
+		name = slug + "-" + name
+	}
+	return filepath.Join(a.Dir, a.Service, name)
+}
+
+// save stores a page's body, gzipped, and records it in the index. A page
+// saved again (e.g. by a second run) replaces the earlier copy.
+func (a *rawArchive) save(query string, page int, url string, header http.Header, body []byte) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	dir := a.queryDir(query)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create raw archive directory: %w", err)
+	}
+	name := fmt.Sprintf("page-%04d.json.gz", page)
+	if err := writeGzip(filepath.Join(dir, name), body); err != nil {
+		return err
+	}
+
+	index, err := readRawIndex(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		index, err = &rawIndex{Service: a.Service, Query: query}, nil
+	}
+	if err != nil {
+		return err
+	}
+	entry := rawPage{Page: page, File: name, URL: redactURL(url), Header: redactHeaders(header), FetchedAt: time.Now().UTC()}
+	replaced := false
+	for i := range index.Pages {
+		if index.Pages[i].Page == page {
+			index.Pages[i], replaced = entry, true
+		}
+	}
+	if !replaced {
+		index.Pages = append(index.Pages, entry)
+	}
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal raw archive index: %w", err)
+	}
+	path := filepath.Join(dir, rawIndexFile)
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
+
+// writeGzip writes data to path, gzipped.
+func writeGzip(path string, data []byte) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	zw := gzip.NewWriter(f)
+	if _, err := zw.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := zw.Close(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// readRawIndex reads the index in dir.
+func readRawIndex(dir string) (*rawIndex, error) {
+	data, err := os.ReadFile(filepath.Join(dir, rawIndexFile))
+	if err != nil {
+		return nil, err
+	}
+	var index rawIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Join(dir, rawIndexFile), err)
+	}
+	return &index, nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// --- Credential Redaction ---
+//
+// Tokens must never reach the log, error messages or any dump of requests.
+// URLs are sanitized where they are printed, and as a last line of defence
+// the log output itself is filtered for known token values and credential
+// query parameters.
+
+// sensitiveHeaders are never shown verbatim.
+var sensitiveHeaders = map[string]bool{
+	"Authorization": true,
+	"Private-Token": true,
+}
+
+// sensitiveParams are query parameters that carry credentials.
+var sensitiveParams = []string{"access_token", "private_token", "token"}
+
+// sensitiveParamPattern finds credential query parameters in free text.
+var sensitiveParamPattern = regexp.MustCompile(`(?i)\b(access_token|private_token|token)=[^&\s"']+`)
+
+// redactHeaders returns a copy of h with credential-bearing values masked.
+func redactHeaders(h http.Header) http.Header {
+	out := h.Clone()
+	for name := range out {
+		if !sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+			continue
+		}
+		for i, v := range out[name] {
+			// Keep the scheme (e.g. "Bearer", "Basic") so the auth type is visible.
+			if scheme, _, ok := strings.Cut(v, " "); ok {
+				out[name][i] = scheme + " REDACTED"
+			} else {
+				out[name][i] = "REDACTED"
+			}
+		}
+	}
+	return out
+}
+
+// redactURL masks credential-bearing query parameters in raw.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return redactText(raw)
+	}
+	q := u.Query()
+	changed := false
+	for _, p := range sensitiveParams {
+		if q.Has(p) {
+			q.Set(p, "REDACTED")
+			changed = true
+		}
+	}
+	if u.User != nil {
+		u.User = url.User("REDACTED")
+		changed = true
+	}
+	if !changed {
+		return raw
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// secrets holds every token in use, so they can be masked wherever they appear.
+var secrets struct {
+	sync.RWMutex
+	values []string
+}
+
+// registerSecret adds a token to the set masked by redactText.
+func registerSecret(token string) {
+	if len(token) < 4 {
+		return // Too short to mask without mangling unrelated text
+	}
+	secrets.Lock()
+	defer secrets.Unlock()
+	secrets.values = append(secrets.values, token)
+	// Bitbucket tokens are "user:password"; the password alone is also secret.
+	if _, pass, ok := strings.Cut(token, ":"); ok && len(pass) >= 4 {
+		secrets.values = append(secrets.values, pass)
+	}
+}
+
+// redactText masks registered tokens and credential query parameters in s.
+func redactText(s string) string {
+	s = sensitiveParamPattern.ReplaceAllString(s, "${1}=REDACTED")
+	secrets.RLock()
+	defer secrets.RUnlock()
+	for _, v := range secrets.values {
+		s = strings.ReplaceAll(s, v, "REDACTED")
+	}
+	return s
+}
+
+// redactError scrubs credentials from an error's message. The URL inside a
+// *url.Error is sanitized in place so the original error chain still works
+// with errors.Is and errors.As.
+func redactError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = redactURL(urlErr.URL)
+	}
+	if msg := err.Error(); redactText(msg) != msg {
+		return &redactedError{err: err}
+	}
+	return err
+}
+
+// redactedError wraps an error whose message still contains a credential.
+type redactedError struct {
+	err error
+}
+
+func (e *redactedError) Error() string { return redactText(e.err.Error()) }
+func (e *redactedError) Unwrap() error { return e.err }
+
+// redactingWriter masks credentials in everything written through it.
+// It is installed as the log output in main.
+type redactingWriter struct {
+	w io.Writer
+}
+
+// Write implements io.Writer. It reports len(p) on success even though the
+// redacted text may differ in length.
+func (r redactingWriter) Write(p []byte) (int, error) {
+	clean := redactText(string(p))
+	if _, err := io.Copy(r.w, bytes.NewReader([]byte(clean))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// --- Refine: Interactive Filtering of Saved Results ---
+
+const refineHelp = `Commands:
+  show [n]               print the first n results (default: all)
+  count                  print the number of results
+  lang <language>        keep repos in the given language
+  topic <topic>          keep repos tagged with the given topic
+  min-stars <n>          keep repos with at least n stars
+  min-forks <n>          keep repos with at least n forks
+  grep <regex>           keep repos whose name or description matches
+  exclude <regex>        drop repos whose name or description matches
+  no-forks               drop forks
+  no-archived            drop archived repos
+  sort <key> [asc]       sort by stars, forks, issues, updated, created or name
+  head <n>               keep the first n results
+  undo                   revert the last filter or sort
+  reset                  go back to the loaded results
+  write <file>           save the current results as JSON
+  quit                   leave refine`
+
+// runRefine implements `rexplorer refine <file>`: it loads a previous JSON
+// output and lets the user narrow it down interactively, so analysis doesn't
+// need repeated API crawls.
+func runRefine(args []string) error {
+	fs := flag.NewFlagSet("refine", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: rexplorer refine <file.json>")
+		fmt.Fprintln(fs.Output(), refineHelp)
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("refine needs exactly one input file")
+	}
+
+	items, err := loadSummaries(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	r := &refiner{loaded: items, current: items, out: os.Stdout}
+	fmt.Fprintf(r.out, "Loaded %d repositories from %s. Type 'help' for commands.\n", len(items), fs.Arg(0))
+	return r.run(os.Stdin)
+}
+
+// refiner holds the state of one refine session.
+type refiner struct {
+	loaded  []RepositorySummary
+	current []RepositorySummary
+	history [][]RepositorySummary // Previous states, for undo
+	out     io.Writer
+}
+
+// run reads commands from in until EOF or quit.
+func (r *refiner) run(in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprintf(r.out, "refine (%d)> ", len(r.current))
+		if !scanner.Scan() {
+			fmt.Fprintln(r.out)
+			return scanner.Err()
+		}
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "quit" || fields[0] == "exit" {
+			return nil
+		}
+		if err := r.exec(fields[0], fields[1:]); err != nil {
+			fmt.Fprintf(r.out, "Error: %v\n", err)
+		}
+	}
+}
+
+// exec runs a single command.
+func (r *refiner) exec(cmd string, args []string) error {
+	arg := strings.Join(args, " ")
+	switch cmd {
+	case "help":
+		fmt.Fprintln(r.out, refineHelp)
+	case "show":
+		items := r.current
+		if arg != "" {
+			n, err := strconv.Atoi(arg)
+			if err != nil || n < 0 {
+				return fmt.Errorf("show: %q is not a count", arg)
+			}
+			items = items[:min(n, len(items))]
+		}
+		PrintSummary(items, "refined results")
+	case "count":
+		fmt.Fprintf(r.out, "%d repositories\n", len(r.current))
+	case "lang":
+		r.filter(func(s RepositorySummary) bool { return strings.EqualFold(s.Language, arg) })
+	case "topic":
+		r.filter(func(s RepositorySummary) bool {
+			for _, t := range s.Topics {
+				if strings.EqualFold(t, arg) {
+					return true
+				}
+			}
+			return false
+		})
+	case "min-stars", "min-forks":
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not a number", cmd, arg)
+		}
+		if cmd == "min-stars" {
+			r.filter(func(s RepositorySummary) bool { return s.Stars >= n })
+		} else {
+			r.filter(func(s RepositorySummary) bool { return s.Forks >= n })
+		}
+	case "grep", "exclude":
+		re, err := regexp.Compile("(?i)" + arg)
+		if err != nil {
+			return fmt.Errorf("%s: invalid regex: %w", cmd, err)
+		}
+		want := cmd == "grep"
+		r.filter(func(s RepositorySummary) bool {
+			return (re.MatchString(s.FullName) || re.MatchString(s.Description)) == want
+		})
+	case "no-forks":
+		r.filter(func(s RepositorySummary) bool { return !s.IsFork })
+	case "no-archived":
+		r.filter(func(s RepositorySummary) bool { return !s.IsArchived })
+	case "sort":
+		if len(args) == 0 {
+			return fmt.Errorf("sort: missing key")
+		}
+		sorted := append([]RepositorySummary(nil), r.current...)
+		if err := sortSummaries(sorted, args[0], len(args) > 1 && args[1] == "asc"); err != nil {
+			return err
+		}
+		r.push(sorted)
+	case "head":
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 0 {
+			return fmt.Errorf("head: %q is not a count", arg)
+		}
+		r.push(r.current[:min(n, len(r.current))])
+	case "undo":
+		if len(r.history) == 0 {
+			return fmt.Errorf("nothing to undo")
+		}
+		r.current = r.history[len(r.history)-1]
+		r.history = r.history[:len(r.history)-1]
+	case "reset":
+		r.push(r.loaded)
+	case "write":
+		if arg == "" {
+			return fmt.Errorf("write: missing file name")
+		}
+		if err := writeSummaries(arg, r.current); err != nil {
+			return err
+		}
+		fmt.Fprintf(r.out, "Wrote %d results to %s\n", len(r.current), arg)
+	default:
+		return fmt.Errorf("unknown command %q; type 'help' for a list", cmd)
+	}
+	return nil
+}
+
+// filter narrows the current results, remembering the previous state.
+func (r *refiner) filter(keep func(RepositorySummary) bool) {
+	r.push(filterSummaries(r.current, keep))
+}
+
+// push makes items the current state, remembering the previous one.
+func (r *refiner) push(items []RepositorySummary) {
+	r.history = append(r.history, r.current)
+	r.current = items
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// --- Release Cadence ---
+//
+// A project that releases regularly is one users can plan around. The
+// releases enricher records when the latest release came out and the
+// average interval between recent ones; -max-release-age filters on the
+// former.
+
+// releaseWindow is how many recent releases the cadence is computed from.
+const releaseWindow = 30
+
+// releasesFetcher is implemented by providers that list releases.
+type releasesFetcher interface {
+	releasesURL(fullName string) string
+	// parseReleases returns the publication times of published releases.
+	parseReleases(body io.Reader) ([]time.Time, error)
+}
+
+// enrichReleases fills in LastReleaseAt and ReleaseCadenceDays.
+func enrichReleases(ctx context.Context, s *BaseRepoSearcher, item *RepositorySummary) error {
+	rf, ok := s.implementation.(releasesFetcher)
+	if !ok {
+		return errUnsupported
+	}
+	body, err := s.fetchDetail(ctx, rf.releasesURL(item.FullName))
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	times, err := rf.parseReleases(body)
+	if err != nil {
+		return err
+	}
+	last, cadence := releaseCadence(times)
+	if !last.IsZero() {
+		item.LastReleaseAt = last.UTC().Format(time.RFC3339)
+	}
+	item.ReleaseCadenceDays = cadence
+	return nil
+}
+
+// releaseCadence returns the latest release time and the mean number of days
+// between consecutive releases (0 with fewer than two releases).
+func releaseCadence(times []time.Time) (last time.Time, cadenceDays float64) {
+	if len(times) == 0 {
+		return time.Time{}, 0
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	last = times[len(times)-1]
+	if len(times) < 2 {
+		return last, 0
+	}
+	span := last.Sub(times[0]).Hours() / 24
+	return last, math.Round(span/float64(len(times)-1)*10) / 10
+}
+
+// releasedWithin reports whether the item's latest release is younger than
+// maxAge. Items without releases never are.
+func releasedWithin(item RepositorySummary, maxAge time.Duration, now time.Time) bool {
+	last, ok := parseTimestamp(item.LastReleaseAt)
+	return ok && now.Sub(last) <= maxAge
+}
+
+// parseReleaseTimes collects the timestamps of the releases that count.
+func parseReleaseTimes(stamps []string) []time.Time {
+	times := make([]time.Time, 0, len(stamps))
+	for _, s := range stamps {
+		if t, ok := parseTimestamp(s); ok {
+			times = append(times, t)
+		}
+	}
+	return times
+}
+
+// releasesURL implements releasesFetcher.
+func (g *GitHubSearcher) releasesURL(fullName string) string {
+	return g.BaseURL + "/repos/" + fullName + fmt.Sprintf("/releases?per_page=%d", releaseWindow)
+}
+
+// parseReleases implements releasesFetcher. Drafts are not releases yet.
+func (g *GitHubSearcher) parseReleases(body io.Reader) ([]time.Time, error) {
+	var releases []struct {
+		Draft       bool   `json:"draft"`
+		PublishedAt string `json:"published_at"`
+	}
+	if err := json.NewDecoder(body).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal GitHub releases: %w", err)
+	}
+	var stamps []string
+	for _, r := range releases {
+		if !r.Draft {
+			stamps = append(stamps, r.PublishedAt)
+		}
+	}
+	return parseReleaseTimes(stamps), nil
+}
+
+// releasesURL implements releasesFetcher.
+func (g *GitLabSearcher) releasesURL(fullName string) string {
+	return g.BaseURL + "/projects/" + url.PathEscape(fullName) + fmt.Sprintf("/releases?per_page=%d", releaseWindow)
+}
+
+// parseReleases implements releasesFetcher. Upcoming releases (released_at
+// in the future) are included; they are rare and clearly intended.
+func (g *GitLabSearcher) parseReleases(body io.Reader) ([]time.Time, error) {
+	var releases []struct {
+		ReleasedAt string `json:"released_at"`
+	}
+	if err := json.NewDecoder(body).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal GitLab releases: %w", err)
+	}
+	stamps := make([]string, len(releases))
+	for i, r := range releases {
+		stamps[i] = r.ReleasedAt
+	}
+	return parseReleaseTimes(stamps), nil
+}
+
+// releasesURL implements releasesFetcher.
+func (g *GiteeSearcher) releasesURL(fullName string) string {
+	return g.BaseURL + "/repos/" + fullName + fmt.Sprintf("/releases?per_page=%d&direction=desc", releaseWindow)
+}
+
+// parseReleases implements releasesFetcher.
+func (g *GiteeSearcher) parseReleases(body io.Reader) ([]time.Time, error) {
+	var releases []struct {
+		CreatedAt string `json:"created_at"`
+	}
+	if err := json.NewDecoder(body).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal Gitee releases: %w", err)
+	}
+	stamps := make([]string, len(releases))
+	for i, r := range releases {
+		stamps[i] = r.CreatedAt
+	}
+	return parseReleaseTimes(stamps), nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// --- Relevance ---
+//
+// Every provider orders its results its own way, so merged results have no
+// meaningful order. A client-side score ranks them all against the query's
+// free-text words instead: each occurrence counts, more in the name than in
+// the topics, and more in the topics than in the description, and a
+// repository named exactly after the query gets a bonus. `-sort relevance`
+// orders by it.
+
+// Relevance weights: per occurrence of a query word in each field, and the
+// bonus for a name matching the query or one of its words exactly.
+const (
+	relevanceName        = 3.0
+	relevanceTopic       = 2.0
+	relevanceDescription = 1.0
+	relevanceExactName   = 10.0
+)
+
+// scoreRelevance sets Relevance on every item against the queries' words.
+// Items are left unscored if the queries have no free text.
+func scoreRelevance(items []RepositorySummary, queries ...string) {
+	var words []string
+	for _, word := range queryWords(queries...) {
+		if w := normalizeWords(word); w != "" {
+			words = append(words, w)
+		}
+	}
+	if len(words) == 0 {
+		return
+	}
+	whole := strings.Join(words, " ")
+	for i := range items {
+		items[i].Relevance = relevanceOf(items[i], words, whole)
+	}
+}
+
+// relevanceOf scores one item. words are normalized; whole is all of them
+// joined, for the exact-name bonus.
+func relevanceOf(s RepositorySummary, words []string, whole string) float64 {
+	name := normalizeWords(s.Name)
+	description := normalizeWords(s.Description)
+	topics := make([]string, len(s.Topics))
+	for i, t := range s.Topics {
+		topics[i] = normalizeWords(t)
+	}
+
+	score := 0.0
+	for _, word := range words {
+		score += relevanceName * float64(countWords(name, word))
+		score += relevanceDescription * float64(countWords(description, word))
+		for _, topic := range topics {
+			score += relevanceTopic * float64(countWords(topic, word))
+		}
+		if name == word {
+			score += relevanceExactName
+		}
+	}
+	if len(words) > 1 && name == whole {
+		score += relevanceExactName
+	}
+	return math.Round(score*100) / 100
+}
+
+// normalizeWords lowercases s and splits it into words at anything but
+// letters and digits, so "go-cli", "Go_CLI" and "go cli" all read "go cli".
+func normalizeWords(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// countWords counts the whole-word occurrences of word (one or more
+// normalized words) in text.
+func countWords(text, word string) int {
+	if text == "" {
+		return 0
+	}
+	fields, want := strings.Fields(text), strings.Fields(word)
+	n := 0
+	for i := 0; i+len(want) <= len(fields); i++ {
+		if slices.Equal(fields[i:i+len(want)], want) {
+			n++
+		}
+	}
+	return n
+}
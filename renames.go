@@ -0,0 +1,93 @@
+package main
+
+import (
+	"log"
+	"strings"
+	"time"
+)
+
+// --- Renamed Repositories ---
+//
+// When a repository is renamed or transferred, providers redirect the old
+// name (GitHub answers with a 301, which the HTTP client follows). fetchRepo
+// notices the name changed and sets PreviousFullName; the rename is then
+// recorded in the local store so the watch list, bookmarks and blocklist
+// keep following the repository under its new name.
+
+// renamesFile is the store document mapping old repository names to new.
+const renamesFile = "renames.json"
+
+// maxRenameChain bounds how many renames resolve follows, in case of cycles.
+const maxRenameChain = 10
+
+// renameRecord is one observed rename.
+type renameRecord struct {
+	Service string    `json:"service"`
+	From    string    `json:"from"`
+	To      string    `json:"to"`
+	SeenAt  time.Time `json:"seen_at"`
+}
+
+// renames maps nodeID(service, old name) to the rename seen for it.
+type renames map[string]renameRecord
+
+// loadRenames reads the recorded renames.
+func loadRenames() (renames, error) {
+	r := renames{}
+	if err := readStoreJSON(renamesFile, &r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// resolve returns the current name of a repository, following recorded
+// renames.
+func (r renames) resolve(service, fullName string) string {
+	for range maxRenameChain {
+		rec, ok := r[nodeID(strings.ToLower(service), fullName)]
+		if !ok {
+			break
+		}
+		fullName = rec.To
+	}
+	return fullName
+}
+
+// targets returns every current name a repository was renamed to on any
+// service, for lookups that don't know the service.
+func (r renames) targets(fullName string) []string {
+	var out []string
+	for _, rec := range r {
+		if strings.EqualFold(rec.From, fullName) {
+			out = append(out, r.resolve(rec.Service, rec.From))
+		}
+	}
+	return out
+}
+
+// recordRenames adds renames to the store.
+func recordRenames(recs ...renameRecord) error {
+	if len(recs) == 0 {
+		return nil
+	}
+	r := renames{}
+	return updateStoreJSON(renamesFile, &r, func() error {
+		for _, rec := range recs {
+			r[nodeID(strings.ToLower(rec.Service), rec.From)] = rec
+		}
+		return nil
+	})
+}
+
+// noteRename records that a repository looked up as from turned out to be
+// renamed, logging instead of failing: losing the record only costs a
+// redirect next time.
+func noteRename(service, from string, summary RepositorySummary, now time.Time) {
+	if summary.PreviousFullName == "" {
+		return
+	}
+	log.Printf("%s was renamed to %s", from, summary.FullName)
+	if err := recordRenames(renameRecord{Service: service, From: from, To: summary.FullName, SeenAt: now}); err != nil {
+		log.Printf("Warning: failed to record rename of %s: %v", from, err)
+	}
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// --- Rendering ---
+
+// renderOptions control how results are presented on stdout.
+type renderOptions struct {
+	// GroupBy is one of the groupKeys, or empty for a flat list.
+	GroupBy string
+	// CompactNumbers abbreviates large counts (12.3k) instead of grouping digits.
+	CompactNumbers bool
+	// Fields, if set, switch the text format to one row per repository
+	// showing just these columns (-fields).
+	Fields []displayField
+	// Clean strips markup from descriptions before they are shown.
+	Clean descriptionCleaner
+	// Highlight marks the query's words in names and descriptions; nil
+	// when color is off.
+	Highlight *highlighter
+}
+
+// outputFormats are the accepted -format values.
+var outputFormats = []string{"text", "markdown", "html", "fragment"}
+
+// PrintSummary prints repository summaries in a readable format
+func PrintSummary(summaries []RepositorySummary, source string) {
+	writeText(os.Stdout, summaries, source, renderOptions{})
+}
+
+// render writes the results to w in the given format.
+func render(w io.Writer, format string, summaries []RepositorySummary, source string, opts renderOptions) error {
+	summaries = opts.Clean.apply(summaries)
+	switch format {
+	case "text", "table", "":
+		writeText(w, summaries, source, opts)
+		return nil
+	case "markdown", "md":
+		writeMarkdown(w, summaries, source, opts)
+		return nil
+	case "html":
+		return writeHTML(w, summaries, source, opts)
+	case "fragment":
+		return writeHTMLFragment(w, summaries, source, opts)
+	default:
+		return fmt.Errorf("unknown format %q; must be one of %s", format, strings.Join(outputFormats, ", "))
+	}
+}
+
+// writeText renders the classic console listing.
+func writeText(w io.Writer, summaries []RepositorySummary, source string, opts renderOptions) {
+	if len(summaries) == 0 {
+		fmt.Fprintln(w, "No repositories found.")
+		return
+	}
+
+	fmt.Fprintf(w, "Found %d repositories from %s:\n\n", len(summaries), source)
+	for _, group := range groupSummaries(summaries, opts.GroupBy) {
+		if group.Name != "" {
+			fmt.Fprintf(w, "=== %s (%d) ===\n\n", group.Name, len(group.Items))
+		}
+		if len(opts.Fields) > 0 {
+			writeColumns(w, group.Items, opts)
+			fmt.Fprintln(w)
+			continue
+		}
+		for i, summary := range group.Items {
+			fmt.Fprintf(w, "%d. %s\n", i+1, opts.Highlight.mark(summary.FullName))
+			fmt.Fprintf(w, "   URL: %s\n", summary.URL)
+			fmt.Fprintf(w, "   Description: %s\n", opts.Highlight.mark(summary.Description))
+			fmt.Fprintf(w, "   Language: %s | Stars: %s | Forks: %s\n", summary.Language,
+				formatCount(summary.Stars, opts.CompactNumbers), formatCount(summary.Forks, opts.CompactNumbers))
+			fmt.Fprintf(w, "   Created: %s | Updated: %s\n", summary.CreatedAt, summary.UpdatedAt)
+			if len(summary.Topics) > 0 {
+				fmt.Fprintf(w, "   Topics: %s\n", strings.Join(summary.Topics, ", "))
+			}
+			if summary.PreviousFullName != "" {
+				fmt.Fprintf(w, "   Renamed from: %s\n", summary.PreviousFullName)
+			}
+			if summary.UpstreamURL != "" {
+				fmt.Fprintf(w, "   Fork of: %s (upstream %s)\n", summary.ParentFullName, summary.UpstreamURL)
+			}
+			if summary.SuccessorURL != "" {
+				fmt.Fprintf(w, "   Archived; continued at: %s\n", summary.SuccessorURL)
+			}
+			if g := summary.Governance; g != nil {
+				protected := "unprotected"
+				if g.BranchProtected {
+					protected = "protected"
+				}
+				fmt.Fprintf(w, "   Governance: %s branch %s | %d/%d recent commits signed\n",
+					protected, g.DefaultBranch, g.SignedCommits, g.CheckedCommits)
+			}
+			if summary.SBOM != nil {
+				fmt.Fprintf(w, "   SBOM: %d packages, %d licenses (%s)\n", summary.SBOM.Packages, len(summary.SBOM.Licenses), summary.SBOM.Path)
+			}
+			if summary.DocsURL != "" {
+				fmt.Fprintf(w, "   Docs: %s\n", summary.DocsURL)
+			} else if summary.HasDocsSite {
+				fmt.Fprintln(w, "   Docs: Pages site")
+			}
+			if len(summary.CISystems) > 0 {
+				fmt.Fprintf(w, "   CI: %s\n", strings.Join(summary.CISystems, ", "))
+			}
+			if p := summary.Package; p != nil {
+				fmt.Fprintf(w, "   Package: %s %s %s", p.Registry, p.Name, p.Version)
+				if p.MonthlyDownloads > 0 {
+					fmt.Fprintf(w, " (%s downloads/month)", formatCount(p.MonthlyDownloads, opts.CompactNumbers))
+				}
+				fmt.Fprintln(w)
+			}
+			if len(summary.Images) > 0 {
+				images := make([]string, len(summary.Images))
+				for i, img := range summary.Images {
+					images[i] = img.Name
+					if img.Pulls > 0 {
+						images[i] += " (" + formatCount(img.Pulls, opts.CompactNumbers) + " pulls)"
+					}
+				}
+				fmt.Fprintf(w, "   Images: %s\n", strings.Join(images, ", "))
+			}
+			if summary.GoodFirstIssues > 0 || summary.HelpWantedIssues > 0 {
+				fmt.Fprintf(w, "   Contributing: %d good first issues | %d help wanted\n", summary.GoodFirstIssues, summary.HelpWantedIssues)
+			}
+			if last, ok := parseTimestamp(summary.LastReleaseAt); ok {
+				fmt.Fprintf(w, "   Last release: %s ago", formatAge(time.Since(last)))
+				if summary.ReleaseCadenceDays > 0 {
+					fmt.Fprintf(w, " | every %.0f days on average", summary.ReleaseCadenceDays)
+				}
+				fmt.Fprintln(w)
+			}
+			if summary.Dependents > 0 {
+				fmt.Fprintf(w, "   Dependents: %s\n", formatCount(summary.Dependents, opts.CompactNumbers))
+			}
+			if len(summary.Tags) > 0 {
+				fmt.Fprintf(w, "   Tags: %s\n", strings.Join(summary.Tags, ", "))
+			}
+			if len(summary.Matrix) > 0 {
+				fmt.Fprintf(w, "   Matrix: %s\n", strings.Join(summary.Matrix, "; "))
+			}
+			if summary.NameCollisions > 0 {
+				fmt.Fprintf(w, "   Note: %d other result(s) are also named %q\n", summary.NameCollisions, summary.Name)
+			}
+			fmt.Fprintln(w, strings.Repeat("-", 50))
+		}
+		if group.Name != "" {
+			fmt.Fprintln(w)
+		}
+	}
+	fmt.Fprintln(w, computeStats(summaries, time.Now()).footer(opts.CompactNumbers))
+}
+
+// writeMarkdown renders the results as markdown tables.
+func writeMarkdown(w io.Writer, summaries []RepositorySummary, source string, opts renderOptions) {
+	fmt.Fprintf(w, "# %d repositories from %s\n\n", len(summaries), source)
+	for _, group := range groupSummaries(summaries, opts.GroupBy) {
+		if group.Name != "" {
+			fmt.Fprintf(w, "## %s (%d)\n\n", group.Name, len(group.Items))
+		}
+		fmt.Fprintln(w, "| Repository | Language | Stars | Forks | Updated | Description |")
+		fmt.Fprintln(w, "|---|---|---:|---:|---|---|")
+		for _, s := range group.Items {
+			fmt.Fprintf(w, "| [%s](%s) | %s | %s | %s | %s | %s |\n",
+				markdownEscape(s.FullName), s.URL, markdownEscape(s.Language),
+				formatCount(s.Stars, opts.CompactNumbers), formatCount(s.Forks, opts.CompactNumbers),
+				s.UpdatedAt, markdownEscape(s.Description))
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "_%s_\n", computeStats(summaries, time.Now()).footer(opts.CompactNumbers))
+}
+
+// markdownEscape keeps cell text from breaking the table layout.
+func markdownEscape(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// htmlTemplates renders -format=html pages and the HTML fragments other
+// pages embed (see RenderHTMLFragment). The page is the fragment with a
+// head; the fragment's classes are prefixed and its styles scoped to its
+// own element, so it doesn't clash with the host page.
+// The count function is replaced per render to honour renderOptions.
+var htmlTemplates = template.Must(template.New("page").Funcs(template.FuncMap{
+	"count": func(n int) string { return formatCount(n, false) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{len .Items}} repositories from {{.Source}}</title>
+<style>
+body { font-family: sans-serif; }
+</style>
+</head>
+<body>
+<h1>{{len .Items}} repositories from {{.Source}}</h1>
+{{template "fragment" .}}
+</body>
+</html>
+{{define "fragment"}}<div class="rexplorer">
+<style>
+.rexplorer table { border-collapse: collapse; margin-bottom: 2em; }
+.rexplorer th, .rexplorer td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+.rexplorer td.rexplorer-num { text-align: right; }
+.rexplorer .rexplorer-badge { display: inline-block; margin-left: 4px; padding: 0 6px; border-radius: 8px; font-size: 80%; background: #eee; color: #555; }
+.rexplorer .rexplorer-archived { background: #fbe3c1; color: #7a4b00; }
+.rexplorer .rexplorer-warning { color: #7a4b00; }
+</style>
+{{range .Warnings}}<p class="rexplorer-warning">{{.}}</p>
+{{end}}{{range .Groups}}{{if .Name}}<h2>{{.Name}} ({{len .Items}})</h2>
+{{end}}<table>
+<tr><th>Repository</th><th>Language</th><th>Stars</th><th>Forks</th><th>Updated</th><th>Description</th></tr>
+{{range .Items}}<tr><td><a href="{{.URL}}">{{.FullName}}</a>{{if .IsArchived}}<span class="rexplorer-badge rexplorer-archived">archived</span>{{end}}{{if .IsFork}}<span class="rexplorer-badge">fork</span>{{end}}{{if .IsPrivate}}<span class="rexplorer-badge">private</span>{{end}}{{if and .License (ne .License "None")}}<span class="rexplorer-badge">{{.License}}</span>{{end}}</td><td>{{.Language}}</td><td class="rexplorer-num">{{count .Stars}}</td><td class="rexplorer-num">{{count .Forks}}</td><td>{{.UpdatedAt}}</td><td>{{.Description}}</td></tr>
+{{end}}</table>
+{{end}}<p>{{.Footer}}</p>
+</div>
+{{end}}`))
+
+// writeHTML renders the results as a standalone HTML page.
+func writeHTML(w io.Writer, summaries []RepositorySummary, source string, opts renderOptions) error {
+	return executeHTML(w, "page", summaries, source, nil, opts)
+}
+
+// writeHTMLFragment renders the results as an HTML fragment, for
+// -format=fragment.
+func writeHTMLFragment(w io.Writer, summaries []RepositorySummary, source string, opts renderOptions) error {
+	return executeHTML(w, "fragment", summaries, source, nil, opts)
+}
+
+// RenderHTMLFragment writes a search result as an HTML fragment: a <div>
+// holding the warnings and a table of the repositories, linked and badged
+// as archived, fork, private and by license, for other web pages to embed.
+func RenderHTMLFragment(w io.Writer, result *SearchResult) error {
+	return executeHTML(w, "fragment", result.Items, result.Source, result.Warnings, renderOptions{})
+}
+
+// executeHTML renders one of the htmlTemplates.
+func executeHTML(w io.Writer, name string, summaries []RepositorySummary, source string, warnings []string, opts renderOptions) error {
+	page, err := htmlTemplates.Clone()
+	if err != nil {
+		return err
+	}
+	page.Funcs(template.FuncMap{
+		"count": func(n int) string { return formatCount(n, opts.CompactNumbers) },
+	})
+	return page.ExecuteTemplate(w, name, map[string]any{
+		"Source":   source,
+		"Items":    summaries,
+		"Warnings": warnings,
+		"Groups":   groupSummaries(summaries, opts.GroupBy),
+		"Footer":   computeStats(summaries, time.Now()).footer(opts.CompactNumbers),
+	})
+}
+
+// --- Grouping ---
+
+// summaryGroup is a named subset of the results.
+type summaryGroup struct {
+	Name  string
+	Items []RepositorySummary
+}
+
+// groupKeys maps each -group-by key to the group names of an item. An item
+// may belong to several groups (e.g. one per tag).
+var groupKeys = map[string]func(RepositorySummary) []string{
+	"language": func(s RepositorySummary) []string { return []string{s.Language} },
+	"license":  func(s RepositorySummary) []string { return []string{s.License} },
+	"provider": func(s RepositorySummary) []string { return []string{s.Provider} },
+	"owner": func(s RepositorySummary) []string {
+		owner, _, _ := strings.Cut(s.FullName, "/")
+		return []string{owner}
+	},
+	"tag": func(s RepositorySummary) []string {
+		if len(s.Tags) == 0 {
+			return []string{"(untagged)"}
+		}
+		return s.Tags
+	},
+	"matrix": func(s RepositorySummary) []string {
+		// Without -matrix, or outside every cell, an item has no cell.
+		if len(s.Matrix) == 0 {
+			return []string{"(none)"}
+		}
+		return s.Matrix
+	},
+}
+
+// validateFormat checks a -format value.
+func validateFormat(format string) error {
+	return render(io.Discard, format, nil, "", renderOptions{})
+}
+
+// validateGroupBy checks a -group-by value.
+func validateGroupBy(key string) error {
+	if _, ok := groupKeys[key]; key != "" && !ok {
+		return fmt.Errorf("unknown group-by key %q; must be one of language, owner, license, provider, tag or matrix", key)
+	}
+	return nil
+}
+
+// groupSummaries splits items by key, largest groups first. With an empty
+// key, it returns a single unnamed group holding every item. Item order is
+// preserved within each group.
+func groupSummaries(items []RepositorySummary, key string) []summaryGroup {
+	names, ok := groupKeys[key]
+	if !ok {
+		return []summaryGroup{{Items: items}}
+	}
+
+	index := make(map[string]int)
+	var groups []summaryGroup
+	for _, item := range items {
+		for _, name := range names(item) {
+			if name == "" {
+				name = "(none)"
+			}
+			i, seen := index[name]
+			if !seen {
+				i = len(groups)
+				index[name] = i
+				groups = append(groups, summaryGroup{Name: name})
+			}
+			groups[i].Items = append(groups[i].Items, item)
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if len(groups[i].Items) != len(groups[j].Items) {
+			return len(groups[i].Items) > len(groups[j].Items)
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
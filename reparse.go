@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// --- Re-parsing Archived Pages ---
+//
+// `rexplorer reparse dir/` runs the current parsers and field mappings over
+// pages archived with -save-raw, and writes the normalized results of each
+// archived search to results.json next to its pages. Nothing is fetched,
+// so a parser fix or a new mapping can be backfilled offline.
+
+// reparsedFile is the name of the regenerated results in each query
+// directory.
+const reparsedFile = "results.json"
+
+// perPageParams are the query parameters providers take the page size in.
+var perPageParams = []string{"per_page", "pagelen", "limit", "first"}
+
+// runReparse implements the reparse subcommand.
+func runReparse(args []string) error {
+	fset := flag.NewFlagSet("reparse", flag.ExitOnError)
+	configFile := fset.String("config", "", "Path to the config file for the field mappings (default ~/.config/rexplorer/config.json)")
+	includeRawFields := fset.String("include-raw-fields", "", "Keep these raw fields under \"extra\", as for a search (comma-separated)")
+	rest, err := parseInterspersed(fset, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return fmt.Errorf("usage: rexplorer reparse [-config file] [-include-raw-fields list] <dir>")
+	}
+	cfg, err := loadConfig(*configFile)
+	if err != nil {
+		return err
+	}
+	rawFields, err := rawFieldMappings(splitList(*includeRawFields))
+	if err != nil {
+		return fmt.Errorf("-include-raw-fields: %w", err)
+	}
+
+	var dirs []string
+	err = filepath.WalkDir(rest[0], func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && d.Name() == rawIndexFile {
+			dirs = append(dirs, filepath.Dir(path))
+		}
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", rest[0], err)
+	}
+	if len(dirs) == 0 {
+		return fmt.Errorf("no archived pages found in %s (see -save-raw)", rest[0])
+	}
+
+	failed := 0
+	for _, dir := range dirs {
+		result, pages, err := reparseDir(dir, cfg, rawFields)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", dir, err)
+			failed++
+			continue
+		}
+		data, err := marshalSummaries(result.Items)
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(dir, reparsedFile)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		fmt.Printf("%s %q: %d results from %d pages -> %s\n", result.Source, result.Query, len(result.Items), pages, path)
+		for _, w := range result.Warnings {
+			fmt.Printf("  warning: %s\n", w)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d archived searches could not be parsed", failed, len(dirs))
+	}
+	return nil
+}
+
+// reparseDir parses the pages archived in dir, in page order, and returns
+// the results and the number of pages read. Unreadable pages are skipped
+// with a warning, as a search would.
+func reparseDir(dir string, cfg *Config, rawFields []fieldMapping) (*SearchResult, int, error) {
+	index, err := readRawIndex(dir)
+	if err != nil {
+		return nil, 0, err
+	}
+	p, ok := lookupProvider(index.Service)
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown service %q", index.Service)
+	}
+	b, ok := baseOf(p.New("", nil))
+	if !ok {
+		return nil, 0, fmt.Errorf("%s pages can't be parsed again", index.Service)
+	}
+	b.FieldMappings = slices.Concat(cfg.mappings[strings.ToLower(index.Service)], rawFields)
+
+	pages := slices.Clone(index.Pages)
+	slices.SortFunc(pages, func(a, b rawPage) int { return cmp.Compare(a.Page, b.Page) })
+	result := &SearchResult{Source: b.Source, Query: index.Query}
+	seen := make(map[string]bool)
+	for _, page := range pages {
+		data, err := readGzip(filepath.Join(dir, page.File))
+		if err == nil {
+			var parsed searchPage
+			parsed, err = b.parsePage(bytes.NewReader(data), data, page.Header, page.Page, archivedPerPage(page.URL, b.PerPage))
+			if page.Page == 1 || result.TotalCount == 0 {
+				result.TotalCount = parsed.totalCount
+			}
+			for _, repo := range parsed.repos {
+				key := strings.ToLower(cmp.Or(repo.FullName, repo.URL))
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				repo.Provider = b.Source
+				result.Items = append(result.Items, repo)
+			}
+		}
+		if err != nil {
+			result.Warnings = addWarning(result.Warnings, fmt.Sprintf("%s: page %d could not be read (%v)", b.Source, page.Page, err))
+		}
+	}
+	return result, len(pages), nil
+}
+
+// archivedPerPage reads the page size from an archived request URL.
+func archivedPerPage(rawURL string, fallback int) int {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fallback
+	}
+	for _, param := range perPageParams {
+		if n, err := strconv.Atoi(u.Query().Get(param)); err == nil && n > 0 {
+			return n
+		}
+	}
+	return fallback
+}
+
+// readGzip reads a gzipped file.
+func readGzip(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer zr.Close()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return data, nil
+}
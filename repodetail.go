@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// --- Repository Details ---
+
+// repoFetcher is implemented by providers that can look up one repository
+// by its full name.
+type repoFetcher interface {
+	repoURL(fullName string) string
+	parseRepo(body io.Reader) (RepositorySummary, error)
+}
+
+// repoDetail is the outcome of a (possibly conditional) repository lookup.
+type repoDetail struct {
+	Summary      RepositorySummary
+	ETag         string
+	LastModified string
+	// NotModified is set when the provider answered 304 to a conditional
+	// request; Summary is then empty.
+	NotModified bool
+}
+
+// fetchRepo looks up one repository. With an etag or lastModified from a
+// previous lookup the request is conditional, and an unchanged repository
+// comes back as NotModified (which GitHub doesn't count against the rate
+// limit).
+func (s *BaseRepoSearcher) fetchRepo(ctx context.Context, fullName, etag, lastModified string) (repoDetail, error) {
+	rf, ok := s.implementation.(repoFetcher)
+	if !ok {
+		return repoDetail{}, fmt.Errorf("repository lookup: %w", errUnsupported)
+	}
+	var prepare func(*http.Request)
+	if etag != "" || lastModified != "" {
+		prepare = func(req *http.Request) {
+			if etag != "" {
+				req.Header.Set("If-None-Match", etag)
+			}
+			if lastModified != "" {
+				req.Header.Set("If-Modified-Since", lastModified)
+			}
+		}
+	}
+
+	resp, err := s.doWithRetries(ctx, rf.repoURL(fullName), 0, prepare)
+	if err != nil {
+		return repoDetail{}, err
+	}
+	defer resp.Body.Close()
+	detail := repoDetail{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+	if resp.StatusCode == http.StatusNotModified {
+		detail.NotModified = true
+		if detail.ETag == "" {
+			detail.ETag = etag
+		}
+		if detail.LastModified == "" {
+			detail.LastModified = lastModified
+		}
+		return detail, nil
+	}
+	detail.Summary, err = rf.parseRepo(resp.Body)
+	if err != nil {
+		return repoDetail{}, err
+	}
+	detail.Summary.Provider = s.Source
+	if detail.Summary.FullName != "" && !strings.EqualFold(detail.Summary.FullName, fullName) {
+		detail.Summary.PreviousFullName = fullName // Followed a redirect
+	}
+	return detail, nil
+}
+
+// repoURL implements repoFetcher.
+func (g *GitHubSearcher) repoURL(fullName string) string { return g.BaseURL + "/repos/" + fullName }
+
+// parseRepo implements repoFetcher.
+func (g *GitHubSearcher) parseRepo(body io.Reader) (RepositorySummary, error) {
+	var repo gitHubRepository
+	if err := json.NewDecoder(body).Decode(&repo); err != nil {
+		return RepositorySummary{}, fmt.Errorf("failed to unmarshal GitHub repository: %w", err)
+	}
+	return g.mapRepoToSummary(&repo), nil
+}
+
+// repoURL implements repoFetcher.
+func (g *GitLabSearcher) repoURL(fullName string) string {
+	return g.BaseURL + "/projects/" + url.PathEscape(fullName)
+}
+
+// parseRepo implements repoFetcher.
+func (g *GitLabSearcher) parseRepo(body io.Reader) (RepositorySummary, error) {
+	var repo gitLabRepository
+	if err := json.NewDecoder(body).Decode(&repo); err != nil {
+		return RepositorySummary{}, fmt.Errorf("failed to unmarshal GitLab project: %w", err)
+	}
+	return g.mapRepoToSummary(&repo), nil
+}
+
+// repoURL implements repoFetcher.
+func (b *BitbucketSearcher) repoURL(fullName string) string {
+	return b.BaseURL + "/repositories/" + fullName
+}
+
+// parseRepo implements repoFetcher.
+func (b *BitbucketSearcher) parseRepo(body io.Reader) (RepositorySummary, error) {
+	var repo bitbucketRepository
+	if err := json.NewDecoder(body).Decode(&repo); err != nil {
+		return RepositorySummary{}, fmt.Errorf("failed to unmarshal Bitbucket repository: %w", err)
+	}
+	return b.mapRepoToSummary(&repo), nil
+}
+
+// repoURL implements repoFetcher.
+func (g *GitCodeSearcher) repoURL(fullName string) string { return g.BaseURL + "/repos/" + fullName }
+
+// parseRepo implements repoFetcher.
+func (g *GitCodeSearcher) parseRepo(body io.Reader) (RepositorySummary, error) {
+	var repo gitCodeRepository
+	if err := json.NewDecoder(body).Decode(&repo); err != nil {
+		return RepositorySummary{}, fmt.Errorf("failed to unmarshal GitCode repository: %w", err)
+	}
+	return g.mapRepoToSummary(&repo), nil
+}
+
+// repoURL implements repoFetcher.
+func (g *GiteeSearcher) repoURL(fullName string) string { return g.BaseURL + "/repos/" + fullName }
+
+// parseRepo implements repoFetcher.
+func (g *GiteeSearcher) parseRepo(body io.Reader) (RepositorySummary, error) {
+	var repo giteeRepository
+	if err := json.NewDecoder(body).Decode(&repo); err != nil {
+		return RepositorySummary{}, fmt.Errorf("failed to unmarshal Gitee repository: %w", err)
+	}
+	return g.mapRepoToSummary(&repo), nil
+}
+
+// repoURL implements repoFetcher.
+func (c *CodebergSearcher) repoURL(fullName string) string { return c.BaseURL + "/repos/" + fullName }
+
+// parseRepo implements repoFetcher.
+func (c *CodebergSearcher) parseRepo(body io.Reader) (RepositorySummary, error) {
+	var repo codebergRepository
+	if err := json.NewDecoder(body).Decode(&repo); err != nil {
+		return RepositorySummary{}, fmt.Errorf("failed to unmarshal Codeberg repository: %w", err)
+	}
+	return c.mapRepoToSummary(&repo), nil
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// --- Reports ---
+
+// reports maps `rexplorer report <kind>` to its generator.
+var reports = map[string]func(args []string) error{
+	"compliance": runComplianceReport,
+}
+
+// runReport implements `rexplorer report <kind> [flags] <file.json>`.
+func runReport(args []string) error {
+	kinds := make([]string, 0, len(reports))
+	for kind := range reports {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	if len(args) == 0 {
+		return fmt.Errorf("usage: rexplorer report <%s> [flags] <file.json>", strings.Join(kinds, "|"))
+	}
+	run, ok := reports[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown report %q; must be one of %s", args[0], strings.Join(kinds, ", "))
+	}
+	return run(args[1:])
+}
+
+// --- Compliance Report ---
+//
+// Legal and security reviews of candidate dependencies ask the same
+// questions of every repository: what license, is it maintained, does it
+// have known vulnerabilities. The compliance report answers them from saved
+// results, checking vulnerabilities against OSV for repositories whose
+// package was identified (-enrich=packages).
+
+// complianceFormats are the accepted report -format values.
+var complianceFormats = []string{"markdown", "html", "csv"}
+
+// spdxLicenses maps the license names providers report to SPDX identifiers.
+var spdxLicenses = map[string]string{
+	"mit license":                                  "MIT",
+	"mit":                                          "MIT",
+	"apache license 2.0":                           "Apache-2.0",
+	"apache-2.0":                                   "Apache-2.0",
+	"gnu general public license v2.0":              "GPL-2.0",
+	"gnu general public license v3.0":              "GPL-3.0",
+	"gnu lesser general public license v2.1":       "LGPL-2.1",
+	"gnu lesser general public license v3.0":       "LGPL-3.0",
+	"gnu affero general public license v3.0":       "AGPL-3.0",
+	"bsd 2-clause \"simplified\" license":          "BSD-2-Clause",
+	"bsd 3-clause \"new\" or \"revised\" license":  "BSD-3-Clause",
+	"mozilla public license 2.0":                   "MPL-2.0",
+	"eclipse public license 2.0":                   "EPL-2.0",
+	"the unlicense":                                "Unlicense",
+	"isc license":                                  "ISC",
+	"boost software license 1.0":                   "BSL-1.0",
+	"creative commons zero v1.0 universal":         "CC0-1.0",
+	"do what the f*ck you want to public license":  "WTFPL",
+	"zlib license":                                 "Zlib",
+	"mulan permissive software license, version 2": "MulanPSL-2.0",
+}
+
+// spdxLicense returns the SPDX identifier for a provider license name, the
+// name itself if it has none, or NOASSERTION if the license is unknown.
+func spdxLicense(name string) string {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "none", "unknown", "other", "noassertion":
+		return "NOASSERTION"
+	}
+	if id, ok := spdxLicenses[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return id
+	}
+	return name
+}
+
+// complianceRow is one repository in the compliance report.
+type complianceRow struct {
+	Repository   string
+	URL          string
+	Provider     string
+	License      string
+	Archived     bool
+	LastActivity string
+	Package      string
+	// Vulnerabilities are OSV IDs; nil means not checked.
+	Vulnerabilities []string
+}
+
+// VulnerabilityStatus renders the vulnerability column.
+func (r complianceRow) VulnerabilityStatus() string {
+	switch {
+	case r.Vulnerabilities == nil:
+		return "not checked"
+	case len(r.Vulnerabilities) == 0:
+		return "none known"
+	}
+	return strings.Join(r.Vulnerabilities, " ")
+}
+
+// runComplianceReport implements `rexplorer report compliance`.
+func runComplianceReport(args []string) error {
+	fs := flag.NewFlagSet("report compliance", flag.ExitOnError)
+	format := fs.String("format", "markdown", "Report format: "+strings.Join(complianceFormats, ", "))
+	output := fs.String("o", "", "Write the report to this file instead of stdout")
+	offline := fs.Bool("offline", false, "Don't query OSV for vulnerabilities")
+	osvURL := fs.String("osv-url", "https://api.osv.dev", "OSV API base URL")
+	timeout := fs.Duration("timeout", time.Minute, "Timeout for the vulnerability lookup")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: rexplorer report compliance [flags] <file.json>")
+		fs.PrintDefaults()
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return fmt.Errorf("report compliance needs exactly one input file")
+	}
+	if !containsFold(complianceFormats, *format) {
+		return fmt.Errorf("unknown report format %q; must be one of %s", *format, strings.Join(complianceFormats, ", "))
+	}
+	items, err := loadSummaries(positional[0])
+	if err != nil {
+		return err
+	}
+
+	rows := make([]complianceRow, len(items))
+	for i, item := range items {
+		rows[i] = complianceRow{
+			Repository:   item.FullName,
+			URL:          item.URL,
+			Provider:     item.Provider,
+			License:      spdxLicense(item.License),
+			Archived:     item.IsArchived,
+			LastActivity: item.UpdatedAt,
+		}
+		if p := item.Package; p != nil {
+			rows[i].Package = p.Registry + ":" + p.Name + "@" + p.Version
+		}
+	}
+	if !*offline {
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+		client := &http.Client{Timeout: *timeout}
+		if err := checkVulnerabilities(ctx, client, *osvURL, items, rows); err != nil {
+			return err
+		}
+	}
+
+	var buf bytes.Buffer
+	switch strings.ToLower(*format) {
+	case "markdown":
+		writeComplianceMarkdown(&buf, rows, positional[0])
+	case "html":
+		err = writeComplianceHTML(&buf, rows, positional[0])
+	case "csv":
+		err = writeComplianceCSV(&buf, rows)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to render compliance report: %w", err)
+	}
+	if *output == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	if err := os.WriteFile(*output, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write compliance report: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote compliance report for %d repositories to %s\n", len(rows), *output)
+	return nil
+}
+
+// osvEcosystems maps PackageInfo registries to OSV ecosystem names.
+var osvEcosystems = map[string]string{"Go": "Go", "npm": "npm", "PyPI": "PyPI"}
+
+// checkVulnerabilities fills in Vulnerabilities for every row whose item has
+// a package, with one OSV batch query.
+func checkVulnerabilities(ctx context.Context, client *http.Client, osvURL string, items []RepositorySummary, rows []complianceRow) error {
+	type osvQuery struct {
+		Package struct {
+			Name      string `json:"name"`
+			Ecosystem string `json:"ecosystem"`
+		} `json:"package"`
+		Version string `json:"version,omitempty"`
+	}
+	var batch struct {
+		Queries []osvQuery `json:"queries"`
+	}
+	var index []int // Query -> row
+	for i, item := range items {
+		p := item.Package
+		if p == nil || osvEcosystems[p.Registry] == "" {
+			continue
+		}
+		var q osvQuery
+		q.Package.Name, q.Package.Ecosystem = p.Name, osvEcosystems[p.Registry]
+		q.Version = p.Version
+		if p.Registry == "Go" {
+			q.Version = strings.TrimPrefix(q.Version, "v") // OSV lists Go versions without the v
+		}
+		batch.Queries = append(batch.Queries, q)
+		index = append(index, i)
+	}
+	if len(batch.Queries) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("failed to marshal OSV query: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(osvURL, "/")+"/v1/querybatch", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "go-repo-searcher/1.0")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("OSV request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("OSV request failed with status %d: %s", resp.StatusCode, body)
+	}
+	var result struct {
+		Results []struct {
+			Vulns []struct {
+				ID string `json:"id"`
+			} `json:"vulns"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to unmarshal OSV response: %w", err)
+	}
+	for qi, r := range result.Results {
+		if qi >= len(index) {
+			break
+		}
+		ids := []string{}
+		for _, v := range r.Vulns {
+			ids = append(ids, v.ID)
+		}
+		sort.Strings(ids)
+		rows[index[qi]].Vulnerabilities = ids
+	}
+	return nil
+}
+
+// yesNo renders a flag for the report.
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// writeComplianceMarkdown renders the report as a markdown table.
+func writeComplianceMarkdown(w io.Writer, rows []complianceRow, source string) {
+	fmt.Fprintf(w, "# Compliance report: %s\n\n", source)
+	fmt.Fprintf(w, "Generated %s for %d repositories.\n\n", time.Now().UTC().Format(time.RFC3339), len(rows))
+	fmt.Fprintln(w, "| Repository | License (SPDX) | Archived | Last activity | Package | Known vulnerabilities |")
+	fmt.Fprintln(w, "|---|---|---|---|---|---|")
+	for _, r := range rows {
+		fmt.Fprintf(w, "| [%s](%s) | %s | %s | %s | %s | %s |\n", markdownEscape(r.Repository), r.URL,
+			markdownEscape(r.License), yesNo(r.Archived), r.LastActivity, markdownEscape(r.Package), r.VulnerabilityStatus())
+	}
+}
+
+// compliancePage is the template for the HTML compliance report.
+var compliancePage = template.Must(template.New("compliance").Funcs(template.FuncMap{"yesNo": yesNo}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Compliance report: {{.Source}}</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+tr.flag td { background: #fdecea; }
+</style>
+</head>
+<body>
+<h1>Compliance report: {{.Source}}</h1>
+<p>Generated {{.Generated}} for {{len .Rows}} repositories.</p>
+<table>
+<tr><th>Repository</th><th>License (SPDX)</th><th>Archived</th><th>Last activity</th><th>Package</th><th>Known vulnerabilities</th></tr>
+{{range .Rows}}<tr{{if or .Archived (and .Vulnerabilities (gt (len .Vulnerabilities) 0)) (eq .License "NOASSERTION")}} class="flag"{{end}}><td><a href="{{.URL}}">{{.Repository}}</a></td><td>{{.License}}</td><td>{{yesNo .Archived}}</td><td>{{.LastActivity}}</td><td>{{.Package}}</td><td>{{.VulnerabilityStatus}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// writeComplianceHTML renders the report as a standalone HTML page, with
+// rows needing attention highlighted.
+func writeComplianceHTML(w io.Writer, rows []complianceRow, source string) error {
+	return compliancePage.Execute(w, map[string]any{
+		"Source":    source,
+		"Generated": time.Now().UTC().Format(time.RFC3339),
+		"Rows":      rows,
+	})
+}
+
+// writeComplianceCSV renders the report as CSV, for spreadsheets.
+func writeComplianceCSV(w io.Writer, rows []complianceRow) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"repository", "url", "provider", "license", "archived", "last_activity", "package", "vulnerabilities"})
+	for _, r := range rows {
+		cw.Write([]string{r.Repository, r.URL, r.Provider, r.License, yesNo(r.Archived), r.LastActivity, r.Package, r.VulnerabilityStatus()})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// --- Paginated Results ---
+//
+// Finished result sets are kept server-side with the jobs, so a web client
+// can fetch them a page at a time instead of thousands of items at once:
+//
+//	GET /api/results/{id}?page=2&per_page=50&sort=stars&order=asc
+//
+// {id} is a job's ID. format=html returns the page as an HTML fragment to
+// embed (see RenderHTMLFragment) instead of JSON. A search run directly,
+// with GET /api/search or POST /api/searches/{name}/run, is kept the same
+// way when the request asks for per_page, and answers with its first page
+// and the ID.
+
+// Result page sizes.
+const (
+	defaultResultsPerPage = 50
+	maxResultsPerPage     = 500
+)
+
+// resultPageParams are the page, size and order requested.
+type resultPageParams struct {
+	Page    int
+	PerPage int
+	Sort    string
+	Order   string // "asc" reverses the sort key's natural order
+	Format  string // "json" or "html"
+}
+
+// parseResultPageParams reads the page parameters from a query string.
+func parseResultPageParams(q url.Values) (resultPageParams, error) {
+	p := resultPageParams{
+		Page: 1, PerPage: defaultResultsPerPage,
+		Sort: q.Get("sort"), Order: q.Get("order"), Format: cmp.Or(q.Get("format"), "json"),
+	}
+	if v := q.Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return p, fmt.Errorf("page: expected a positive number")
+		}
+		p.Page = n
+	}
+	if v := q.Get("per_page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxResultsPerPage {
+			return p, fmt.Errorf("per_page: expected a number from 1 to %d", maxResultsPerPage)
+		}
+		p.PerPage = n
+	}
+	if _, ok := sortKeys[p.Sort]; p.Sort != "" && !ok {
+		return p, fmt.Errorf("sort: unknown key %q; must be one of %s", p.Sort, strings.Join(sortKeyNames(), ", "))
+	}
+	if p.Order != "" && p.Order != "asc" && p.Order != "desc" {
+		return p, fmt.Errorf("order: expected asc or desc")
+	}
+	if p.Format != "json" && p.Format != "html" {
+		return p, fmt.Errorf("format: expected json or html")
+	}
+	return p, nil
+}
+
+// query renders the parameters for another page.
+func (p resultPageParams) query(page int) string {
+	q := url.Values{"page": {strconv.Itoa(page)}, "per_page": {strconv.Itoa(p.PerPage)}}
+	if p.Sort != "" {
+		q.Set("sort", p.Sort)
+	}
+	if p.Order != "" {
+		q.Set("order", p.Order)
+	}
+	if p.Format != "json" {
+		q.Set("format", p.Format)
+	}
+	return q.Encode()
+}
+
+// resultPage is one page of a stored result set.
+type resultPage struct {
+	ID         string              `json:"id"`
+	Source     string              `json:"source"`
+	Query      string              `json:"query"`
+	TotalCount int                 `json:"total_count"` // As the providers reported it
+	Warnings   []string            `json:"warnings,omitempty"`
+	Sort       string              `json:"sort,omitempty"`
+	Order      string              `json:"order,omitempty"`
+	Page       int                 `json:"page"`
+	PerPage    int                 `json:"per_page"`
+	Pages      int                 `json:"pages"`
+	TotalItems int                 `json:"total_items"` // Items stored
+	Next       string              `json:"next,omitempty"`
+	Items      []RepositorySummary `json:"items"`
+}
+
+// newResultPage cuts one page out of a job's results.
+func newResultPage(j job, p resultPageParams) resultPage {
+	result := j.Result
+	items := result.Items
+	if p.Sort != "" {
+		items = slices.Clone(items)
+		sortSummaries(items, p.Sort, p.Order == "asc")
+	}
+	page := resultPage{
+		ID: j.ID, Source: result.Source, Query: result.Query, TotalCount: result.TotalCount,
+		Warnings: result.Warnings, Sort: p.Sort, Order: p.Order,
+		Page: p.Page, PerPage: p.PerPage, Pages: (len(items) + p.PerPage - 1) / p.PerPage,
+		TotalItems: len(items), Items: []RepositorySummary{},
+	}
+	start := min((p.Page-1)*p.PerPage, len(items))
+	end := min(start+p.PerPage, len(items))
+	page.Items = append(page.Items, items[start:end]...)
+	if p.Page < page.Pages {
+		page.Next = "/api/results/" + j.ID + "?" + p.query(p.Page+1)
+	}
+	return page
+}
+
+// handleResults serves GET /api/results/{id}.
+func (s *server) handleResults(w http.ResponseWriter, r *http.Request, t *tenant) {
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/results"), "/")
+	if id == "" || strings.Contains(id, "/") {
+		writeAPIError(w, http.StatusNotFound, "not found; use GET /api/results/{id}")
+		return
+	}
+	params, err := parseResultPageParams(r.URL.Query())
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	j, ok := s.jobs.get(t.Name, id)
+	switch {
+	case !ok:
+		writeAPIError(w, http.StatusNotFound, fmt.Sprintf("no results %q", id))
+	case !j.finished():
+		writeAPIError(w, http.StatusConflict, fmt.Sprintf("job %s is %s; poll GET /api/jobs/%s until it finishes", id, j.Status, id))
+	case j.Result == nil:
+		writeAPIError(w, http.StatusConflict, fmt.Sprintf("job %s %s without results: %s", id, j.Status, j.Error))
+	default:
+		writeResultPage(w, newResultPage(j, params), params)
+	}
+}
+
+// writeResultPage writes a page of results in the requested format.
+func writeResultPage(w http.ResponseWriter, page resultPage, params resultPageParams) {
+	if params.Format != "html" {
+		writeAPIJSON(w, http.StatusOK, page)
+		return
+	}
+	if page.Next != "" {
+		w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", page.Next))
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	RenderHTMLFragment(w, &SearchResult{Source: page.Source, Query: page.Query, Items: page.Items, Warnings: page.Warnings})
+}
+
+// record keeps the results of a search that was run directly as a
+// finished job, so they can be paged through later.
+func (q *jobQueue) record(tenantName, name string, search SavedSearch, started time.Time, result *SearchResult) (job, error) {
+	now := time.Now().UTC()
+	started = started.UTC()
+	j := &job{
+		ID: newJobID(), Tenant: tenantName, Name: name, Search: search, Status: jobDone,
+		SubmittedAt: started, StartedAt: &started, FinishedAt: &now,
+		Items: len(result.Items), Result: result,
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err := q.save(j); err != nil {
+		return job{}, err
+	}
+	q.jobs[j.ID] = j
+	return *j, nil
+}
@@ -0,0 +1,56 @@
+package rexplorertest
+
+import (
+	"context"
+	"sync"
+)
+
+// --- Fake Searcher ---
+
+// FakeSearcher is a canned searcher that returns a fixed result, standing
+// in for a provider entirely. R and P are the result and plan types of the
+// searcher interface it fakes: FakeSearcher[SearchResult, SearchPlan] is a
+// rexplorer searcher. Like the real searchers, it is safe for concurrent use.
+type FakeSearcher[R, P any] struct {
+	Result  *R // Search returns a copy; a zero R if nil
+	Err     error
+	Planned *P // Plan returns a copy; a zero P if nil
+
+	mu      sync.Mutex
+	queries []string
+}
+
+// Queries returns every query passed to Search so far, in order.
+func (f *FakeSearcher[R, P]) Queries() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.queries...)
+}
+
+// Search returns a copy of the canned result or the canned error.
+func (f *FakeSearcher[R, P]) Search(ctx context.Context, query string, maxPages int) (*R, error) {
+	f.mu.Lock()
+	f.queries = append(f.queries, query)
+	f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var result R
+	if f.Result != nil {
+		result = *f.Result
+	}
+	return &result, nil
+}
+
+// Plan returns a copy of the canned plan; a FakeSearcher never issues
+// requests.
+func (f *FakeSearcher[R, P]) Plan(query string, maxPages int) (*P, error) {
+	var plan P
+	if f.Planned != nil {
+		plan = *f.Planned
+	}
+	return &plan, nil
+}
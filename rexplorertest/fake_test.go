@@ -0,0 +1,36 @@
+package rexplorertest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+type result struct{ Items []string }
+
+type plan struct{ Requests int }
+
+func TestFakeSearcher(t *testing.T) {
+	f := &FakeSearcher[result, plan]{Result: &result{Items: []string{"o/a"}}}
+	r, err := f.Search(context.Background(), "go", 1)
+	if err != nil || !slices.Equal(r.Items, []string{"o/a"}) {
+		t.Fatalf("Search = %v, %v", r, err)
+	}
+	r.Items = nil
+	if again, _ := f.Search(context.Background(), "rust", 1); len(again.Items) != 1 {
+		t.Error("changing a result changed the canned one")
+	}
+	if got := f.Queries(); !slices.Equal(got, []string{"go", "rust"}) {
+		t.Errorf("Queries = %v", got)
+	}
+	if p, err := f.Plan("go", 1); err != nil || p.Requests != 0 {
+		t.Errorf("Plan = %v, %v", p, err)
+	}
+
+	boom := errors.New("boom")
+	f.Err = boom
+	if _, err := f.Search(context.Background(), "go", 1); !errors.Is(err, boom) {
+		t.Errorf("Search error = %v, want %v", err, boom)
+	}
+}
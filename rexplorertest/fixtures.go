@@ -0,0 +1,164 @@
+// Package rexplorertest helps applications embedding rexplorer's searchers
+// write deterministic tests without hitting the real APIs: FakeSearcher
+// stands in for a provider entirely, and the transports record and replay
+// its HTTP exchanges, so the provider parsing code still runs against the
+// replayed responses.
+//
+// Record once against the real service, e.g. with rexplorer -record-fixtures
+// dir/ or a client using RecordingTransport, then replay in tests:
+//
+//	client := &http.Client{Transport: &rexplorertest.ReplayTransport{Dir: "testdata/github"}}
+package rexplorertest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Fixture is one recorded HTTP exchange, stored as a JSON file.
+type Fixture struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"` // Credentials are redacted
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   string      `json:"body"`
+}
+
+// FixtureName derives a stable file name for a request. The URL is redacted
+// with redact first, if set, so a replay matches a recording regardless of
+// the token used. A request body (e.g. a GraphQL query) is part of the
+// name, since such requests often share one URL.
+func FixtureName(req *http.Request, redact func(string) string) string {
+	if redact == nil {
+		redact = func(u string) string { return u }
+	}
+	h := sha256.New()
+	io.WriteString(h, req.Method+" "+redact(req.URL.String()))
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			if data, err := io.ReadAll(body); err == nil && len(data) > 0 {
+				io.WriteString(h, "\n")
+				h.Write(data)
+			}
+			body.Close()
+		}
+	}
+	return req.URL.Hostname() + "-" + hex.EncodeToString(h.Sum(nil)[:8]) + ".json"
+}
+
+// RecordingTransport passes requests through to Next and saves every
+// exchange as a fixture file in Dir.
+type RecordingTransport struct {
+	Dir  string
+	Next http.RoundTripper // http.DefaultTransport if nil
+	// RedactURL and RedactHeaders scrub credentials before anything is
+	// saved. Both are required, so a token can't be recorded by accident.
+	RedactURL     func(string) string
+	RedactHeaders func(http.Header) http.Header
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if t.RedactURL == nil || t.RedactHeaders == nil {
+		return nil, fmt.Errorf("recording transport needs RedactURL and RedactHeaders")
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response for recording: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	fx := Fixture{
+		Method: req.Method,
+		URL:    t.RedactURL(req.URL.String()),
+		Status: resp.StatusCode,
+		Header: t.RedactHeaders(resp.Header),
+		Body:   string(body),
+	}
+	if err := WriteFixture(t.Dir, FixtureName(req, t.RedactURL), fx); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// WriteFixture stores fx as dir/name.
+func WriteFixture(dir, name string, fx Fixture) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create fixture directory %s: %w", dir, err)
+	}
+	data, err := json.MarshalIndent(fx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal fixture: %w", err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write fixture %s: %w", path, err)
+	}
+	return nil
+}
+
+// ReplayTransport serves responses previously saved by RecordingTransport.
+// Requests without a matching fixture fail instead of reaching the network.
+type ReplayTransport struct {
+	Dir string
+	// RedactURL must match the one used when recording.
+	RedactURL func(string) string
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	redactURL := t.RedactURL
+	if redactURL == nil {
+		redactURL = func(u string) string { return u }
+	}
+	path := filepath.Join(t.Dir, FixtureName(req, redactURL))
+	fx, err := ReadFixture(path)
+	if err != nil {
+		return nil, fmt.Errorf("no fixture for %s %s: %w", req.Method, redactURL(req.URL.String()), err)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", fx.Status, http.StatusText(fx.Status)),
+		StatusCode:    fx.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        fx.Header,
+		Body:          io.NopCloser(strings.NewReader(fx.Body)),
+		ContentLength: int64(len(fx.Body)),
+		Request:       req,
+	}, nil
+}
+
+// ReadFixture loads a single fixture file.
+func ReadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fx Fixture
+	if err := json.Unmarshal(data, &fx); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+	}
+	if fx.Header == nil {
+		fx.Header = http.Header{}
+	}
+	return &fx, nil
+}
@@ -0,0 +1,70 @@
+package rexplorertest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Total", "1")
+		io.WriteString(w, `[{"full_name":"o/a"}]`)
+	}))
+	defer srv.Close()
+	dir := t.TempDir()
+
+	redactURL := func(u string) string { return strings.Replace(u, "secret", "REDACTED", 1) }
+	record := &http.Client{Transport: &RecordingTransport{Dir: dir, RedactURL: redactURL, RedactHeaders: http.Header.Clone}}
+	resp, err := record.Get(srv.URL + "/search?q=go&access_token=secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 {
+		t.Fatalf("recorded %d fixtures, want 1", len(files))
+	}
+	data, _ := os.ReadFile(dir + "/" + files[0].Name())
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("fixture contains the token: %s", data)
+	}
+
+	// A different token replays the same fixture, without the server.
+	srv.Close()
+	replay := &http.Client{Transport: &ReplayTransport{Dir: dir, RedactURL: func(u string) string {
+		return strings.Replace(u, "other", "REDACTED", 1)
+	}}}
+	resp, err = replay.Get(srv.URL + "/search?q=go&access_token=other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != `[{"full_name":"o/a"}]` || resp.Header.Get("X-Total") != "1" {
+		t.Errorf("replayed %q with X-Total %q", body, resp.Header.Get("X-Total"))
+	}
+
+	if _, err := replay.Get(srv.URL + "/search?q=rust"); err == nil {
+		t.Error("replaying an unrecorded request succeeded")
+	}
+}
+
+func TestFixtureNameIncludesBody(t *testing.T) {
+	a, _ := http.NewRequest(http.MethodPost, "https://api.example.com/graphql", strings.NewReader(`{"query":"a"}`))
+	b, _ := http.NewRequest(http.MethodPost, "https://api.example.com/graphql", strings.NewReader(`{"query":"b"}`))
+	if FixtureName(a, nil) == FixtureName(b, nil) {
+		t.Error("requests with different bodies share a fixture name")
+	}
+}
+
+func TestRecordingNeedsRedaction(t *testing.T) {
+	record := &http.Client{Transport: &RecordingTransport{Dir: t.TempDir()}}
+	if _, err := record.Get("http://127.0.0.1:1/search?access_token=secret"); err == nil {
+		t.Error("recorded without redacting credentials")
+	}
+}
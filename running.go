@@ -0,0 +1,144 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --- In-Flight Searches ---
+//
+// Every search the server runs, ad hoc, saved or scheduled, is listed while
+// it runs: GET /api/running returns the tenant's searches with their
+// progress, and DELETE /api/running/{id} cancels one. Cancelling ends the
+// search's context, so the page loop stops after the page in flight and
+// the search returns what it has, marked partial, instead of crawling on.
+
+// runningSearch is one search in progress.
+type runningSearch struct {
+	ID        string    `json:"id"`
+	Tenant    string    `json:"-"`
+	Name      string    `json:"name,omitempty"` // Saved search, if any
+	Service   string    `json:"service"`
+	Query     string    `json:"query"`
+	StartedAt time.Time `json:"started_at"`
+	cancel    context.CancelFunc
+
+	mu    sync.Mutex
+	pages int
+	items int
+}
+
+// runningStatus is a running search as listed by the API.
+type runningStatus struct {
+	*runningSearch
+	Pages     int  `json:"pages"`
+	Items     int  `json:"items"`
+	Cancelled bool `json:"cancelled,omitempty"`
+}
+
+// observe implements BaseRepoSearcher.OnEvent, counting progress.
+func (r *runningSearch) observe(e SearchEvent) {
+	if e.Kind != EventPageCompleted {
+		return
+	}
+	r.mu.Lock()
+	r.pages++
+	r.items += e.Items
+	r.mu.Unlock()
+}
+
+// runningSearches tracks the server's searches in progress.
+type runningSearches struct {
+	mu        sync.Mutex
+	searches  map[string]*runningSearch
+	cancelled map[string]bool
+}
+
+// start registers a search and returns it with a context that Cancel ends.
+func (rs *runningSearches) start(ctx context.Context, t *tenant, name string, search SavedSearch) (*runningSearch, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	id := make([]byte, 6)
+	rand.Read(id)
+	r := &runningSearch{
+		ID: hex.EncodeToString(id), Tenant: t.Name, Name: name,
+		Service: cmp.Or(search.Service, "github"), Query: search.Query,
+		StartedAt: time.Now().UTC(), cancel: cancel,
+	}
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.searches == nil {
+		rs.searches = make(map[string]*runningSearch)
+		rs.cancelled = make(map[string]bool)
+	}
+	rs.searches[r.ID] = r
+	return r, ctx
+}
+
+// finish unregisters a search, and reports whether it was cancelled.
+func (rs *runningSearches) finish(r *runningSearch) bool {
+	r.cancel()
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	cancelled := rs.cancelled[r.ID]
+	delete(rs.searches, r.ID)
+	delete(rs.cancelled, r.ID)
+	return cancelled
+}
+
+// list returns the tenant's running searches, oldest first.
+func (rs *runningSearches) list(tenantName string) []runningStatus {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	list := []runningStatus{}
+	for _, r := range rs.searches {
+		if r.Tenant != tenantName {
+			continue
+		}
+		r.mu.Lock()
+		list = append(list, runningStatus{runningSearch: r, Pages: r.pages, Items: r.items, Cancelled: rs.cancelled[r.ID]})
+		r.mu.Unlock()
+	}
+	slices.SortFunc(list, func(a, b runningStatus) int {
+		return cmp.Or(a.StartedAt.Compare(b.StartedAt), strings.Compare(a.ID, b.ID))
+	})
+	return list
+}
+
+// cancel ends one of the tenant's running searches. It reports false if
+// the tenant has no running search with that ID.
+func (rs *runningSearches) cancel(tenantName, id string) bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	r, ok := rs.searches[id]
+	if !ok || r.Tenant != tenantName {
+		return false
+	}
+	rs.cancelled[id] = true
+	r.cancel()
+	return true
+}
+
+// handleRunning serves GET /api/running and DELETE /api/running/{id}.
+func (s *server) handleRunning(w http.ResponseWriter, r *http.Request, t *tenant) {
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/running"), "/")
+	switch {
+	case id == "" && r.Method == http.MethodGet:
+		writeAPIJSON(w, http.StatusOK, s.running.list(t.Name))
+	case id != "" && r.Method == http.MethodDelete:
+		if !s.running.cancel(t.Name, id) {
+			writeAPIError(w, http.StatusNotFound, fmt.Sprintf("no running search %q", id))
+			return
+		}
+		writeAPIJSON(w, http.StatusAccepted, map[string]string{"id": id, "status": "cancelling"})
+	default:
+		writeAPIError(w, http.StatusNotFound, "not found; use GET /api/running or DELETE /api/running/{id}")
+	}
+}
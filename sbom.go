@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// --- SBOMs ---
+//
+// GitHub generates an SPDX software bill of materials from each repository's
+// dependency graph. The sbom enricher saves it for compliance review and
+// summarizes it inline.
+
+// sbomDir is where SBOM documents are saved, next to the JSON output.
+const sbomDir = "sbom"
+
+// SBOMSummary describes a repository's software bill of materials.
+type SBOMSummary struct {
+	// Path is the saved SPDX document.
+	Path string `json:"path"`
+	// Packages is the number of dependencies listed, excluding the repository itself.
+	Packages int `json:"packages"`
+	// Licenses are the distinct licenses declared by those dependencies.
+	Licenses []string `json:"licenses,omitempty"`
+}
+
+// sbomFetcher is implemented by providers that publish SBOMs.
+type sbomFetcher interface {
+	sbomURL(fullName string) string
+	// parseSBOM returns the SPDX document and its summary (without Path).
+	parseSBOM(body io.Reader) (json.RawMessage, SBOMSummary, error)
+}
+
+// enrichSBOM saves the repository's SBOM under sbomDir and fills in SBOM.
+func enrichSBOM(ctx context.Context, s *BaseRepoSearcher, item *RepositorySummary) error {
+	sf, ok := s.implementation.(sbomFetcher)
+	if !ok {
+		return errUnsupported
+	}
+	body, err := s.fetchDetail(ctx, sf.sbomURL(item.FullName))
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	doc, summary, err := sf.parseSBOM(body)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(sbomDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create SBOM directory: %w", err)
+	}
+	name := strings.ToLower(s.Source) + "_" + strings.ReplaceAll(item.FullName, "/", "_") + ".spdx.json"
+	summary.Path = filepath.Join(sbomDir, name)
+	if err := os.WriteFile(summary.Path, doc, 0o644); err != nil {
+		return fmt.Errorf("failed to write SBOM: %w", err)
+	}
+	item.SBOM = &summary
+	return nil
+}
+
+// sbomURL implements sbomFetcher.
+func (g *GitHubSearcher) sbomURL(fullName string) string {
+	return g.BaseURL + "/repos/" + fullName + "/dependency-graph/sbom"
+}
+
+// parseSBOM implements sbomFetcher. GitHub wraps the SPDX document in an
+// "sbom" object; the document DESCRIBES the repository's own package.
+func (g *GitHubSearcher) parseSBOM(body io.Reader) (json.RawMessage, SBOMSummary, error) {
+	var resp struct {
+		SBOM json.RawMessage `json:"sbom"`
+	}
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		return nil, SBOMSummary{}, fmt.Errorf("failed to unmarshal GitHub SBOM: %w", err)
+	}
+	var doc struct {
+		DocumentDescribes []string `json:"documentDescribes"`
+		Packages          []struct {
+			SPDXID           string `json:"SPDXID"`
+			LicenseDeclared  string `json:"licenseDeclared"`
+			LicenseConcluded string `json:"licenseConcluded"`
+		} `json:"packages"`
+	}
+	if err := json.Unmarshal(resp.SBOM, &doc); err != nil {
+		return nil, SBOMSummary{}, fmt.Errorf("failed to unmarshal SPDX document: %w", err)
+	}
+
+	var summary SBOMSummary
+	seen := make(map[string]bool)
+	for _, p := range doc.Packages {
+		if containsFold(doc.DocumentDescribes, p.SPDXID) {
+			continue
+		}
+		summary.Packages++
+		license := p.LicenseDeclared
+		if license == "" || license == "NOASSERTION" {
+			license = p.LicenseConcluded
+		}
+		if license != "" && license != "NOASSERTION" && !seen[license] {
+			seen[license] = true
+			summary.Licenses = append(summary.Licenses, license)
+		}
+	}
+	sort.Strings(summary.Licenses)
+	return resp.SBOM, summary, nil
+}
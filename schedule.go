@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+)
+
+// --- Scheduled Searches ---
+//
+// In serve mode, saved searches with an "every" interval run on their own.
+// Due searches start in priority order, at most -concurrency at a time; a
+// search that can't start yet stays due, ahead of lower-priority ones, until
+// a slot frees up.
+
+// scheduleTick is how often the scheduler looks for due searches.
+const scheduleTick = 30 * time.Second
+
+// scheduledSearch is one saved search that is due.
+type scheduledSearch struct {
+	tenant *tenant
+	name   string
+	search SavedSearch
+	due    time.Time
+}
+
+// key identifies the search across ticks.
+func (s scheduledSearch) key() string { return s.tenant.Name + "\n" + s.name }
+
+// dueSearches returns the scheduled searches due at now that aren't running,
+// highest priority first, then longest overdue.
+func (s *server) dueSearches(now time.Time, next map[string]time.Time, running map[string]bool) []scheduledSearch {
+	var due []scheduledSearch
+	for _, t := range s.tenants {
+		for name, search := range t.Searches {
+			if search.Every == "" {
+				continue
+			}
+			run := scheduledSearch{tenant: t, name: name, search: search, due: next[t.Name+"\n"+name]}
+			if !running[run.key()] && !run.due.After(now) {
+				due = append(due, run)
+			}
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].search.Priority != due[j].search.Priority {
+			return due[i].search.Priority > due[j].search.Priority
+		}
+		if !due[i].due.Equal(due[j].due) {
+			return due[i].due.Before(due[j].due)
+		}
+		return due[i].key() < due[j].key()
+	})
+	return due
+}
+
+// schedule runs the scheduled searches until ctx is done. Every search is
+// due when the server starts.
+func (s *server) schedule(ctx context.Context, concurrency int) {
+	next := make(map[string]time.Time)
+	running := make(map[string]bool)
+	done := make(chan string)
+	ticker := time.NewTicker(scheduleTick)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now()
+		for _, run := range s.dueSearches(now, next, running) {
+			if len(running) >= concurrency {
+				break
+			}
+			every, _ := time.ParseDuration(run.search.Every)
+			next[run.key()] = now.Add(every)
+			running[run.key()] = true
+			go func() {
+				s.runScheduled(ctx, run)
+				select {
+				case done <- run.key():
+				case <-ctx.Done():
+				}
+			}()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case key := <-done:
+			delete(running, key)
+		}
+	}
+}
+
+// runScheduled runs one scheduled search. Its outcome is kept as the saved
+// search's latest run.
+func (s *server) runScheduled(ctx context.Context, run scheduledSearch) {
+	t := run.tenant
+	searchers, err := s.tenantSearchers(t, run.search)
+	if err != nil {
+		log.Printf("Warning: %s: scheduled search %q: %v", t.Name, run.name, err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	result, err := s.search(ctx, t, run.name, searchers, run.search)
+	if err != nil {
+		log.Printf("Warning: %s: scheduled search %q failed: %v", t.Name, run.name, err)
+		return
+	}
+	log.Printf("%s: scheduled search %q retrieved %d repositories", t.Name, run.name, len(result.Items))
+}
@@ -0,0 +1,565 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --- Serve Mode ---
+//
+// `rexplorer serve` runs searches over HTTP for the tenants (users or teams)
+// listed in the config file. Each tenant authenticates with its own API key,
+// searches with its own provider tokens, has its own saved searches, and
+// spends its own request budget, so one deployment can serve a whole
+// organization without one team's crawl using up another's rate limit.
+
+// TenantConfig is one user or team allowed to use serve mode.
+type TenantConfig struct {
+	Name string `json:"name"`
+	// APIKeySHA256 is the hex SHA-256 of the tenant's API key, so the config
+	// file holds no usable secret (e.g. printf %s "$KEY" | sha256sum).
+	APIKeySHA256 string `json:"api_key_sha256"`
+	// Tokens maps service names to the environment variables holding the
+	// tenant's provider tokens. Services without one are searched
+	// unauthenticated; the server's own tokens are never used.
+	Tokens map[string]string `json:"tokens,omitempty"`
+	// Searches are the tenant's saved searches, by name.
+	Searches map[string]SavedSearch `json:"searches,omitempty"`
+	// RequestBudget is how many provider requests the tenant may send per
+	// BudgetWindow (default 1h); 0 means no limit.
+	RequestBudget int    `json:"request_budget,omitempty"`
+	BudgetWindow  string `json:"budget_window,omitempty"`
+}
+
+// SavedSearch is a search a tenant runs by name.
+type SavedSearch struct {
+	Service string `json:"service"`
+	Query   string `json:"query"`
+	Pages   int    `json:"pages,omitempty"`
+	// Every runs the search on a schedule, e.g. "6h" (see schedule).
+	Every string `json:"every,omitempty"`
+	// Priority orders scheduled runs, higher first. A search can't spend the
+	// budgets of the tenant's higher-priority searches (see tenant.reserved),
+	// so important monitors still run when a broad crawl has used up most of
+	// the tenant's requests.
+	Priority int `json:"priority,omitempty"`
+	// Budget caps the provider requests one run may send; 0 means no cap.
+	Budget int `json:"budget,omitempty"`
+	// Webhook receives the results of every run that changed them (see
+	// notifyWebhook), signed with the secret in WebhookSecretEnv if set.
+	Webhook          string `json:"webhook,omitempty"`
+	WebhookSecretEnv string `json:"webhook_secret_env,omitempty"`
+}
+
+// defaultBudgetWindow is the budget period when a tenant doesn't set one.
+const defaultBudgetWindow = time.Hour
+
+// validateTenants checks the tenants section of the config file and
+// normalizes the service names in their tokens.
+func validateTenants(tenants []TenantConfig) error {
+	names := make(map[string]bool)
+	keys := make(map[string]bool)
+	for i := range tenants {
+		t := &tenants[i]
+		if t.Name == "" {
+			return fmt.Errorf("tenants[%d]: name is required", i)
+		}
+		if names[t.Name] {
+			return fmt.Errorf("tenants: duplicate name %q", t.Name)
+		}
+		names[t.Name] = true
+		if sum, err := hex.DecodeString(t.APIKeySHA256); err != nil || len(sum) != sha256.Size {
+			return fmt.Errorf("tenants.%s.api_key_sha256: expected 64 hex digits", t.Name)
+		}
+		if keys[strings.ToLower(t.APIKeySHA256)] {
+			return fmt.Errorf("tenants.%s: API key already used by another tenant", t.Name)
+		}
+		keys[strings.ToLower(t.APIKeySHA256)] = true
+		tokens := make(map[string]string, len(t.Tokens))
+		for service, env := range t.Tokens {
+			if _, ok := lookupProvider(service); !ok {
+				return fmt.Errorf("tenants.%s.tokens: unknown service %q", t.Name, service)
+			}
+			tokens[strings.ToLower(service)] = env
+		}
+		t.Tokens = tokens
+		for name, s := range t.Searches {
+			for _, service := range resolveServices(s.Service) {
+				if _, ok := lookupProvider(service); !ok {
+					return fmt.Errorf("tenants.%s.searches.%s: unknown service %q", t.Name, name, service)
+				}
+			}
+			if s.Query == "" {
+				return fmt.Errorf("tenants.%s.searches.%s: query is required", t.Name, name)
+			}
+			if s.Every != "" {
+				if d, err := time.ParseDuration(s.Every); err != nil || d < time.Minute {
+					return fmt.Errorf("tenants.%s.searches.%s.every: expected a duration of at least 1m", t.Name, name)
+				}
+			}
+			if s.Budget < 0 {
+				return fmt.Errorf("tenants.%s.searches.%s.budget must not be negative", t.Name, name)
+			}
+			if s.Webhook != "" {
+				if u, err := url.Parse(s.Webhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+					return fmt.Errorf("tenants.%s.searches.%s.webhook: expected an http(s) URL", t.Name, name)
+				}
+			}
+		}
+		if t.RequestBudget < 0 {
+			return fmt.Errorf("tenants.%s.request_budget must not be negative", t.Name)
+		}
+		if t.BudgetWindow != "" {
+			if _, err := time.ParseDuration(t.BudgetWindow); err != nil {
+				return fmt.Errorf("tenants.%s.budget_window: %w", t.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// --- Request Budgets ---
+
+// errBudgetExhausted fails requests once a tenant's budget is spent.
+var errBudgetExhausted = errors.New("request budget exhausted")
+
+// requestBudget limits the provider requests sent in each fixed window.
+type requestBudget struct {
+	limit  int           // 0 is unlimited
+	window time.Duration // 0 never resets
+
+	mu    sync.Mutex
+	start time.Time
+	used  int
+}
+
+// roll starts a new window if the current one has ended. b.mu must be held.
+func (b *requestBudget) roll(now time.Time) {
+	if b.window > 0 && now.Sub(b.start) >= b.window {
+		b.start, b.used = now, 0
+	}
+}
+
+// take spends one request, reporting whether the budget allowed it. The
+// last reserve requests of the window are left for others.
+func (b *requestBudget) take(now time.Time, reserve int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.roll(now)
+	if b.limit > 0 && b.used >= b.limit-reserve {
+		return false
+	}
+	b.used++
+	return true
+}
+
+// status returns the requests used and the end of the current window.
+func (b *requestBudget) status(now time.Time) (used int, resets time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.roll(now)
+	return b.used, b.start.Add(b.window)
+}
+
+// Middleware refuses requests once the budget, less reserve, is spent.
+// Installed after the response cache, it only charges requests that reach
+// the network.
+func (b *requestBudget) Middleware(reserve int) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if !b.take(time.Now(), reserve) {
+				return nil, errBudgetExhausted
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// --- Server ---
+
+// tenant is a configured tenant with its runtime state.
+type tenant struct {
+	TenantConfig
+	key    []byte
+	budget *requestBudget
+	cache  *ResponseCache // Per tenant: responses depend on the token
+
+	mu       sync.Mutex
+	last     map[string]*savedRun // Latest run of each saved search
+	lastGood map[string]*savedRun // Latest successful run of each
+}
+
+// reserved returns the part of the tenant's budget set aside for saved
+// searches with a higher priority than priority: the sum of their budgets.
+func (t *tenant) reserved(priority int) int {
+	total := 0
+	for _, s := range t.Searches {
+		if s.Priority > priority {
+			total += s.Budget
+		}
+	}
+	return total
+}
+
+// server serves the search API.
+type server struct {
+	tenants  []*tenant
+	client   *http.Client
+	timeout  time.Duration
+	maxPages int
+	running  runningSearches
+
+	jobs       *jobQueue // Queued searches; nil in tests of the other routes
+	jobTimeout time.Duration
+}
+
+// newServer builds the server state for the configured tenants.
+func newServer(tenants []TenantConfig, client *http.Client, timeout time.Duration, maxPages int) *server {
+	s := &server{client: client, timeout: timeout, maxPages: maxPages}
+	for _, cfg := range tenants {
+		key, _ := hex.DecodeString(cfg.APIKeySHA256)
+		window := defaultBudgetWindow
+		if d, err := time.ParseDuration(cfg.BudgetWindow); err == nil {
+			window = d
+		}
+		s.tenants = append(s.tenants, &tenant{
+			TenantConfig: cfg, key: key,
+			budget: &requestBudget{limit: cfg.RequestBudget, window: window},
+			cache:  NewResponseCache(256, 10*time.Minute),
+			last:   make(map[string]*savedRun), lastGood: make(map[string]*savedRun),
+		})
+	}
+	return s
+}
+
+// handler returns the API routes.
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/search", s.authenticated(http.MethodGet, s.handleSearch))
+	mux.HandleFunc("/api/searches", s.authenticated(http.MethodGet, s.handleListSearches))
+	mux.HandleFunc("/api/searches/", s.authenticated("", s.handleSavedSearch))
+	mux.HandleFunc("/api/budget", s.authenticated(http.MethodGet, s.handleBudget))
+	mux.HandleFunc("/api/running", s.authenticated("", s.handleRunning))
+	mux.HandleFunc("/api/running/", s.authenticated("", s.handleRunning))
+	mux.HandleFunc("/badges/", s.handleBadge)
+	if s.jobs != nil {
+		mux.HandleFunc("/api/jobs", s.authenticated("", s.handleJobs))
+		mux.HandleFunc("/api/jobs/", s.authenticated("", s.handleJobs))
+		mux.HandleFunc("/api/results/", s.authenticated(http.MethodGet, s.handleResults))
+	}
+	return mux
+}
+
+// authenticate returns the tenant whose API key the request carries, in
+// the Authorization header as a bearer token or in X-API-Key.
+func (s *server) authenticate(r *http.Request) (*tenant, bool) {
+	key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		key = r.Header.Get("X-API-Key")
+	}
+	if key == "" {
+		return nil, false
+	}
+	sum := sha256.Sum256([]byte(key))
+	for _, t := range s.tenants {
+		if subtle.ConstantTimeCompare(sum[:], t.key) == 1 {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// authenticated wraps a handler that needs a tenant and accepts method
+// (any method if empty).
+func (s *server) authenticated(method string, h func(http.ResponseWriter, *http.Request, *tenant)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if method != "" && r.Method != method {
+			w.Header().Set("Allow", method)
+			writeAPIError(w, http.StatusMethodNotAllowed, "use "+method)
+			return
+		}
+		t, ok := s.authenticate(r)
+		if !ok {
+			writeAPIError(w, http.StatusUnauthorized, "missing or unknown API key")
+			return
+		}
+		h(w, r, t)
+	}
+}
+
+// handleSearch runs an ad hoc search: ?service=github&q=...&pages=3, plus
+// per_page etc. to keep the results and page through them (see results.go).
+func (s *server) handleSearch(w http.ResponseWriter, r *http.Request, t *tenant) {
+	q := r.URL.Query()
+	pages, _ := strconv.Atoi(q.Get("pages"))
+	s.runSearch(w, r, t, "", SavedSearch{Service: q.Get("service"), Query: q.Get("q"), Pages: pages})
+}
+
+// handleListSearches lists the tenant's saved searches.
+func (s *server) handleListSearches(w http.ResponseWriter, r *http.Request, t *tenant) {
+	searches := t.Searches
+	if searches == nil {
+		searches = map[string]SavedSearch{}
+	}
+	writeAPIJSON(w, http.StatusOK, searches)
+}
+
+// handleSavedSearch serves one of the tenant's saved searches:
+// POST /api/searches/{name}/run runs it, and GET /api/searches/{name}/last
+// returns its latest run.
+func (s *server) handleSavedSearch(w http.ResponseWriter, r *http.Request, t *tenant) {
+	name, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/searches/"), "/")
+	saved, ok := t.Searches[name]
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, fmt.Sprintf("no saved search %q", name))
+		return
+	}
+	switch {
+	case action == "run" && r.Method == http.MethodPost:
+		s.runSearch(w, r, t, name, saved)
+	case action == "last" && r.Method == http.MethodGet:
+		t.mu.Lock()
+		last := t.last[name]
+		t.mu.Unlock()
+		if last == nil {
+			writeAPIError(w, http.StatusNotFound, fmt.Sprintf("%q has not run yet", name))
+			return
+		}
+		writeAPIJSON(w, http.StatusOK, last)
+	default:
+		writeAPIError(w, http.StatusNotFound, "not found; use POST .../run or GET .../last")
+	}
+}
+
+// handleBudget reports the tenant's request budget.
+func (s *server) handleBudget(w http.ResponseWriter, r *http.Request, t *tenant) {
+	used, resets := t.budget.status(time.Now())
+	writeAPIJSON(w, http.StatusOK, map[string]any{
+		"tenant": t.Name, "limit": t.RequestBudget, "used": used, "resets_at": resets.UTC(),
+	})
+}
+
+// runSearch runs a search as the tenant and writes the result. name is the
+// saved search's name, or empty for an ad hoc search.
+func (s *server) runSearch(w http.ResponseWriter, r *http.Request, t *tenant, name string, search SavedSearch) {
+	if search.Query == "" {
+		writeAPIError(w, http.StatusBadRequest, "a query is required")
+		return
+	}
+	if !s.checkBudget(w, t, search) {
+		return
+	}
+	searchers, err := s.tenantSearchers(t, search)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// Asking for per_page keeps the results, and returns their first page.
+	paged := s.jobs != nil && r.URL.Query().Has("per_page")
+	params, err := parseResultPageParams(r.URL.Query())
+	if paged && err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+	defer cancel()
+	started := time.Now()
+	result, err := s.search(ctx, t, name, searchers, search)
+	if err != nil {
+		status := http.StatusBadGateway
+		if errors.Is(err, errBudgetExhausted) {
+			status = http.StatusTooManyRequests
+		}
+		writeAPIError(w, status, redactText(err.Error()))
+		return
+	}
+	if paged {
+		j, err := s.jobs.record(t.Name, name, search, started, result)
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("Location", "/api/results/"+j.ID)
+		writeResultPage(w, newResultPage(j, params), params)
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, result)
+}
+
+// checkBudget refuses a search, with 429 and Retry-After, if the tenant's
+// budget left for its priority is spent. It reports whether it may run.
+func (s *server) checkBudget(w http.ResponseWriter, t *tenant, search SavedSearch) bool {
+	if t.RequestBudget <= 0 {
+		return true
+	}
+	used, resets := t.budget.status(time.Now())
+	if used < t.RequestBudget-t.reserved(search.Priority) {
+		return true
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(resets).Seconds())+1))
+	writeAPIError(w, http.StatusTooManyRequests, "request budget exhausted until "+resets.UTC().Format(time.RFC3339))
+	return false
+}
+
+// search runs a search as the tenant, and records it as the latest run of
+// the saved search name, if any. It is listed as running, and can be
+// cancelled, until it returns.
+func (s *server) search(ctx context.Context, t *tenant, name string, searchers []searcherTemplate, search SavedSearch) (*SearchResult, error) {
+	pages := search.Pages
+	if pages <= 0 || pages > s.maxPages {
+		pages = s.maxPages
+	}
+	log.Printf("%s: searching %s for %q (max %d pages)", t.Name, cmp.Or(search.Service, "github"), search.Query, pages)
+	started := time.Now()
+	active, ctx := s.running.start(ctx, t, name, search)
+	for _, searcher := range searchers {
+		if b, ok := baseOf(searcher); ok {
+			b.OnEvent = active.observe
+		}
+	}
+	result, err := searchAll(ctx, searchers, search.Query, pages, false, 1)
+	if s.running.finish(active) {
+		log.Printf("%s: search %s for %q cancelled", t.Name, active.ID, search.Query)
+		if err != nil {
+			err = fmt.Errorf("search cancelled: %w", err)
+		} else {
+			result.Warnings = addWarning(result.Warnings, "search cancelled on request; results are partial")
+		}
+	}
+	if name != "" {
+		run := &savedRun{RanAt: started.UTC(), Result: result}
+		if err != nil {
+			run.Error = redactText(err.Error())
+		}
+		t.mu.Lock()
+		t.last[name] = run
+		// Failed runs don't replace the webhook's baseline.
+		prev := t.lastGood[name]
+		if err == nil {
+			t.lastGood[name] = run
+		}
+		t.mu.Unlock()
+		if err == nil && search.Webhook != "" {
+			go s.notifyWebhook(t, name, search, prev, run)
+		}
+	}
+	return result, err
+}
+
+// savedRun is the outcome of the latest run of a saved search.
+type savedRun struct {
+	RanAt  time.Time     `json:"ran_at"`
+	Result *SearchResult `json:"result,omitempty"`
+	Error  string        `json:"error,omitempty"`
+}
+
+// tenantSearchers builds the searchers for a search with the tenant's
+// tokens, cache and budget, and the search's own budget.
+func (s *server) tenantSearchers(t *tenant, search SavedSearch) ([]searcherTemplate, error) {
+	// One run budget is shared by all of the search's providers.
+	run := &requestBudget{limit: search.Budget}
+	reserve := t.reserved(search.Priority)
+	var searchers []searcherTemplate
+	for _, name := range resolveServices(cmp.Or(search.Service, "github")) {
+		p, ok := lookupProvider(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown service %q; must be one of %s", name, strings.Join(serviceNames(), ", "))
+		}
+		env := t.Tokens[p.Name]
+		token := ""
+		if env != "" {
+			token = os.Getenv(env)
+		}
+		if token == "" && p.TokenRequired {
+			return nil, fmt.Errorf("%s needs a token, and none is configured for tenant %s", p.Name, t.Name)
+		}
+		searcher, err := newSearcherWithToken(p, token, cmp.Or(env, "a token for "+t.Name), s.client, false)
+		if err != nil {
+			return nil, err
+		}
+		if b, ok := baseOf(searcher); ok {
+			b.Use(t.cache.Middleware(), run.Middleware(0), t.budget.Middleware(reserve))
+		}
+		searchers = append(searchers, searcher)
+	}
+	if len(searchers) == 0 {
+		return nil, fmt.Errorf("no service given")
+	}
+	return searchers, nil
+}
+
+// writeAPIJSON writes v as the JSON response.
+func writeAPIJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+}
+
+// writeAPIError writes an error response.
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	writeAPIJSON(w, status, map[string]string{"error": message})
+}
+
+// runServe implements `rexplorer serve`.
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:8080", "Address to listen on")
+	configFile := fs.String("config", "", "Configuration file with the tenants (default: ~/.config/rexplorer/config.json)")
+	timeout := fs.Duration("timeout", 2*time.Minute, "Timeout for each search")
+	maxPages := fs.Int("max-pages", 5, "Most pages a search may fetch per provider")
+	concurrency := fs.Int("concurrency", 2, "Scheduled searches run at the same time")
+	jobsDir := fs.String("jobs-dir", "", "Directory keeping queued search jobs and their results (default: jobs in the data directory)")
+	jobWorkers := fs.Int("job-workers", 2, "Queued jobs run at the same time")
+	jobTimeout := fs.Duration("job-timeout", time.Hour, "Timeout for each queued job")
+	jobRetention := fs.Duration("job-retention", 7*24*time.Hour, "How long finished jobs' results are kept (0 keeps them)")
+	fs.Parse(args)
+
+	cfg, err := loadConfig(*configFile)
+	if err != nil {
+		return err
+	}
+	if len(cfg.Tenants) == 0 {
+		return fmt.Errorf("no tenants configured; add a \"tenants\" section to the config file")
+	}
+	if err := useStore(cfg.Store); err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 30 * time.Second, Transport: newTransport(cfg.Transport.merge(defaultTransportConfig))}
+	s := newServer(cfg.Tenants, client, *timeout, *maxPages)
+	if *jobsDir == "" {
+		dir, err := dataDir()
+		if err != nil {
+			return err
+		}
+		*jobsDir = filepath.Join(dir, "jobs")
+	}
+	workers := max(1, *jobWorkers)
+	if s.jobs, err = openJobQueue(*jobsDir, *jobRetention, workers); err != nil {
+		return err
+	}
+	s.jobTimeout = *jobTimeout
+	go s.schedule(context.Background(), max(1, *concurrency))
+	s.runJobs(context.Background(), workers)
+	log.Printf("Serving %d tenants on %s", len(s.tenants), *addr)
+	srv := &http.Server{Addr: *addr, Handler: s.handler(), ReadHeaderTimeout: 10 * time.Second}
+	return srv.ListenAndServe()
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode"
+)
+
+// --- Similar Repositories ---
+
+// similarStopWords are description words too common to say anything about
+// what a project does.
+var similarStopWords = map[string]bool{
+	"that": true, "this": true, "with": true, "from": true, "your": true,
+	"into": true, "using": true, "used": true, "over": true, "simple": true,
+	"easy": true, "fast": true, "written": true, "based": true, "tool": true,
+	"tools": true, "library": true, "project": true, "more": true, "than": true,
+	"which": true, "have": true, "will": true, "also": true, "other": true,
+	"make": true, "some": true, "like": true, "just": true, "very": true,
+}
+
+// similarCandidate is a search result scored against the original.
+type similarCandidate struct {
+	RepositorySummary
+	Score        float64
+	SharedTopics []string
+}
+
+// runSimilar implements `rexplorer similar <full_name>`: it looks up a
+// repository, searches every selected provider for its topics and
+// description keywords, and ranks what comes back by overlap.
+func runSimilar(args []string) error {
+	fs := flag.NewFlagSet("similar", flag.ExitOnError)
+	from := fs.String("service", "github", "Service the original repository lives on")
+	across := fs.String("across", "github", "Service(s) to search: a name, a comma-separated list, or all")
+	pages := fs.Int("pages", 1, "Pages to fetch per search")
+	top := fs.Int("top", 20, "Number of similar repositories to show")
+	timeout := fs.Duration("timeout", 2*time.Minute, "Timeout for all searches")
+	rest, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return fmt.Errorf("usage: rexplorer similar [-service name] [-across list] <full_name>")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	source, err := newSearcher(*from, client, false)
+	if err != nil {
+		return err
+	}
+	sb, _ := baseOf(source)
+	detail, err := sb.fetchRepo(ctx, rest[0], "", "")
+	if err != nil {
+		return fmt.Errorf("failed to look up %s: %w", rest[0], err)
+	}
+	original := detail.Summary
+	topics := newTopicNormalizer(nil)
+	topics.apply([]RepositorySummary{original})
+
+	queries := similarQueries(original)
+	if len(queries) == 0 {
+		return fmt.Errorf("%s has no topics or description to search for", rest[0])
+	}
+	log.Printf("Searching for %s", strings.Join(queries, " | "))
+
+	var found []RepositorySummary
+	for _, name := range resolveServices(*across) {
+		searcher, err := newSearcher(name, client, false)
+		if err != nil {
+			log.Printf("Warning: skipping %s: %v", name, err)
+			continue
+		}
+		for _, q := range queries {
+			result, err := searcher.Search(ctx, q, *pages)
+			if err != nil {
+				log.Printf("Warning: search for %q failed: %v", q, err)
+				continue
+			}
+			found = append(found, result.Items...)
+		}
+	}
+	topics.apply(found)
+
+	ranked := rankSimilar(original, found)
+	if *top > 0 && len(ranked) > *top {
+		ranked = ranked[:*top]
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "SCORE\tREPOSITORY\tPROVIDER\tSTARS\tLANGUAGE\tSHARED TOPICS")
+	for _, c := range ranked {
+		fmt.Fprintf(w, "%.2f\t%s\t%s\t%s\t%s\t%s\n", c.Score, c.FullName, c.Provider,
+			formatCount(c.Stars, false), c.Language, strings.Join(c.SharedTopics, ", "))
+	}
+	return w.Flush()
+}
+
+// similarQueries picks searches likely to find alternatives: the leading
+// topics one at a time, then the most telling description words together.
+func similarQueries(repo RepositorySummary) []string {
+	var queries []string
+	for i, t := range repo.Topics {
+		if i == 3 {
+			break
+		}
+		queries = append(queries, t)
+	}
+	if words := descriptionKeywords(repo.Description, 3); len(words) > 0 {
+		queries = append(queries, strings.Join(words, " "))
+	}
+	return queries
+}
+
+// descriptionKeywords returns up to n distinctive words from a description,
+// in order of appearance.
+func descriptionKeywords(description string, n int) []string {
+	seen := make(map[string]bool)
+	var words []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+	}) {
+		if len(w) < 4 || similarStopWords[w] || seen[w] {
+			continue
+		}
+		seen[w] = true
+		words = append(words, w)
+		if len(words) == n {
+			break
+		}
+	}
+	return words
+}
+
+// rankSimilar scores candidates against the original and returns them best
+// first, without the original itself or duplicates. The score is the
+// Jaccard overlap of topics, plus a bonus for a shared language and for
+// shared description keywords.
+func rankSimilar(original RepositorySummary, candidates []RepositorySummary) []similarCandidate {
+	originalTopics := make(map[string]bool, len(original.Topics))
+	for _, t := range original.Topics {
+		originalTopics[t] = true
+	}
+	originalWords := make(map[string]bool)
+	for _, w := range descriptionKeywords(original.Description, 20) {
+		originalWords[w] = true
+	}
+
+	seen := map[string]bool{strings.ToLower(original.Provider + "/" + original.FullName): true}
+	var ranked []similarCandidate
+	for _, c := range candidates {
+		key := strings.ToLower(c.Provider + "/" + c.FullName)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		var shared []string
+		union := len(originalTopics)
+		for _, t := range c.Topics {
+			if originalTopics[t] {
+				shared = append(shared, t)
+			} else {
+				union++
+			}
+		}
+		score := 0.0
+		if union > 0 {
+			score = float64(len(shared)) / float64(union)
+		}
+		if c.Language != "" && c.Language != "Unknown" && c.Language == original.Language {
+			score += 0.2
+		}
+		if len(originalWords) > 0 {
+			common := 0
+			for _, w := range descriptionKeywords(c.Description, 20) {
+				if originalWords[w] {
+					common++
+				}
+			}
+			score += 0.3 * float64(common) / float64(len(originalWords))
+		}
+		ranked = append(ranked, similarCandidate{RepositorySummary: c, Score: score, SharedTopics: shared})
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].Stars > ranked[j].Stars
+	})
+	return ranked
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// --- Additional Output Sinks ---
+//
+// -also-write saves the results in more formats in the same run, e.g.
+// -also-write json:results.json,csv:results.csv next to the console view.
+
+// sinkFormats are the formats -also-write accepts.
+var sinkFormats = []string{"json", "csv", "text", "markdown", "html", "fragment", "ghorg", "gickup", "terraform", "backstage", "cyclonedx"}
+
+// outputSink is one -also-write target.
+type outputSink struct {
+	Format string
+	Path   string
+}
+
+// sinkList is the -also-write flag. It can be repeated, and each value may
+// hold several comma-separated format:path pairs.
+type sinkList []outputSink
+
+// String implements flag.Value.
+func (l *sinkList) String() string {
+	parts := make([]string, len(*l))
+	for i, s := range *l {
+		parts[i] = s.Format + ":" + s.Path
+	}
+	return strings.Join(parts, ",")
+}
+
+// Set implements flag.Value.
+func (l *sinkList) Set(value string) error {
+	for _, part := range splitList(value) {
+		format, path, ok := strings.Cut(part, ":")
+		format = strings.ToLower(strings.TrimSpace(format))
+		if format == "md" {
+			format = "markdown"
+		}
+		if !ok || strings.TrimSpace(path) == "" {
+			return fmt.Errorf("invalid sink %q: expected format:path", part)
+		}
+		if !slices.Contains(sinkFormats, format) {
+			return fmt.Errorf("unknown sink format %q; must be one of %s", format, strings.Join(sinkFormats, ", "))
+		}
+		*l = append(*l, outputSink{Format: format, Path: strings.TrimSpace(path)})
+	}
+	return nil
+}
+
+// write renders the results in the sink's format and saves them, encrypted
+// if enc is set. It returns the path written.
+func (s outputSink) write(result *SearchResult, opts renderOptions, enc *encrypter) (string, error) {
+	var buf bytes.Buffer
+	switch s.Format {
+	case "json":
+		data, err := marshalSummaries(result.Items)
+		if err != nil {
+			return "", err
+		}
+		buf.Write(data)
+	case "csv":
+		if err := writeCSV(&buf, opts.Clean.apply(result.Items)); err != nil {
+			return "", err
+		}
+	case "ghorg":
+		if err := writeGhorg(&buf, result.Items, result.Source); err != nil {
+			return "", err
+		}
+	case "gickup":
+		if err := writeGickup(&buf, result.Items, result.Source); err != nil {
+			return "", err
+		}
+	case "backstage":
+		if err := writeBackstage(&buf, result.Items, result.Source); err != nil {
+			return "", err
+		}
+	case "cyclonedx":
+		if err := writeCycloneDX(&buf, result.Items, result.Source); err != nil {
+			return "", err
+		}
+	case "terraform":
+		if err := writeTerraform(&buf, s.Path, result.Items, result.Source); err != nil {
+			return "", err
+		}
+	default:
+		if err := render(&buf, s.Format, result.Items, result.Source, opts); err != nil {
+			return "", err
+		}
+	}
+	return writeArtifact(s.Path, buf.Bytes(), enc)
+}
+
+// csvColumns are the columns of the CSV output, one per summary field.
+var csvColumns = []string{"provider", "full_name", "url", "description", "language", "stars", "forks",
+	"open_issues", "license", "topics", "created_at", "updated_at", "is_fork", "is_archived", "tags"}
+
+// writeCSV writes the results as CSV with a header row. List fields are
+// joined with semicolons.
+func writeCSV(w io.Writer, items []RepositorySummary) error {
+	cw := csv.NewWriter(w)
+	cw.Write(csvColumns)
+	for _, s := range items {
+		cw.Write([]string{
+			s.Provider, s.FullName, s.URL, s.Description, s.Language,
+			strconv.Itoa(s.Stars), strconv.Itoa(s.Forks), strconv.Itoa(s.OpenIssuesCount),
+			s.License, strings.Join(s.Topics, ";"), s.CreatedAt, s.UpdatedAt,
+			strconv.FormatBool(s.IsFork), strconv.FormatBool(s.IsArchived), strings.Join(s.Tags, ";"),
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,430 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"html/template"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// --- Static Catalog Site ---
+//
+// `rexplorer site build -out site/ [results.json...]` turns what rexplorer
+// knows about repositories into a static catalog any web server can host:
+// an index of every repository, indexes by language and topic, and a page
+// per repository with its details and a chart of its stars and forks over
+// time. Repositories come from the imported snapshots in the data directory
+// (see import.go), the watch list, and any result files given; each
+// repository's latest appearance supplies its details, and every
+// appearance a point of its trend.
+
+// sitePoint is one observation of a repository.
+type sitePoint struct {
+	At    time.Time
+	Stars int
+	Forks int
+}
+
+// siteRepo is one repository in the catalog.
+type siteRepo struct {
+	RepositorySummary
+	Page   string // Path of its page, relative to the site root
+	Seen   time.Time
+	Points []sitePoint
+}
+
+// siteCatalog collects the repositories, keyed by provider and name.
+type siteCatalog struct {
+	repos map[string]*siteRepo
+}
+
+// add records an appearance of item at the given time.
+func (c *siteCatalog) add(service string, item RepositorySummary, at time.Time) {
+	item.Provider = cmp.Or(item.Provider, service)
+	if item.FullName == "" {
+		return
+	}
+	key := strings.ToLower(item.Provider + "/" + item.FullName)
+	r, ok := c.repos[key]
+	if !ok {
+		r = &siteRepo{Page: path.Join("repo", siteSlug(item.Provider), siteRepoPath(item.FullName)+".html")}
+		c.repos[key] = r
+	}
+	// Without a time, e.g. never refreshed, it only fills in missing details.
+	if at.IsZero() {
+		if r.FullName == "" {
+			r.RepositorySummary = item
+		}
+		return
+	}
+	if !at.Before(r.Seen) {
+		r.RepositorySummary, r.Seen = item, at
+	}
+	// The same run may be both imported and passed as a file.
+	if !slices.ContainsFunc(r.Points, func(p sitePoint) bool { return p.At.Equal(at) }) {
+		r.Points = append(r.Points, sitePoint{At: at, Stars: item.Stars, Forks: item.Forks})
+	}
+}
+
+// sorted returns the repositories, most stars first.
+func (c *siteCatalog) sorted() []*siteRepo {
+	repos := make([]*siteRepo, 0, len(c.repos))
+	for _, r := range c.repos {
+		slices.SortFunc(r.Points, func(a, b sitePoint) int { return a.At.Compare(b.At) })
+		repos = append(repos, r)
+	}
+	slices.SortFunc(repos, func(a, b *siteRepo) int {
+		return cmp.Or(cmp.Compare(b.Stars, a.Stars), strings.Compare(strings.ToLower(a.FullName), strings.ToLower(b.FullName)))
+	})
+	return repos
+}
+
+// loadSiteCatalog gathers the repositories from the snapshots, the watch
+// list and the given result files.
+func loadSiteCatalog(files []string) (*siteCatalog, error) {
+	c := &siteCatalog{repos: make(map[string]*siteRepo)}
+	dir, err := dataDir()
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := filepath.Glob(filepath.Join(dir, snapshotsDir, "*", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range snapshots {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshot: %w", err)
+		}
+		var snap resultSnapshot
+		if err := json.Unmarshal(data, &snap); err != nil {
+			log.Printf("Warning: skipping unreadable snapshot %s: %v", file, err)
+			continue
+		}
+		for _, item := range snap.Items {
+			c.add(snap.Service, item, snap.RanAt)
+		}
+	}
+
+	watched, err := loadWatchList()
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range watched {
+		if e.Summary != nil {
+			c.add(e.Service, *e.Summary, e.CheckedAt)
+		}
+	}
+
+	for _, file := range files {
+		items, err := loadSummaries(file)
+		if err != nil {
+			return nil, err
+		}
+		at, err := importedRanAt(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			c.add("", item, at)
+		}
+	}
+	return c, nil
+}
+
+// siteSlug makes a name safe for a file name: lowercase letters, digits,
+// dots, underscores and dashes.
+func siteSlug(name string) string {
+	slug := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '_' {
+			return unicode.ToLower(r)
+		}
+		return '-'
+	}, name)
+	slug = strings.Trim(slug, "-.")
+	if slug == "" {
+		return "unnamed"
+	}
+	return slug
+}
+
+// siteRepoPath is a repository's page path below its provider's folder,
+// one folder per namespace level.
+func siteRepoPath(fullName string) string {
+	parts := strings.Split(fullName, "/")
+	for i, p := range parts {
+		parts[i] = siteSlug(p)
+	}
+	return path.Join(parts...)
+}
+
+// siteGroup is a language or topic with its repositories.
+type siteGroup struct {
+	Name  string
+	Page  string
+	Repos []*siteRepo
+}
+
+// siteGroups groups the repositories by the names key returns, largest
+// group first.
+func siteGroups(repos []*siteRepo, folder string, key func(*siteRepo) []string) []*siteGroup {
+	byName := make(map[string]*siteGroup)
+	slugs := make(map[string]bool)
+	var groups []*siteGroup
+	for _, r := range repos {
+		for _, name := range key(r) {
+			if name == "" {
+				continue
+			}
+			g, ok := byName[strings.ToLower(name)]
+			if !ok {
+				// C, C# and C++ share a slug; later ones get a number.
+				slug := siteSlug(name)
+				for i := 2; slugs[slug]; i++ {
+					slug = fmt.Sprintf("%s-%d", siteSlug(name), i)
+				}
+				slugs[slug] = true
+				g = &siteGroup{Name: name, Page: path.Join(folder, slug+".html")}
+				byName[strings.ToLower(name)] = g
+				groups = append(groups, g)
+			}
+			g.Repos = append(g.Repos, r)
+		}
+	}
+	slices.SortFunc(groups, func(a, b *siteGroup) int {
+		return cmp.Or(cmp.Compare(len(b.Repos), len(a.Repos)), strings.Compare(a.Name, b.Name))
+	})
+	return groups
+}
+
+// siteChart is a trend line, laid out for the chart template.
+type siteChart struct {
+	Label    string
+	Points   string // SVG polyline points
+	Min, Max string
+	From, To string
+}
+
+// Chart size, in SVG units.
+const (
+	siteChartWidth  = 600
+	siteChartHeight = 150
+)
+
+// newSiteChart plots value over the repository's points. It returns nil
+// with fewer than two points, which make no trend.
+func newSiteChart(label string, points []sitePoint, value func(sitePoint) int) *siteChart {
+	if len(points) < 2 {
+		return nil
+	}
+	first, last := points[0].At, points[len(points)-1].At
+	lo, hi := value(points[0]), value(points[0])
+	for _, p := range points {
+		lo, hi = min(lo, value(p)), max(hi, value(p))
+	}
+	span := last.Sub(first).Seconds()
+	var coords []string
+	for _, p := range points {
+		x := 0.0
+		if span > 0 {
+			x = p.At.Sub(first).Seconds() / span * siteChartWidth
+		}
+		y := float64(siteChartHeight) / 2
+		if hi > lo {
+			y = siteChartHeight - float64(value(p)-lo)/float64(hi-lo)*siteChartHeight
+		}
+		coords = append(coords, fmt.Sprintf("%.1f,%.1f", x, y))
+	}
+	return &siteChart{
+		Label: label, Points: strings.Join(coords, " "),
+		Min: formatCount(lo, true), Max: formatCount(hi, true),
+		From: first.Format("2006-01-02"), To: last.Format("2006-01-02"),
+	}
+}
+
+// siteTemplates renders the catalog pages. Every page gets Root, the
+// relative path back to the site root, so the site works from any folder.
+var siteTemplates = template.Must(template.New("site").Funcs(template.FuncMap{
+	"count": func(n int) string { return formatCount(n, true) },
+}).Parse(`{{define "head"}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} - {{.Site}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em auto; max-width: 72em; padding: 0 1em; }
+nav a { margin-right: 1em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border-bottom: 1px solid #ddd; padding: 4px 8px; text-align: left; vertical-align: top; }
+td.num { text-align: right; }
+ul.cloud { list-style: none; padding: 0; }
+ul.cloud li { display: inline-block; margin: 0 1em 0.5em 0; }
+svg.chart { border: 1px solid #ddd; background: #fafafa; }
+</style>
+</head>
+<body>
+<nav><a href="{{.Root}}index.html">{{.Site}}</a><a href="{{.Root}}languages.html">Languages</a><a href="{{.Root}}topics.html">Topics</a></nav>
+<h1>{{.Title}}</h1>
+{{end}}
+{{define "foot"}}<p><small>Generated {{.Generated}}</small></p>
+</body>
+</html>
+{{end}}
+{{define "table"}}<table>
+<tr><th>Repository</th><th>Provider</th><th>Language</th><th>Stars</th><th>Updated</th><th>Description</th></tr>
+{{range .Repos}}<tr><td><a href="{{$.Root}}{{.Page}}">{{.FullName}}</a>{{if .IsArchived}} (archived){{end}}</td><td>{{.Provider}}</td><td>{{.Language}}</td><td class="num">{{count .Stars}}</td><td>{{.UpdatedAt}}</td><td>{{.Description}}</td></tr>
+{{end}}</table>
+{{end}}
+{{define "list"}}{{template "head" .}}{{template "table" .}}{{template "foot" .}}{{end}}
+{{define "groups"}}{{template "head" .}}<ul class="cloud">
+{{range .Groups}}<li><a href="{{$.Root}}{{.Page}}">{{.Name}}</a> ({{len .Repos}})</li>
+{{end}}</ul>
+{{template "foot" .}}{{end}}
+{{define "repo"}}{{template "head" .}}{{with .Repo}}<p>{{.Description}}</p>
+<table>
+<tr><th>Repository</th><td><a href="{{.URL}}">{{.URL}}</a></td></tr>
+<tr><th>Provider</th><td>{{.Provider}}</td></tr>
+{{if .Language}}<tr><th>Language</th><td>{{.Language}}</td></tr>
+{{end}}<tr><th>Stars</th><td>{{count .Stars}}</td></tr>
+<tr><th>Forks</th><td>{{count .Forks}}</td></tr>
+<tr><th>Open issues</th><td>{{count .OpenIssuesCount}}</td></tr>
+{{if .License}}<tr><th>License</th><td>{{.License}}</td></tr>
+{{end}}{{if .Topics}}<tr><th>Topics</th><td>{{range $i, $t := .Topics}}{{if $i}}, {{end}}{{$t}}{{end}}</td></tr>
+{{end}}{{if .Homepage}}<tr><th>Homepage</th><td><a href="{{.Homepage}}">{{.Homepage}}</a></td></tr>
+{{end}}<tr><th>Created</th><td>{{.CreatedAt}}</td></tr>
+<tr><th>Updated</th><td>{{.UpdatedAt}}</td></tr>
+{{if .IsArchived}}<tr><th>Archived</th><td>yes{{if .SuccessorURL}}; continued at <a href="{{.SuccessorURL}}">{{.SuccessorURL}}</a>{{end}}</td></tr>
+{{end}}</table>
+{{end}}<h2>Trend</h2>
+{{range .Charts}}<h3>{{.Label}}</h3>
+<svg class="chart" xmlns="http://www.w3.org/2000/svg" width="640" height="190" viewBox="-20 -20 640 190">
+<polyline fill="none" stroke="#0366d6" stroke-width="2" points="{{.Points}}"/>
+<text x="0" y="-6" font-size="11">{{.Max}}</text><text x="0" y="164" font-size="11">{{.Min}} · {{.From}}</text><text x="600" y="164" font-size="11" text-anchor="end">{{.To}}</text>
+</svg>
+{{else}}<p>Not enough history for a trend yet: each import, watch refresh or result file adds a point.</p>
+{{end}}{{template "foot" .}}{{end}}
+`))
+
+// sitePage is the data every page template gets.
+type sitePage struct {
+	Site      string
+	Title     string
+	Root      string
+	Generated string
+	Repos     []*siteRepo
+	Groups    []*siteGroup
+	Repo      *siteRepo
+	Charts    []*siteChart
+}
+
+// writeSitePage renders one page to out/rel.
+func writeSitePage(out, rel, name string, page sitePage) error {
+	page.Root = strings.Repeat("../", strings.Count(rel, "/"))
+	file := filepath.Join(out, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("failed to create site directory: %w", err)
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", file, err)
+	}
+	if err := siteTemplates.ExecuteTemplate(f, name, page); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to render %s: %w", file, err)
+	}
+	return f.Close()
+}
+
+// buildSite writes the catalog of repos to out, and returns the number of
+// pages written.
+func buildSite(out, title string, repos []*siteRepo, now time.Time) (int, error) {
+	base := sitePage{Site: title, Generated: now.UTC().Format("2006-01-02 15:04 MST")}
+	languages := siteGroups(repos, "language", func(r *siteRepo) []string {
+		if r.Language == "Unknown" {
+			return nil
+		}
+		return []string{r.Language}
+	})
+	topics := siteGroups(repos, "topic", func(r *siteRepo) []string { return r.Topics })
+
+	pages := 0
+	write := func(rel, name string, page sitePage) error {
+		pages++
+		return writeSitePage(out, rel, name, page)
+	}
+	index := base
+	index.Title, index.Repos = fmt.Sprintf("%d repositories", len(repos)), repos
+	if err := write("index.html", "list", index); err != nil {
+		return pages, err
+	}
+	for _, g := range []struct {
+		rel, title string
+		groups     []*siteGroup
+	}{{"languages.html", "Languages", languages}, {"topics.html", "Topics", topics}} {
+		page := base
+		page.Title, page.Groups = g.title, g.groups
+		if err := write(g.rel, "groups", page); err != nil {
+			return pages, err
+		}
+		for _, group := range g.groups {
+			page := base
+			page.Title, page.Repos = fmt.Sprintf("%s (%d)", group.Name, len(group.Repos)), group.Repos
+			if err := write(group.Page, "list", page); err != nil {
+				return pages, err
+			}
+		}
+	}
+	for _, r := range repos {
+		page := base
+		page.Title, page.Repo = r.FullName, r
+		for _, chart := range []*siteChart{
+			newSiteChart("Stars", r.Points, func(p sitePoint) int { return p.Stars }),
+			newSiteChart("Forks", r.Points, func(p sitePoint) int { return p.Forks }),
+		} {
+			if chart != nil {
+				page.Charts = append(page.Charts, chart)
+			}
+		}
+		if err := write(r.Page, "repo", page); err != nil {
+			return pages, err
+		}
+	}
+	return pages, nil
+}
+
+// runSite implements `rexplorer site build`.
+func runSite(args []string) error {
+	if len(args) == 0 || args[0] != "build" {
+		return fmt.Errorf("usage: rexplorer site build [-out dir] [-title text] [results.json...]")
+	}
+	fs := flag.NewFlagSet("site build", flag.ExitOnError)
+	out := fs.String("out", "site", "Directory to write the site to")
+	title := fs.String("title", "Repository catalog", "Title of the site")
+	files, err := parseInterspersed(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	catalog, err := loadSiteCatalog(files)
+	if err != nil {
+		return err
+	}
+	repos := catalog.sorted()
+	if len(repos) == 0 {
+		return fmt.Errorf("no repositories to catalog; import results, watch repositories or pass result files")
+	}
+	pages, err := buildSite(*out, *title, repos, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %d pages for %d repositories to %s\n", pages, len(repos), *out)
+	return nil
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// --- Spill-to-Disk Accumulation ---
+//
+// A crawl of tens of thousands of repositories repeats many of them from
+// page to page as the index shifts. A resultSpool keeps at most limit items
+// in memory while pages arrive: each time the buffer fills it is sorted and
+// written to a temporary "run" file. Duplicates are dropped with an
+// external merge sort over the runs, and a second merge restores the order
+// the items arrived in.
+//
+// The spool bounds memory during the crawl and the deduplication only.
+// Items returns the unique results as one slice, which the caller filters,
+// sorts and renders in memory, so the peak still grows with the number of
+// distinct repositories found.
+
+// spoolEntry is an item with its arrival position.
+type spoolEntry struct {
+	Seq  int               `json:"seq"`
+	Item RepositorySummary `json:"item"`
+}
+
+// resultSpool accumulates search results, spilling to disk past limit items.
+type resultSpool struct {
+	limit int
+	dir   string // Created on the first spill
+	buf   []spoolEntry
+	runs  []string
+	next  int
+}
+
+// newResultSpool creates a spool keeping at most limit items in memory.
+func newResultSpool(limit int) *resultSpool {
+	return &resultSpool{limit: limit}
+}
+
+// byIdentity orders entries so duplicates are adjacent, earliest first.
+func byIdentity(a, b spoolEntry) bool {
+	if a.Item.Provider != b.Item.Provider {
+		return a.Item.Provider < b.Item.Provider
+	}
+	if ka, kb := strings.ToLower(a.Item.FullName), strings.ToLower(b.Item.FullName); ka != kb {
+		return ka < kb
+	}
+	return a.Seq < b.Seq
+}
+
+// bySeq orders entries by arrival.
+func bySeq(a, b spoolEntry) bool { return a.Seq < b.Seq }
+
+// sameRepo reports whether two entries are the same repository on the same
+// provider. Items without a full name are never considered duplicates.
+func sameRepo(a, b spoolEntry) bool {
+	return a.Item.FullName != "" && a.Item.Provider == b.Item.Provider &&
+		strings.EqualFold(a.Item.FullName, b.Item.FullName)
+}
+
+// Add appends items, spilling the buffer to disk when it is full.
+func (sp *resultSpool) Add(items []RepositorySummary) error {
+	for _, item := range items {
+		sp.buf = append(sp.buf, spoolEntry{Seq: sp.next, Item: item})
+		sp.next++
+		if len(sp.buf) >= sp.limit {
+			if err := sp.spill(byIdentity); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// spill sorts the buffer and writes it out as a new run.
+func (sp *resultSpool) spill(less func(a, b spoolEntry) bool) error {
+	if len(sp.buf) == 0 {
+		return nil
+	}
+	if sp.dir == "" {
+		dir, err := os.MkdirTemp("", "rexplorer-spool-")
+		if err != nil {
+			return fmt.Errorf("failed to create spool directory: %w", err)
+		}
+		sp.dir = dir
+	}
+	sort.Slice(sp.buf, func(i, j int) bool { return less(sp.buf[i], sp.buf[j]) })
+
+	path := filepath.Join(sp.dir, fmt.Sprintf("run-%04d.jsonl", len(sp.runs)))
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create spool run: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, e := range sp.buf {
+		if err := enc.Encode(e); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to write spool run: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write spool run: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write spool run: %w", err)
+	}
+	sp.runs = append(sp.runs, path)
+	sp.buf = sp.buf[:0]
+	return nil
+}
+
+// Items returns everything added, in arrival order, keeping only the first
+// occurrence of each repository per provider. The spool is empty afterwards.
+func (sp *resultSpool) Items() ([]RepositorySummary, error) {
+	if len(sp.runs) == 0 {
+		sort.Slice(sp.buf, func(i, j int) bool { return byIdentity(sp.buf[i], sp.buf[j]) })
+		var unique []spoolEntry
+		for i, e := range sp.buf {
+			if i == 0 || !sameRepo(sp.buf[i-1], e) {
+				unique = append(unique, e)
+			}
+		}
+		sort.Slice(unique, func(i, j int) bool { return bySeq(unique[i], unique[j]) })
+		sp.buf = nil
+		return entryItems(unique), nil
+	}
+
+	// Pass 1: merge the identity-sorted runs, dropping duplicates, into new
+	// runs sorted by arrival.
+	if err := sp.spill(byIdentity); err != nil {
+		return nil, err
+	}
+	identityRuns := sp.runs
+	sp.runs = nil
+	var prev *spoolEntry
+	err := mergeRuns(identityRuns, byIdentity, func(e spoolEntry) error {
+		if prev != nil && sameRepo(*prev, e) {
+			return nil
+		}
+		prev = &e
+		sp.buf = append(sp.buf, e)
+		if len(sp.buf) >= sp.limit {
+			return sp.spill(bySeq)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := sp.spill(bySeq); err != nil {
+		return nil, err
+	}
+
+	// Pass 2: merge by arrival.
+	var items []RepositorySummary
+	err = mergeRuns(sp.runs, bySeq, func(e spoolEntry) error {
+		items = append(items, e.Item)
+		return nil
+	})
+	return items, err
+}
+
+// Close removes the spool's temporary files.
+func (sp *resultSpool) Close() error {
+	if sp.dir == "" {
+		return nil
+	}
+	return os.RemoveAll(sp.dir)
+}
+
+// entryItems strips the arrival positions.
+func entryItems(entries []spoolEntry) []RepositorySummary {
+	items := make([]RepositorySummary, len(entries))
+	for i, e := range entries {
+		items[i] = e.Item
+	}
+	return items
+}
+
+// runReader is one open run in a k-way merge.
+type runReader struct {
+	f    *os.File
+	dec  *json.Decoder
+	head spoolEntry
+}
+
+// runHeap orders open runs by their head entry.
+type runHeap struct {
+	readers []*runReader
+	less    func(a, b spoolEntry) bool
+}
+
+func (h *runHeap) Len() int           { return len(h.readers) }
+func (h *runHeap) Less(i, j int) bool { return h.less(h.readers[i].head, h.readers[j].head) }
+func (h *runHeap) Swap(i, j int)      { h.readers[i], h.readers[j] = h.readers[j], h.readers[i] }
+func (h *runHeap) Push(x any)         { h.readers = append(h.readers, x.(*runReader)) }
+func (h *runHeap) Pop() any {
+	r := h.readers[len(h.readers)-1]
+	h.readers = h.readers[:len(h.readers)-1]
+	return r
+}
+
+// mergeRuns calls fn with the entries of the sorted runs in less order.
+func mergeRuns(paths []string, less func(a, b spoolEntry) bool, fn func(spoolEntry) error) error {
+	h := &runHeap{less: less}
+	defer func() {
+		for _, r := range h.readers {
+			r.f.Close()
+		}
+	}()
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open spool run: %w", err)
+		}
+		r := &runReader{f: f, dec: json.NewDecoder(bufio.NewReader(f))}
+		if err := r.dec.Decode(&r.head); err != nil {
+			f.Close()
+			if errors.Is(err, io.EOF) {
+				continue
+			}
+			return fmt.Errorf("failed to read spool run: %w", err)
+		}
+		h.readers = append(h.readers, r)
+	}
+	heap.Init(h)
+
+	for h.Len() > 0 {
+		r := h.readers[0]
+		if err := fn(r.head); err != nil {
+			return err
+		}
+		r.head = spoolEntry{}
+		err := r.dec.Decode(&r.head)
+		switch {
+		case errors.Is(err, io.EOF):
+			heap.Pop(h)
+			r.f.Close()
+		case err != nil:
+			return fmt.Errorf("failed to read spool run: %w", err)
+		default:
+			heap.Fix(h, 0)
+		}
+	}
+	return nil
+}
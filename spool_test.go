@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestSpoolDropsDuplicatesAcrossRuns(t *testing.T) {
+	sp := newResultSpool(2)
+	defer sp.Close()
+	repo := func(provider, name string) RepositorySummary {
+		return RepositorySummary{Provider: provider, FullName: name, Stars: len(name)}
+	}
+	pages := [][]RepositorySummary{
+		{repo("GitHub", "o/a"), repo("GitHub", "o/b")},
+		{repo("GitHub", "o/c"), repo("GitHub", "O/A")}, // o/a again, in another run
+		{repo("GitLab", "o/a"), repo("GitHub", "o/b")},
+		{repo("GitHub", "o/d")},
+	}
+	for _, page := range pages {
+		if err := sp.Add(page); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(sp.runs) < 2 {
+		t.Fatalf("spilled %d runs, want several", len(sp.runs))
+	}
+
+	items, err := sp.Items()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, item := range items {
+		got = append(got, item.Provider+" "+item.FullName)
+	}
+	want := []string{"GitHub o/a", "GitHub o/b", "GitHub o/c", "GitLab o/a", "GitHub o/d"}
+	if !slices.Equal(got, want) {
+		t.Errorf("items = %v, want %v", got, want)
+	}
+
+	dir := sp.dir
+	sp.Close()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("spool directory %s left behind", dir)
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// --- Local Store ---
+//
+// Durable state that isn't configuration (bookmarks, history, ...) lives
+// under the data directory, one JSON document per kind of data, or in a
+// shared bucket so a team sees the same history, watch list and bookmarks.
+
+// dataDir returns the directory holding rexplorer's local store, following
+// the XDG base directory convention.
+func dataDir() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "rexplorer"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate data directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "share", "rexplorer"), nil
+}
+
+// storeBackend holds the store documents. The default is the local data
+// directory; a team can share one store through the config file's "store"
+// section instead (see StoreConfig).
+type storeBackend interface {
+	// load returns a document's content, or an error wrapping
+	// os.ErrNotExist if there is none.
+	load(name string) ([]byte, error)
+	// save replaces a document.
+	save(name string, data []byte) error
+	// location describes where a document lives, for messages.
+	location(name string) string
+}
+
+// StoreConfig selects where the store documents live.
+type StoreConfig struct {
+	// Backend is "local" (the default) or "s3".
+	Backend string `json:"backend,omitempty"`
+	// Bucket, Prefix, Region and Endpoint locate the documents for the s3
+	// backend. Endpoint defaults to AWS; set it for S3-compatible services.
+	Bucket   string `json:"bucket,omitempty"`
+	Prefix   string `json:"prefix,omitempty"`
+	Region   string `json:"region,omitempty"`
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// storeBackends are the accepted StoreConfig.Backend values.
+var storeBackends = []string{"local", "s3"}
+
+// validate checks the backend and its settings.
+func (c StoreConfig) validate() error {
+	switch c.Backend {
+	case "", "local":
+		return nil
+	case "s3":
+		if c.Bucket == "" || c.Region == "" {
+			return fmt.Errorf("store: the s3 backend needs a bucket and a region")
+		}
+		if c.Endpoint != "" {
+			if u, err := url.Parse(c.Endpoint); err != nil || u.Host == "" {
+				return fmt.Errorf("store.endpoint: invalid URL %q", c.Endpoint)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("store.backend: unknown backend %q; must be one of %s", c.Backend, strings.Join(storeBackends, ", "))
+	}
+}
+
+// open returns the backend the configuration selects.
+func (c StoreConfig) open() (storeBackend, error) {
+	if c.Backend == "s3" {
+		return newS3Store(c)
+	}
+	dir, err := dataDir()
+	if err != nil {
+		return nil, err
+	}
+	return localStore{dir: dir}, nil
+}
+
+var (
+	storeMu sync.Mutex
+	store   storeBackend
+)
+
+// useStore selects the store backend for the rest of the run. A search calls
+// it with the -config file's settings; subcommands get the default config's.
+func useStore(c StoreConfig) error {
+	backend, err := c.open()
+	if err != nil {
+		return err
+	}
+	storeMu.Lock()
+	store = backend
+	storeMu.Unlock()
+	return nil
+}
+
+// currentStore returns the selected backend, selecting it from the default
+// config file on first use.
+func currentStore() (storeBackend, error) {
+	storeMu.Lock()
+	backend := store
+	storeMu.Unlock()
+	if backend != nil {
+		return backend, nil
+	}
+	cfg, err := loadConfig("")
+	if err != nil {
+		return nil, err
+	}
+	if err := useStore(cfg.Store); err != nil {
+		return nil, err
+	}
+	return currentStore()
+}
+
+// readStoreJSON decodes the named store document into v. A missing document
+// leaves v untouched.
+func readStoreJSON(name string, v any) error {
+	backend, err := currentStore()
+	if err != nil {
+		return err
+	}
+	data, err := backend.load(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", backend.location(name), err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", backend.location(name), err)
+	}
+	return nil
+}
+
+// storeLocks serializes the writes to each store document within this
+// process, e.g. serve's concurrent saved-search runs recording history.
+var storeLocks sync.Map // Document name to *sync.Mutex
+
+// lockStoreDocument locks the named document and returns the unlock function.
+func lockStoreDocument(name string) func() {
+	mu, _ := storeLocks.LoadOrStore(name, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return mu.(*sync.Mutex).Unlock
+}
+
+// writeStoreJSON replaces the named store document with v.
+func writeStoreJSON(name string, v any) error {
+	defer lockStoreDocument(name)()
+	return saveStoreJSON(name, v)
+}
+
+// updateStoreJSON reads the named store document into v, lets change
+// modify it and writes it back, with no other write to the document in
+// between. A missing document leaves v untouched before change.
+func updateStoreJSON(name string, v any, change func() error) error {
+	defer lockStoreDocument(name)()
+	if err := readStoreJSON(name, v); err != nil {
+		return err
+	}
+	if err := change(); err != nil {
+		return err
+	}
+	return saveStoreJSON(name, v)
+}
+
+// saveStoreJSON writes v as the named document; the caller holds its lock.
+func saveStoreJSON(name string, v any) error {
+	backend, err := currentStore()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", name, err)
+	}
+	return backend.save(name, data)
+}
+
+// --- Local Store Backend ---
+
+// localStore keeps the documents as files in a directory.
+type localStore struct {
+	dir string
+}
+
+// load implements storeBackend.
+func (l localStore) load(name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(l.dir, name))
+}
+
+// save implements storeBackend. The document is written to a temporary file
+// of its own first, so a crash never leaves it half-written and concurrent
+// saves never write through the same file.
+func (l localStore) save(name string, data []byte) error {
+	if err := os.MkdirAll(l.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+	path := filepath.Join(l.dir, name)
+	tmp, err := os.CreateTemp(l.dir, name+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name()) // Fails harmlessly once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
+
+// location implements storeBackend.
+func (l localStore) location(name string) string {
+	return filepath.Join(l.dir, name)
+}
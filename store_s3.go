@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --- S3 Store Backend ---
+//
+// The s3 backend keeps the store documents as objects in a bucket on AWS S3
+// or any S3-compatible service (MinIO, R2, Ceph, ...), so a team's scheduled
+// scans and interactive runs share one history, watch list and bookmarks.
+// Credentials come from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and, for
+// temporary credentials, AWS_SESSION_TOKEN. Writes replace the whole
+// document, so when two runs save the same document the later one wins.
+// Without ListBucket permission S3 answers a missing object with 403, not
+// 404; a document that was never read or written is then taken as absent.
+//
+// A Postgres backend would need a database driver, which this build does
+// not include; a SQLite database replicated with litestream can't be
+// shared for writing either, so S3 is the supported shared backend.
+
+// s3Store is a storeBackend on an S3 bucket.
+type s3Store struct {
+	base   *url.URL // Endpoint and bucket, path-style
+	prefix string
+	region string
+
+	accessKey, secretKey, sessionToken string
+
+	client *http.Client
+	now    func() time.Time
+
+	mu    sync.Mutex
+	known map[string]bool // Documents read or written by this run
+}
+
+// newS3Store creates the backend for c, which has been validated.
+func newS3Store(c StoreConfig) (*s3Store, error) {
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + c.Region + ".amazonaws.com"
+	}
+	base, err := url.Parse(strings.TrimSuffix(endpoint, "/") + "/" + c.Bucket + "/")
+	if err != nil {
+		return nil, fmt.Errorf("invalid store endpoint: %w", err)
+	}
+	s := &s3Store{
+		base: base, prefix: c.Prefix, region: c.Region,
+		accessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		client:       &http.Client{Timeout: 30 * time.Second},
+		now:          time.Now,
+		known:        make(map[string]bool),
+	}
+	if s.accessKey == "" || s.secretKey == "" {
+		return nil, fmt.Errorf("the s3 store needs AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+	return s, nil
+}
+
+// objectURL returns the URL of a document's object.
+func (s *s3Store) objectURL(name string) *url.URL {
+	return s.base.JoinPath(s.prefix, name)
+}
+
+// load implements storeBackend.
+func (s *s3Store) load(name string) ([]byte, error) {
+	resp, err := s.do(http.MethodGet, s.objectURL(name), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden && !s.isKnown(name) {
+		return nil, os.ErrNotExist
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, firstLine(body))
+	}
+	s.markKnown(name)
+	return body, nil
+}
+
+// save implements storeBackend.
+func (s *s3Store) save(name string, data []byte) error {
+	resp, err := s.do(http.MethodPut, s.objectURL(name), data)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", s.location(name), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to write %s: unexpected status %d: %s", s.location(name), resp.StatusCode, firstLine(body))
+	}
+	s.markKnown(name)
+	return nil
+}
+
+// isKnown reports whether the document was read or written before, so a
+// 403 for it is a real refusal rather than a missing object.
+func (s *s3Store) isKnown(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.known[name]
+}
+
+// markKnown records that the document exists.
+func (s *s3Store) markKnown(name string) {
+	s.mu.Lock()
+	s.known[name] = true
+	s.mu.Unlock()
+}
+
+// location implements storeBackend.
+func (s *s3Store) location(name string) string {
+	return s.objectURL(name).String()
+}
+
+// do sends a request signed with AWS Signature Version 4.
+func (s *s3Store) do(method string, u *url.URL, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	s.sign(req, body, s.now().UTC())
+	return s.client.Do(req)
+}
+
+// sign adds the SigV4 headers for a request with the given payload.
+func (s *s3Store) sign(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.sessionToken)
+	}
+
+	names := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	values := map[string]string{"host": req.URL.Host, "x-amz-content-sha256": payloadHash, "x-amz-date": amzDate}
+	if s.sessionToken != "" {
+		names = append(names, "x-amz-security-token")
+		values["x-amz-security-token"] = s.sessionToken
+	}
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + values[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonicalRequest := strings.Join([]string{
+		req.Method, req.URL.EscapedPath(), canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(), signedHeaders, payloadHash,
+	}, "\n")
+
+	scope := day + "/" + s.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+	key := []byte("AWS4" + s.secretKey)
+	for _, part := range []string{day, s.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, signature))
+}
+
+// canonicalQuery encodes query parameters the way SigV4 signs them: sorted,
+// with every value present (lifecycle=) and spaces as %20.
+func canonicalQuery(q url.Values) string {
+	var parts []string
+	for _, key := range slices.Sorted(maps.Keys(q)) {
+		values := slices.Sorted(slices.Values(q[key]))
+		for _, v := range values {
+			parts = append(parts, awsEscape(key)+"="+awsEscape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// awsEscape percent-encodes everything but unreserved characters.
+func awsEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// sha256Hex returns the hex SHA-256 of data.
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 returns HMAC-SHA256(key, data).
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// firstLine returns the first line of an error body, for messages.
+func firstLine(body []byte) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(string(body)), "\n")
+	return line
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConcurrentHistoryWritesKeepEveryRun(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	if err := useStore(StoreConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		storeMu.Lock()
+		store = nil
+		storeMu.Unlock()
+	})
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := recordRun(runRecord{Service: "github", Query: fmt.Sprintf("q%d", i), RanAt: time.Now()}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	history, err := loadHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 20 {
+		t.Errorf("history has %d runs, want 20", len(history))
+	}
+	dir, _ := dataDir()
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 {
+		t.Errorf("data directory holds %d files, want just the history", len(files))
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// --- Successors of Archived Repositories ---
+//
+// Archived projects often say where development continued ("moved to ...",
+// "superseded by ..."). Surfacing that link as SuccessorURL keeps users from
+// adopting a dead project when a maintained one exists.
+
+// successorPattern finds a hand-off phrase followed, within the same
+// paragraph, by a link. The link may lack a scheme (github.com/owner/repo).
+var successorPattern = regexp.MustCompile(`(?i)(?:moved|migrated|relocated|continued|transferred)\s+(?:to|at|over to)|succe(?:ssor|eded by)|superseded\s+by|replaced\s+by|new\s+home|maintained\s+fork|deprecated\s+in\s+favou?r\s+of`)
+
+// successorLink matches the first link after a hand-off phrase.
+var successorLink = regexp.MustCompile(`(?i)(https?://[^\s)\]>"'<]+|(?:github\.com|gitlab\.com|gitee\.com|gitcode\.com|bitbucket\.org|codeberg\.org)/[\w.-]+/[\w.-]+)`)
+
+// findSuccessor returns the successor link in text, or "". The link must
+// follow a hand-off phrase within 200 bytes, on the same paragraph.
+func findSuccessor(text string) string {
+	for _, loc := range successorPattern.FindAllStringIndex(text, -1) {
+		window := text[loc[1]:min(len(text), loc[1]+200)]
+		if para := strings.Index(window, "\n\n"); para >= 0 {
+			window = window[:para]
+		}
+		link := successorLink.FindString(window)
+		if link == "" {
+			continue
+		}
+		link = strings.TrimRight(link, ".,;:!")
+		link = strings.TrimSuffix(link, ".git")
+		if !strings.Contains(link, "://") {
+			link = "https://" + link
+		}
+		if _, err := url.Parse(link); err == nil {
+			return link
+		}
+	}
+	return ""
+}
+
+// markSuccessors fills in SuccessorURL for archived items from their
+// descriptions. It sends no requests; see enrichSuccessor for READMEs.
+func markSuccessors(items []RepositorySummary) int {
+	n := 0
+	for i := range items {
+		if items[i].IsArchived && items[i].SuccessorURL == "" {
+			if link := findSuccessor(items[i].Description); link != "" && !sameRepoURL(link, items[i].URL) {
+				items[i].SuccessorURL = link
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// sameRepoURL reports whether two links point at the same repository page.
+func sameRepoURL(a, b string) bool {
+	norm := func(s string) string {
+		return strings.TrimSuffix(strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(s, "https://"), "http://")), "/")
+	}
+	return norm(a) == norm(b)
+}
+
+// readmeFetcher is implemented by providers that can return a README.
+type readmeFetcher interface {
+	readmeURL(fullName string) string
+	parseReadme(body io.Reader) (string, error)
+}
+
+// enrichSuccessor looks for a successor link in the README of archived
+// repositories whose description didn't have one.
+func enrichSuccessor(ctx context.Context, s *BaseRepoSearcher, item *RepositorySummary) error {
+	if !item.IsArchived || item.SuccessorURL != "" {
+		return nil
+	}
+	rf, ok := s.implementation.(readmeFetcher)
+	if !ok {
+		return errUnsupported
+	}
+	body, err := s.fetchDetail(ctx, rf.readmeURL(item.FullName))
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	readme, err := rf.parseReadme(body)
+	if err != nil {
+		return err
+	}
+	if link := findSuccessor(readme); link != "" && !sameRepoURL(link, item.URL) {
+		item.SuccessorURL = link
+	}
+	return nil
+}
+
+// readmeURL implements readmeFetcher.
+func (g *GitHubSearcher) readmeURL(fullName string) string {
+	return g.BaseURL + "/repos/" + fullName + "/readme"
+}
+
+// parseReadme implements readmeFetcher. GitHub returns the file base64
+// encoded inside a JSON document.
+func (g *GitHubSearcher) parseReadme(body io.Reader) (string, error) {
+	var file struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	if err := json.NewDecoder(body).Decode(&file); err != nil {
+		return "", fmt.Errorf("failed to unmarshal GitHub README: %w", err)
+	}
+	if file.Encoding != "base64" {
+		return file.Content, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(file.Content, "\n", ""))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode GitHub README: %w", err)
+	}
+	return string(data), nil
+}
+
+// readmeURL implements readmeFetcher. GitLab serves the raw file.
+func (g *GitLabSearcher) readmeURL(fullName string) string {
+	return g.BaseURL + "/projects/" + url.PathEscape(fullName) + "/repository/files/README.md/raw?ref=HEAD"
+}
+
+// parseReadme implements readmeFetcher.
+func (g *GitLabSearcher) parseReadme(body io.Reader) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read GitLab README: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// --- Tag Rules ---
+
+// TagRule adds Tag to every result that satisfies all of its conditions.
+// Conditions left empty are ignored; a rule must have at least one.
+//
+// Example:
+//
+//	{"tag": "cncf", "topic": "kubernetes"}
+//	{"tag": "stale", "updated_older_than": "2y"}
+type TagRule struct {
+	Tag string `json:"tag"`
+	// Topic matches results with a topic containing this text.
+	Topic string `json:"topic,omitempty"`
+	// Language matches results in this language.
+	Language string `json:"language,omitempty"`
+	// Match is a regex tested against the full name and description.
+	Match string `json:"match,omitempty"`
+	// UpdatedOlderThan matches results not updated within this age (see parseAge).
+	UpdatedOlderThan string `json:"updated_older_than,omitempty"`
+	// MinStars matches results with at least this many stars.
+	MinStars int `json:"min_stars,omitempty"`
+
+	match    *regexp.Regexp
+	olderAge time.Duration
+}
+
+// compile validates the rule and prepares its regex and age.
+func (r *TagRule) compile() error {
+	if r.Tag == "" {
+		return fmt.Errorf("missing tag")
+	}
+	if r.Topic == "" && r.Language == "" && r.Match == "" && r.UpdatedOlderThan == "" && r.MinStars == 0 {
+		return fmt.Errorf("rule has no conditions")
+	}
+	if r.Match != "" {
+		re, err := regexp.Compile("(?i)" + r.Match)
+		if err != nil {
+			return fmt.Errorf("invalid match regex: %w", err)
+		}
+		r.match = re
+	}
+	if r.UpdatedOlderThan != "" {
+		age, err := parseAge(r.UpdatedOlderThan)
+		if err != nil {
+			return err
+		}
+		r.olderAge = age
+	}
+	return nil
+}
+
+// matches reports whether s satisfies every condition of the rule.
+func (r *TagRule) matches(s RepositorySummary, now time.Time) bool {
+	if r.Topic != "" {
+		found := false
+		for _, t := range s.Topics {
+			if strings.Contains(strings.ToLower(t), strings.ToLower(r.Topic)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if r.Language != "" && !strings.EqualFold(s.Language, r.Language) {
+		return false
+	}
+	if r.match != nil && !r.match.MatchString(s.FullName) && !r.match.MatchString(s.Description) {
+		return false
+	}
+	if r.UpdatedOlderThan != "" {
+		updated, ok := parseTimestamp(s.UpdatedAt)
+		if !ok || now.Sub(updated) <= r.olderAge {
+			return false
+		}
+	}
+	if r.MinStars > 0 && s.Stars < r.MinStars {
+		return false
+	}
+	return true
+}
+
+// applyTagRules adds the tags of every matching rule to each item.
+// Rules must have been compiled (loadConfig does this).
+func applyTagRules(rules []TagRule, items []RepositorySummary, now time.Time) {
+	for i := range items {
+		for j := range rules {
+			if rules[j].matches(items[i], now) && !containsFold(items[i].Tags, rules[j].Tag) {
+				items[i].Tags = append(items[i].Tags, rules[j].Tag)
+			}
+		}
+	}
+}
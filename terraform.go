@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// --- Terraform Export ---
+//
+// The terraform sink writes the results as Terraform/OpenTofu JSON, so
+// infrastructure code can provision mirrors, webhooks or scanning jobs for
+// every repository found, with for_each over a map keyed by
+// "<provider>/<full_name>":
+//
+//	-also-write terraform:repos.tf.json      a locals block: local.rexplorer_repositories
+//	-also-write terraform:repos.auto.tfvars.json
+//	                                         a value for var.rexplorer_repositories
+//
+// A path ending in .tfvars.json gets the variable form; declare the
+// variable as map(object({...})) with the fields of terraformRepo.
+
+// terraformName is the name of the local value or variable.
+const terraformName = "rexplorer_repositories"
+
+// terraformRepo is one repository as Terraform sees it. Every field is
+// always present, so the map fits a single object type.
+type terraformRepo struct {
+	Provider      string   `json:"provider"`
+	FullName      string   `json:"full_name"`
+	Owner         string   `json:"owner"`
+	Name          string   `json:"name"`
+	URL           string   `json:"url"`
+	Description   string   `json:"description"`
+	DefaultBranch string   `json:"default_branch"`
+	Language      string   `json:"language"`
+	License       string   `json:"license"`
+	Topics        []string `json:"topics"`
+	Stars         int      `json:"stars"`
+	Archived      bool     `json:"archived"`
+	Fork          bool     `json:"fork"`
+	Private       bool     `json:"private"`
+}
+
+// writeTerraform writes the results as a .tf.json locals block, or as a
+// .tfvars.json variable value if path says so.
+func writeTerraform(w io.Writer, path string, items []RepositorySummary, source string) error {
+	repos := make(map[string]terraformRepo, len(items))
+	for _, s := range items {
+		provider := strings.ToLower(s.Provider)
+		if provider == "" {
+			provider = strings.ToLower(source)
+		}
+		owner, name, _ := cutLast(s.FullName, "/")
+		repos[provider+"/"+s.FullName] = terraformRepo{
+			Provider: provider, FullName: s.FullName, Owner: owner, Name: name, URL: s.URL,
+			Description: s.Description, DefaultBranch: s.DefaultBranch, Language: s.Language,
+			License: spdxLicense(s.License), Topics: append([]string{}, sortedLabels(s.Topics)...),
+			Stars: s.Stars, Archived: s.IsArchived, Fork: s.IsFork, Private: s.IsPrivate,
+		}
+	}
+
+	var doc any = map[string]any{terraformName: repos}
+	if !strings.HasSuffix(strings.ToLower(path), ".tfvars.json") {
+		doc = map[string]any{
+			"//":     fmt.Sprintf("Generated by rexplorer: %d repositories from %s", len(items), source),
+			"locals": map[string]any{terraformName: repos},
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode Terraform JSON: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// --- Adaptive Throttling ---
+
+// throttle limits concurrent requests to one provider, adapting the limit
+// to the rate-limit headers the provider sends back: full concurrency while
+// plenty of quota remains, less as it runs low, and a pause until the reset
+// time once it is used up.
+type throttle struct {
+	max int
+
+	mu          sync.Mutex
+	limit       int
+	inflight    int
+	pausedUntil time.Time
+}
+
+// newThrottle creates a throttle allowing up to max concurrent requests.
+func newThrottle(max int) *throttle {
+	if max < 1 {
+		max = 1
+	}
+	return &throttle{max: max, limit: max}
+}
+
+// acquire waits for a request slot.
+func (t *throttle) acquire(ctx context.Context) error {
+	for {
+		t.mu.Lock()
+		wait := time.Until(t.pausedUntil)
+		if wait <= 0 && t.inflight < t.limit {
+			t.inflight++
+			t.mu.Unlock()
+			return nil
+		}
+		t.mu.Unlock()
+		if err := sleepCtx(ctx, max(wait, 50*time.Millisecond)); err != nil {
+			return err
+		}
+	}
+}
+
+// release returns a slot taken by acquire.
+func (t *throttle) release() {
+	t.mu.Lock()
+	t.inflight--
+	t.mu.Unlock()
+}
+
+// observe adjusts the limit from a response's rate-limit headers. GitHub,
+// Gitee and GitCode send X-RateLimit-*, GitLab sends RateLimit-*.
+func (t *throttle) observe(h http.Header, now time.Time) {
+	remaining, okRemaining := headerInt(h, "X-RateLimit-Remaining", "RateLimit-Remaining")
+	limit, okLimit := headerInt(h, "X-RateLimit-Limit", "RateLimit-Limit")
+	if !okRemaining {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if remaining == 0 {
+		if reset, ok := headerInt(h, "X-RateLimit-Reset", "RateLimit-Reset"); ok {
+			t.pausedUntil = time.Unix(int64(reset), 0)
+		} else {
+			t.pausedUntil = now.Add(defaultCoolDown)
+		}
+		t.limit = 1
+		return
+	}
+	if !okLimit || limit <= 0 {
+		return
+	}
+	// Keep full concurrency above half the quota, then scale down with it.
+	t.limit = min(t.max, max(1, t.max*remaining*2/limit))
+}
+
+// headerInt returns the first of the named headers that holds an integer.
+func headerInt(h http.Header, names ...string) (int, bool) {
+	for _, name := range names {
+		if n, err := strconv.Atoi(h.Get(name)); err == nil {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// Middleware returns middleware feeding every response to observe.
+func (t *throttle) Middleware() Middleware {
+	return ObserveResponse(func(_ *http.Request, resp *http.Response, err error) {
+		if err == nil {
+			t.observe(resp.Header, time.Now())
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// --- Topic Normalization ---
+
+// topicAliases maps common topic spellings to one canonical topic.
+// Config.TopicAliases extends and overrides it.
+var topicAliases = map[string]string{
+	"k8s":          "kubernetes",
+	"golang":       "go",
+	"js":           "javascript",
+	"nodejs":       "node",
+	"node-js":      "node",
+	"ts":           "typescript",
+	"py":           "python",
+	"python3":      "python",
+	"ml":           "machine-learning",
+	"ai":           "artificial-intelligence",
+	"llms":         "llm",
+	"cli-app":      "cli",
+	"command-line": "cli",
+	"commandline":  "cli",
+	"tui-app":      "tui",
+	"vuejs":        "vue",
+	"vue-js":       "vue",
+	"reactjs":      "react",
+	"react-js":     "react",
+}
+
+// topicNormalizer canonicalizes topics.
+type topicNormalizer struct {
+	aliases map[string]string
+}
+
+// newTopicNormalizer combines the built-in aliases with user overrides.
+func newTopicNormalizer(overrides map[string]string) topicNormalizer {
+	aliases := make(map[string]string, len(topicAliases)+len(overrides))
+	for k, v := range topicAliases {
+		aliases[k] = v
+	}
+	for k, v := range overrides {
+		aliases[canonicalTopicSpelling(k)] = canonicalTopicSpelling(v)
+	}
+	return topicNormalizer{aliases: aliases}
+}
+
+// canonicalTopicSpelling lowercases a topic and joins words with hyphens,
+// the form GitHub uses.
+func canonicalTopicSpelling(topic string) string {
+	return strings.Join(strings.Fields(strings.ToLower(topic)), "-")
+}
+
+// normalize returns the canonical form of a topic.
+func (n topicNormalizer) normalize(topic string) string {
+	topic = canonicalTopicSpelling(topic)
+	if canonical, ok := n.aliases[topic]; ok {
+		return canonical
+	}
+	return topic
+}
+
+// apply normalizes every item's topics, dropping duplicates the aliases
+// create.
+func (n topicNormalizer) apply(items []RepositorySummary) {
+	for i := range items {
+		topics := items[i].Topics
+		seen := make(map[string]bool, len(topics))
+		kept := topics[:0]
+		for _, t := range topics {
+			t = n.normalize(t)
+			if t == "" || seen[t] {
+				continue
+			}
+			seen[t] = true
+			kept = append(kept, intern(t))
+		}
+		items[i].Topics = kept
+	}
+}
+
+// --- Topic Report ---
+
+// topicCount is how many results carry a topic.
+type topicCount struct {
+	Topic string
+	Count int
+}
+
+// topTopics counts topics across items, most common first (ties by name),
+// keeping at most n.
+func topTopics(items []RepositorySummary, n int) []topicCount {
+	counts := make(map[string]int)
+	for _, item := range items {
+		for _, t := range item.Topics {
+			counts[t]++
+		}
+	}
+	top := make([]topicCount, 0, len(counts))
+	for t, c := range counts {
+		top = append(top, topicCount{Topic: t, Count: c})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].Topic < top[j].Topic
+	})
+	if n > 0 && len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
+
+// writeTopicsReport prints the most common topics with their share of the
+// result set.
+func writeTopicsReport(w io.Writer, items []RepositorySummary, n int) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TOPIC\tREPOSITORIES\tSHARE")
+	for _, tc := range topTopics(items, n) {
+		fmt.Fprintf(tw, "%s\t%s\t%.1f%%\n", tc.Topic, formatCount(tc.Count, false), float64(tc.Count)*100/float64(len(items)))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// --- Transport Tuning ---
+
+// TransportConfig tunes the HTTP connection pool shared by all providers.
+// The net/http defaults keep only two idle connections per host, so a
+// parallel crawl keeps opening and closing connections.
+type TransportConfig struct {
+	// MaxIdleConnsPerHost is the number of idle connections kept per host.
+	MaxIdleConnsPerHost int `json:"max_idle_conns_per_host,omitempty"`
+	// MaxConnsPerHost caps all connections per host (0 means no limit).
+	MaxConnsPerHost int `json:"max_conns_per_host,omitempty"`
+	// IdleConnTimeout is how long an idle connection is kept, e.g. "90s".
+	IdleConnTimeout string `json:"idle_conn_timeout,omitempty"`
+	// ForceHTTP2 attempts HTTP/2 even with a customized transport.
+	ForceHTTP2 *bool `json:"force_http2,omitempty"`
+}
+
+// defaultTransportConfig is used for settings neither the config file nor
+// the flags set.
+var defaultTransportConfig = TransportConfig{
+	MaxIdleConnsPerHost: 16,
+	IdleConnTimeout:     "90s",
+}
+
+// validate checks the values JSON decoding doesn't.
+func (c TransportConfig) validate() error {
+	if c.IdleConnTimeout != "" {
+		if _, err := time.ParseDuration(c.IdleConnTimeout); err != nil {
+			return fmt.Errorf("transport.idle_conn_timeout: %w", err)
+		}
+	}
+	if c.MaxIdleConnsPerHost < 0 || c.MaxConnsPerHost < 0 {
+		return fmt.Errorf("transport: connection counts must not be negative")
+	}
+	return nil
+}
+
+// merge returns c with its unset fields taken from fallback.
+func (c TransportConfig) merge(fallback TransportConfig) TransportConfig {
+	if c.MaxIdleConnsPerHost == 0 {
+		c.MaxIdleConnsPerHost = fallback.MaxIdleConnsPerHost
+	}
+	if c.MaxConnsPerHost == 0 {
+		c.MaxConnsPerHost = fallback.MaxConnsPerHost
+	}
+	if c.IdleConnTimeout == "" {
+		c.IdleConnTimeout = fallback.IdleConnTimeout
+	}
+	if c.ForceHTTP2 == nil {
+		c.ForceHTTP2 = fallback.ForceHTTP2
+	}
+	return c
+}
+
+// newTransport builds a transport from the defaults with c applied. The
+// configuration has already been checked by validate.
+func newTransport(c TransportConfig) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if c.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = c.MaxIdleConnsPerHost
+		t.MaxIdleConns = max(t.MaxIdleConns, c.MaxIdleConnsPerHost*len(providers))
+	}
+	t.MaxConnsPerHost = c.MaxConnsPerHost
+	if d, err := time.ParseDuration(c.IdleConnTimeout); err == nil {
+		t.IdleConnTimeout = d
+	}
+	if c.ForceHTTP2 != nil {
+		t.ForceAttemptHTTP2 = *c.ForceHTTP2
+	}
+	return t
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// --- Canonical Upstreams ---
+//
+// A popular project can show up in results as hundreds of trivial forks.
+// -resolve-forks follows each fork's parent chain to the repository at the
+// top and either annotates the fork (UpstreamURL) or replaces it with that
+// upstream.
+
+// maxForkDepth bounds the parent chain walk, in case of cycles.
+const maxForkDepth = 10
+
+// resolveForksModes are the accepted -resolve-forks values.
+var resolveForksModes = []string{"annotate", "replace"}
+
+// upstreamResolver walks fork chains, remembering every repository it looks
+// up so forks of the same project share the work. It is safe for concurrent
+// use by enrichment workers.
+type upstreamResolver struct {
+	mu    sync.Mutex
+	repos map[string]RepositorySummary // By nodeID
+	roots map[string]string            // Fork nodeID -> upstream nodeID
+}
+
+// newUpstreamResolver creates an empty resolver.
+func newUpstreamResolver() *upstreamResolver {
+	return &upstreamResolver{repos: make(map[string]RepositorySummary), roots: make(map[string]string)}
+}
+
+// enricher returns the enricher that annotates forks with their upstream.
+func (r *upstreamResolver) enricher() enricher {
+	return enricher{Name: "upstream", Description: "canonical upstream of forks", Enrich: r.enrich}
+}
+
+// lookup returns a repository's details, from memory if it was seen before.
+func (r *upstreamResolver) lookup(ctx context.Context, s *BaseRepoSearcher, fullName string) (RepositorySummary, error) {
+	id := nodeID(s.Source, fullName)
+	r.mu.Lock()
+	repo, ok := r.repos[id]
+	r.mu.Unlock()
+	if ok {
+		return repo, nil
+	}
+	detail, err := s.fetchRepo(ctx, fullName, "", "")
+	if err != nil {
+		return RepositorySummary{}, err
+	}
+	r.mu.Lock()
+	r.repos[id] = detail.Summary
+	r.mu.Unlock()
+	return detail.Summary, nil
+}
+
+// enrich implements enricher: it fills in ParentFullName and UpstreamURL.
+func (r *upstreamResolver) enrich(ctx context.Context, s *BaseRepoSearcher, item *RepositorySummary) error {
+	if !item.IsFork {
+		return nil
+	}
+	cur := *item
+	for depth := 0; depth < maxForkDepth; depth++ {
+		parent := cur.ParentFullName
+		if parent == "" {
+			detail, err := r.lookup(ctx, s, cur.FullName)
+			if err != nil {
+				return err
+			}
+			parent = detail.ParentFullName
+		}
+		if parent == "" {
+			break
+		}
+		if depth == 0 {
+			item.ParentFullName = parent
+		}
+		next, err := r.lookup(ctx, s, parent)
+		if err != nil {
+			return fmt.Errorf("failed to follow fork parent %s: %w", parent, err)
+		}
+		cur = next
+		if !cur.IsFork {
+			break
+		}
+	}
+	if strings.EqualFold(cur.FullName, item.FullName) {
+		return nil // Parent unknown
+	}
+	item.UpstreamURL = cur.URL
+	r.mu.Lock()
+	r.roots[nodeID(item.Provider, item.FullName)] = nodeID(s.Source, cur.FullName)
+	r.mu.Unlock()
+	return nil
+}
+
+// replace swaps every resolved fork for its upstream, keeping only the first
+// occurrence of each repository. It returns the new items and the number of
+// forks replaced.
+func (r *upstreamResolver) replace(items []RepositorySummary) ([]RepositorySummary, int) {
+	seen := make(map[string]bool, len(items))
+	out := make([]RepositorySummary, 0, len(items))
+	replaced := 0
+	for _, item := range items {
+		id := nodeID(item.Provider, item.FullName)
+		if root, ok := r.roots[id]; ok {
+			upstream := r.repos[root]
+			upstream.Provider = item.Provider
+			item, id = upstream, root
+			replaced++
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, item)
+	}
+	return out, replaced
+}
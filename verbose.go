@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// --- Verbose Progress ---
+//
+// With -verbose, every page gets a one-line summary: how many results it
+// held and how many were new, the running total against the provider's,
+// how long it took, and how many requests the provider's rate limit has
+// left. Long crawls can be followed without debug logging.
+
+// pageStats returns an OnEvent callback that logs page statistics, then
+// passes every event on to next (if set).
+func pageStats(next func(SearchEvent)) func(SearchEvent) {
+	return func(e SearchEvent) {
+		if e.Kind == EventPageCompleted {
+			log.Print(formatPageStats(e))
+		}
+		if next != nil {
+			next(e)
+		}
+	}
+}
+
+// formatPageStats renders one page-completed event.
+func formatPageStats(e SearchEvent) string {
+	line := fmt.Sprintf("%s page %d: %d items (%d new, %d duplicate) | %s so far",
+		e.Source, e.Page, e.Items+e.Duplicates, e.Items, e.Duplicates, formatCount(e.Retrieved, false))
+	if e.TotalCount >= 0 {
+		line += " of " + formatCount(e.TotalCount, false)
+	}
+	line += " | " + e.Elapsed.Round(10*time.Millisecond).String()
+	if e.RateRemaining >= 0 {
+		line += " | " + formatCount(e.RateRemaining, false) + " requests left"
+	}
+	return line
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+)
+
+// --- Data-Quality Warnings ---
+//
+// Some providers leave fields out, ignore a setting, or stop before the end
+// of the results. None of that fails a search, but it changes what the
+// results mean, so each condition is recorded in SearchResult.Warnings and
+// shown after the summary.
+
+// caveatReporter is implemented by providers whose results are known to be
+// incomplete under their current configuration.
+type caveatReporter interface {
+	// searchCaveats describes what the results will lack, without the provider name.
+	searchCaveats() []string
+}
+
+// warn records a warning for the searcher's next results, once.
+func (s *BaseRepoSearcher) warn(format string, args ...any) {
+	s.notes = addWarning(s.notes, s.Source+": "+fmt.Sprintf(format, args...))
+}
+
+// addWarning appends w to warnings unless it is already there.
+func addWarning(warnings []string, w ...string) []string {
+	for _, item := range w {
+		if !slices.Contains(warnings, item) {
+			warnings = append(warnings, item)
+		}
+	}
+	return warnings
+}
+
+// printWarnings writes the warnings as a list, or nothing if there are none.
+func printWarnings(w io.Writer, warnings []string) {
+	if len(warnings) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nWarnings:\n")
+	for _, warning := range warnings {
+		fmt.Fprintf(w, "- %s\n", warning)
+	}
+}
+
+// searchCaveats implements caveatReporter for GitLab.
+func (g *GitLabSearcher) searchCaveats() []string {
+	caveats := []string{"languages are not in search results; use -enrich=languages or -service gitlab-graphql"}
+	if !g.UpdatedSince.IsZero() && (g.Group != "" || g.Scope != "" && g.Scope != "projects") {
+		caveats = append(caveats, "the search API can't filter by activity, so -since-last-run and -active-within are applied after fetching")
+	}
+	return caveats
+}
+
+// searchCaveats implements caveatReporter for GitLab GraphQL.
+func (g *GitLabGraphQLSearcher) searchCaveats() []string {
+	caveats := []string{"licenses and fork parents are not in GraphQL search results"}
+	if !g.UpdatedSince.IsZero() {
+		caveats = append(caveats, "GraphQL can't filter by activity, so -since-last-run and -active-within are applied after fetching")
+	}
+	return caveats
+}
+
+// searchCaveats implements caveatReporter for Bitbucket.
+func (b *BitbucketSearcher) searchCaveats() []string {
+	caveats := []string{"stars, forks, open issues, topics, licenses and archived status are not available"}
+	if !b.UpdatedSince.IsZero() {
+		caveats = append(caveats, "-since-last-run and -active-within are applied after fetching")
+	}
+	return caveats
+}
+
+// searchCaveats implements caveatReporter for GitCode.
+func (g *GitCodeSearcher) searchCaveats() []string {
+	if !g.UpdatedSince.IsZero() {
+		return []string{"-since-last-run and -active-within are applied after fetching"}
+	}
+	return nil
+}
+
+// searchCaveats implements caveatReporter for Gitee.
+func (g *GiteeSearcher) searchCaveats() []string {
+	var caveats []string
+	if g.Org != "" || g.Enterprise != "" {
+		caveats = append(caveats, "organization listings are matched against the query locally, so -pages limits the listing, not the matches")
+	}
+	if !g.UpdatedSince.IsZero() {
+		caveats = append(caveats, "-since-last-run and -active-within are applied after fetching")
+	}
+	return caveats
+}
+
+// searchCaveats implements caveatReporter for Codeberg.
+func (c *CodebergSearcher) searchCaveats() []string {
+	var caveats []string
+	if c.UsesLicense {
+		caveats = append(caveats, "Forgejo doesn't report licenses, so only Gitea instances have them")
+	}
+	if !c.UpdatedSince.IsZero() {
+		caveats = append(caveats, "-since-last-run and -active-within are applied after fetching")
+	}
+	return caveats
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// --- Watch List ---
+
+// watchFile is the store document holding the watch list.
+const watchFile = "watch.json"
+
+// watchEntry is a watched repository and what was known at the last refresh.
+type watchEntry struct {
+	Service  string `json:"service"`
+	FullName string `json:"full_name"`
+	// ETag and LastModified validate the last response, so a refresh of an
+	// unchanged repository is answered with 304 Not Modified.
+	ETag         string             `json:"etag,omitempty"`
+	LastModified string             `json:"last_modified,omitempty"`
+	Summary      *RepositorySummary `json:"summary,omitempty"`
+	CheckedAt    time.Time          `json:"checked_at,omitempty"`
+	ChangedAt    time.Time          `json:"changed_at,omitempty"`
+}
+
+// loadWatchList reads the watch list from the local store.
+func loadWatchList() ([]watchEntry, error) {
+	var entries []watchEntry
+	if err := readStoreJSON(watchFile, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// runWatch implements `rexplorer watch add|remove|list|refresh`.
+func runWatch(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: rexplorer watch <add|remove|list|refresh|badges> [args]")
+	}
+	entries, err := loadWatchList()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "add":
+		fs := flag.NewFlagSet("watch add", flag.ExitOnError)
+		service := fs.String("service", "github", "Service the repositories live on")
+		names, err := parseInterspersed(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("usage: rexplorer watch add [-service name] <full_name>...")
+		}
+		if _, ok := lookupProvider(*service); !ok && !strings.HasPrefix(*service, "fixture:") {
+			return fmt.Errorf("unknown service %q", *service)
+		}
+		for _, name := range names {
+			if findWatch(entries, *service, name) < 0 {
+				entries = append(entries, watchEntry{Service: *service, FullName: name})
+			}
+		}
+		return writeStoreJSON(watchFile, entries)
+
+	case "remove":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: rexplorer watch remove <full_name>")
+		}
+		kept := entries[:0]
+		for _, e := range entries {
+			if !strings.EqualFold(e.FullName, args[1]) {
+				kept = append(kept, e)
+			}
+		}
+		if len(kept) == len(entries) {
+			return fmt.Errorf("%s is not watched", args[1])
+		}
+		return writeStoreJSON(watchFile, kept)
+
+	case "list":
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "SERVICE\tREPOSITORY\tSTARS\tCHECKED\tCHANGED")
+		for _, e := range entries {
+			stars := "-"
+			if e.Summary != nil {
+				stars = formatCount(e.Summary.Stars, false)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.Service, e.FullName, stars, formatTime(e.CheckedAt), formatTime(e.ChangedAt))
+		}
+		return w.Flush()
+
+	case "refresh":
+		fs := flag.NewFlagSet("watch refresh", flag.ExitOnError)
+		timeout := fs.Duration("timeout", 10*time.Minute, "Timeout for the whole refresh")
+		fs.Parse(args[1:])
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+		refreshWatchList(ctx, entries, time.Now().UTC())
+		return writeStoreJSON(watchFile, entries)
+
+	case "badges":
+		return runWatchBadges(args[1:], entries)
+
+	default:
+		return fmt.Errorf("unknown watch command %q (want add, remove, list, refresh or badges)", args[0])
+	}
+}
+
+// findWatch returns the index of a watched repository, or -1.
+func findWatch(entries []watchEntry, service, fullName string) int {
+	for i, e := range entries {
+		if strings.EqualFold(e.Service, service) && strings.EqualFold(e.FullName, fullName) {
+			return i
+		}
+	}
+	return -1
+}
+
+// refreshWatchList looks up every watched repository with a conditional
+// request, updating entries in place and printing what changed.
+func refreshWatchList(ctx context.Context, entries []watchEntry, now time.Time) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	searchers := make(map[string]*BaseRepoSearcher)
+	known, err := loadRenames()
+	if err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	unchanged, changed, failed := 0, 0, 0
+	for i := range entries {
+		e := &entries[i]
+		if current := known.resolve(e.Service, e.FullName); current != e.FullName {
+			fmt.Printf("%s: renamed to %s\n", e.FullName, current)
+			e.FullName, e.ETag, e.LastModified = current, "", ""
+		}
+		b, ok := searchers[e.Service]
+		if !ok {
+			searcher, err := newSearcher(e.Service, client, false)
+			if err != nil {
+				log.Printf("Warning: skipping %s: %v", e.FullName, err)
+				failed++
+				continue
+			}
+			b, _ = baseOf(searcher)
+			searchers[e.Service] = b
+		}
+
+		detail, err := b.fetchRepo(ctx, e.FullName, e.ETag, e.LastModified)
+		if err != nil {
+			log.Printf("Warning: failed to refresh %s: %v", e.FullName, err)
+			failed++
+			continue
+		}
+		e.CheckedAt = now
+		e.ETag, e.LastModified = detail.ETag, detail.LastModified
+		if detail.Summary.PreviousFullName != "" {
+			noteRename(e.Service, e.FullName, detail.Summary, now)
+			fmt.Printf("%s: renamed to %s\n", e.FullName, detail.Summary.FullName)
+			e.FullName = detail.Summary.FullName
+			e.ChangedAt = now
+		}
+		if detail.NotModified {
+			unchanged++
+			continue
+		}
+
+		if e.Summary != nil {
+			if diffs := diffSummaries(*e.Summary, detail.Summary); len(diffs) > 0 {
+				fmt.Printf("%s: %s\n", e.FullName, strings.Join(diffs, ", "))
+				e.ChangedAt = now
+				changed++
+			} else {
+				unchanged++
+			}
+		} else {
+			e.ChangedAt = now
+			changed++
+		}
+		summary := detail.Summary
+		e.Summary = &summary
+	}
+	log.Printf("Refreshed %d repositories: %d changed, %d unchanged, %d failed.", len(entries), changed, unchanged, failed)
+}
+
+// diffSummaries describes what changed between two snapshots of a
+// repository, e.g. "stars 10 -> 12".
+func diffSummaries(old, cur RepositorySummary) []string {
+	var diffs []string
+	for _, f := range []struct {
+		name     string
+		old, cur any
+	}{
+		{"stars", old.Stars, cur.Stars},
+		{"forks", old.Forks, cur.Forks},
+		{"open issues", old.OpenIssuesCount, cur.OpenIssuesCount},
+		{"language", old.Language, cur.Language},
+		{"license", old.License, cur.License},
+		{"archived", old.IsArchived, cur.IsArchived},
+		{"updated", old.UpdatedAt, cur.UpdatedAt},
+		{"description", old.Description, cur.Description},
+	} {
+		if f.old != f.cur {
+			diffs = append(diffs, fmt.Sprintf("%s %v -> %v", f.name, f.old, f.cur))
+		}
+	}
+	return diffs
+}
+
+// formatTime renders a timestamp for tables, or "-" if unset.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// --- Result Webhooks ---
+//
+// A saved search with a webhook posts its results after every run that
+// changed them: the full snapshot, and an RFC 6902 JSON Patch that turns
+// the previous run's items into the new ones, so an integration can update
+// its own copy with minimal writes. After a restart the previous items are
+// unknown; the first delivery is then marked as a baseline and has no patch.
+
+// webhookAttempts is how many times a delivery is tried.
+const webhookAttempts = 3
+
+// webhookPayload is the body posted to a webhook.
+type webhookPayload struct {
+	Tenant  string    `json:"tenant"`
+	Search  string    `json:"search"`
+	Service string    `json:"service"`
+	Query   string    `json:"query"`
+	RanAt   time.Time `json:"ran_at"`
+	// Baseline is set when there was no previous run to compare with;
+	// receivers should replace their copy with Items.
+	Baseline      bool       `json:"baseline,omitempty"`
+	PreviousRanAt *time.Time `json:"previous_ran_at,omitempty"`
+	// Patch applies to the previous run's items (a JSON array).
+	Patch []jsonPatchOp       `json:"patch,omitempty"`
+	Items []RepositorySummary `json:"items"`
+}
+
+// jsonPatchOp is one RFC 6902 operation.
+type jsonPatchOp struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value,omitempty"`
+}
+
+// itemKey identifies a repository across runs.
+func itemKey(s RepositorySummary) string {
+	return strings.ToLower(s.Provider + "\n" + s.FullName)
+}
+
+// diffCellLimit bounds the LCS table diffItems builds for the items that
+// changed between runs; above it, those items are removed and added whole.
+const diffCellLimit = 1 << 20
+
+// diffItems returns the JSON Patch turning old into cur. Repositories kept
+// in the same relative order are patched field by field; the others are
+// removed and added.
+func diffItems(old, cur []RepositorySummary) ([]jsonPatchOp, error) {
+	n, m := len(old), len(cur)
+	oldKeys := make([]string, n)
+	for i, s := range old {
+		oldKeys[i] = itemKey(s)
+	}
+	curKeys := make([]string, m)
+	for j, s := range cur {
+		curKeys[j] = itemKey(s)
+	}
+	keptOld := make([]bool, n)
+	match := make(map[int]int) // Index in cur to index in old
+	keep := func(i, j int) { keptOld[i], match[j] = true, i }
+
+	// Runs usually differ in a few places: the common prefix and suffix
+	// stay in place without building a table for them.
+	pre := 0
+	for pre < n && pre < m && oldKeys[pre] == curKeys[pre] {
+		keep(pre, pre)
+		pre++
+	}
+	suf := 0
+	for suf < n-pre && suf < m-pre && oldKeys[n-1-suf] == curKeys[m-1-suf] {
+		keep(n-1-suf, m-1-suf)
+		suf++
+	}
+
+	// The longest common subsequence of the keys in between is what stays
+	// in place too, unless its table would be too large.
+	a, b := oldKeys[pre:n-suf], curKeys[pre:m-suf]
+	if len(a)*len(b) <= diffCellLimit {
+		lcs := make([][]int, len(a)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(b)+1)
+		}
+		for i := len(a) - 1; i >= 0; i-- {
+			for j := len(b) - 1; j >= 0; j-- {
+				if a[i] == b[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+		for i, j := 0, 0; i < len(a) && j < len(b); {
+			switch {
+			case a[i] == b[j]:
+				keep(pre+i, pre+j)
+				i++
+				j++
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				i++
+			default:
+				j++
+			}
+		}
+	}
+
+	// Remove from the end so earlier indices stay valid; what is left is
+	// the kept items in order, and adding the new ones at their final
+	// positions, front to back, produces cur.
+	var ops []jsonPatchOp
+	for i := n - 1; i >= 0; i-- {
+		if !keptOld[i] {
+			ops = append(ops, jsonPatchOp{Op: "remove", Path: "/" + strconv.Itoa(i)})
+		}
+	}
+	for j := range cur {
+		i, kept := match[j]
+		if !kept {
+			ops = append(ops, jsonPatchOp{Op: "add", Path: "/" + strconv.Itoa(j), Value: cur[j]})
+			continue
+		}
+		fieldOps, err := diffFields(old[i], cur[j], "/"+strconv.Itoa(j))
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, fieldOps...)
+	}
+	return ops, nil
+}
+
+// diffFields returns the operations turning one repository's JSON object
+// into another's, at path.
+func diffFields(old, cur RepositorySummary, path string) ([]jsonPatchOp, error) {
+	a, err := jsonFields(old)
+	if err != nil {
+		return nil, err
+	}
+	b, err := jsonFields(cur)
+	if err != nil {
+		return nil, err
+	}
+	var ops []jsonPatchOp
+	for _, name := range slices.Sorted(maps.Keys(a)) {
+		if _, ok := b[name]; !ok {
+			ops = append(ops, jsonPatchOp{Op: "remove", Path: path + "/" + escapePointer(name)})
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(b)) {
+		prev, ok := a[name]
+		switch {
+		case !ok:
+			ops = append(ops, jsonPatchOp{Op: "add", Path: path + "/" + escapePointer(name), Value: b[name]})
+		case !bytes.Equal(prev, b[name]):
+			ops = append(ops, jsonPatchOp{Op: "replace", Path: path + "/" + escapePointer(name), Value: b[name]})
+		}
+	}
+	return ops, nil
+}
+
+// jsonFields returns a repository's JSON fields, as encoded in the output.
+func jsonFields(s RepositorySummary) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s: %w", s.FullName, err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %w", s.FullName, err)
+	}
+	return fields, nil
+}
+
+// escapePointer escapes a JSON Pointer reference token (RFC 6901).
+func escapePointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+// notifyWebhook posts a saved search's run to its webhook, if its items
+// changed since prev (nil if unknown).
+func (s *server) notifyWebhook(t *tenant, name string, search SavedSearch, prev, run *savedRun) {
+	payload := webhookPayload{
+		Tenant: t.Name, Search: name, Service: search.Service, Query: search.Query,
+		RanAt: run.RanAt, Items: run.Result.Items,
+	}
+	if prev == nil || prev.Result == nil {
+		payload.Baseline = true
+	} else {
+		patch, err := diffItems(prev.Result.Items, run.Result.Items)
+		if err != nil {
+			log.Printf("Warning: %s: webhook for %q: %v", t.Name, name, err)
+			return
+		}
+		if len(patch) == 0 {
+			return
+		}
+		payload.Patch, payload.PreviousRanAt = patch, &prev.RanAt
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Warning: %s: webhook for %q: failed to marshal payload: %v", t.Name, name, err)
+		return
+	}
+
+	delay := time.Second
+	for attempt := 1; ; attempt++ {
+		err = s.postWebhook(search, body)
+		if err == nil {
+			return
+		}
+		if attempt == webhookAttempts {
+			break
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+	log.Printf("Warning: %s: webhook for %q failed after %d attempts: %v", t.Name, name, webhookAttempts, err)
+}
+
+// postWebhook sends one delivery. With WebhookSecretEnv set, the body is
+// signed with HMAC-SHA256 in X-Rexplorer-Signature ("sha256=<hex>").
+func (s *server) postWebhook(search SavedSearch, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, search.Webhook, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if search.WebhookSecretEnv != "" {
+		mac := hmac.New(sha256.New, []byte(os.Getenv(search.WebhookSecretEnv)))
+		mac.Write(body)
+		req.Header.Set("X-Rexplorer-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return redactError(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// applyPatch applies the operations diffItems emits to items.
+func applyPatch(t *testing.T, items []RepositorySummary, ops []jsonPatchOp) []any {
+	t.Helper()
+	var doc []any
+	data, _ := json.Marshal(items)
+	json.Unmarshal(data, &doc)
+	for _, op := range ops {
+		var value any
+		data, _ := json.Marshal(op.Value)
+		json.Unmarshal(data, &value)
+		parts := strings.Split(op.Path, "/")[1:]
+		i, _ := strconv.Atoi(parts[0])
+		if len(parts) == 2 {
+			obj := doc[i].(map[string]any)
+			if op.Op == "remove" {
+				delete(obj, parts[1])
+			} else {
+				obj[parts[1]] = value
+			}
+			continue
+		}
+		switch op.Op {
+		case "remove":
+			doc = append(doc[:i], doc[i+1:]...)
+		case "add":
+			doc = append(doc[:i], append([]any{value}, doc[i:]...)...)
+		default:
+			t.Fatalf("unexpected operation %s %s", op.Op, op.Path)
+		}
+	}
+	return doc
+}
+
+func testItems(names ...int) []RepositorySummary {
+	items := make([]RepositorySummary, len(names))
+	for i, n := range names {
+		items[i] = RepositorySummary{Provider: "github", FullName: fmt.Sprintf("o/r%d", n), Stars: n}
+	}
+	return items
+}
+
+func checkPatch(t *testing.T, old, cur []RepositorySummary) []jsonPatchOp {
+	t.Helper()
+	ops, err := diffItems(old, cur)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []any
+	data, _ := json.Marshal(cur)
+	json.Unmarshal(data, &want)
+	if got := applyPatch(t, old, ops); !reflect.DeepEqual(got, want) {
+		t.Errorf("patched items differ from the current ones")
+	}
+	return ops
+}
+
+func TestDiffItemsPatchesChanges(t *testing.T) {
+	old := testItems(1, 2, 3, 4, 5, 6)
+	cur := testItems(1, 3, 7, 4, 2, 6)
+	cur[0].Stars = 10
+	checkPatch(t, old, cur)
+
+	if ops := checkPatch(t, old, old); len(ops) != 0 {
+		t.Errorf("unchanged items gave %d operations", len(ops))
+	}
+}
+
+func TestDiffItemsLargeChange(t *testing.T) {
+	// The middle is too large for an LCS table; it is replaced whole, but
+	// the unchanged ends are still kept.
+	var oldNames, curNames []int
+	for i := range 2000 {
+		oldNames = append(oldNames, i)
+		curNames = append(curNames, 1999-i)
+	}
+	old := testItems(append(append([]int{-1}, oldNames...), -2)...)
+	cur := testItems(append(append([]int{-1}, curNames...), -2)...)
+	ops := checkPatch(t, old, cur)
+	if want := 2 * 2000; len(ops) != want {
+		t.Errorf("got %d operations, want %d", len(ops), want)
+	}
+}
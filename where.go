@@ -0,0 +1,251 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// --- Field Expressions ---
+//
+// `-where "forks>=10" -where "open_issues_count<200"` filters on any field
+// of the JSON output, by its JSON name; nested fields are reached with dots
+// (package.monthly_downloads). Numbers compare numerically, timestamps as
+// times ("updated_at>=2024-01-01"), and other text case-insensitively; =~
+// matches a regular expression, and on a list (topics) = and =~ match any
+// element. A field that is missing, such as an enrichment that wasn't run,
+// only satisfies !=.
+
+// whereOps are the comparison operators. The first one in an expression
+// splits it, the longer one if two start at the same place (>= over >).
+var whereOps = []string{">=", "<=", "!=", "==", "=~", ">", "<", "="}
+
+// whereExpr is one parsed -where expression.
+type whereExpr struct {
+	Field string
+	Op    string
+	Value string
+	path  []int          // Field indices from RepositorySummary down
+	kind  reflect.Kind   // Of the field, or of its elements for a list
+	list  bool           // Whether the field is a list
+	num   float64        // Value, for numeric fields
+	re    *regexp.Regexp // Value, for =~
+}
+
+// whereList is the -where flag; it can be repeated, and every expression
+// must hold.
+type whereList []whereExpr
+
+// String implements flag.Value.
+func (l *whereList) String() string {
+	parts := make([]string, len(*l))
+	for i, e := range *l {
+		parts[i] = e.Field + e.Op + e.Value
+	}
+	return strings.Join(parts, " ")
+}
+
+// Set implements flag.Value.
+func (l *whereList) Set(value string) error {
+	e, err := parseWhere(value)
+	if err != nil {
+		return err
+	}
+	*l = append(*l, e)
+	return nil
+}
+
+// parseWhere parses and checks an expression against the summary schema.
+func parseWhere(s string) (whereExpr, error) {
+	var e whereExpr
+	at := -1
+	for _, op := range whereOps {
+		if i := strings.Index(s, op); i > 0 && (at < 0 || i < at || i == at && len(op) > len(e.Op)) {
+			at, e.Op = i, op
+		}
+	}
+	if at < 0 {
+		return e, fmt.Errorf("invalid expression %q: expected field, an operator (%s) and a value", s, strings.Join(whereOps, " "))
+	}
+	e.Field = strings.TrimSpace(s[:at])
+	e.Value = strings.Trim(strings.TrimSpace(s[at+len(e.Op):]), `"'`)
+	if e.Op == "==" {
+		e.Op = "="
+	}
+
+	typ := reflect.TypeOf(RepositorySummary{})
+	for _, name := range strings.Split(e.Field, ".") {
+		for typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+		f, ok := reflect.StructField{}, false
+		if typ.Kind() == reflect.Struct {
+			f, ok = jsonField(typ, name)
+		}
+		if !ok {
+			return e, fmt.Errorf("invalid expression %q: unknown field %q", s, name)
+		}
+		e.path = append(e.path, f.Index...)
+		typ = f.Type
+	}
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() == reflect.Slice {
+		e.list, typ = true, typ.Elem()
+	}
+	e.kind = typ.Kind()
+
+	switch {
+	case e.Op == "=~":
+		re, err := regexp.Compile("(?i)" + e.Value)
+		if err != nil {
+			return e, fmt.Errorf("invalid expression %q: %w", s, err)
+		}
+		e.re = re
+	case e.kind == reflect.Struct || e.kind == reflect.Map:
+		return e, fmt.Errorf("invalid expression %q: %s can't be compared; pick one of its fields", s, e.Field)
+	case e.list && e.Op != "=" && e.Op != "!=":
+		return e, fmt.Errorf("invalid expression %q: %s is a list; use =, != or =~", s, e.Field)
+	case e.kind == reflect.Bool:
+		if _, err := strconv.ParseBool(e.Value); err != nil || e.Op != "=" && e.Op != "!=" {
+			return e, fmt.Errorf("invalid expression %q: %s is true or false; use = or !=", s, e.Field)
+		}
+	case isNumericKind(e.kind):
+		n, err := strconv.ParseFloat(e.Value, 64)
+		if err != nil {
+			return e, fmt.Errorf("invalid expression %q: %s is a number", s, e.Field)
+		}
+		e.num = n
+	}
+	return e, nil
+}
+
+// jsonField finds the struct field encoded under name.
+func jsonField(typ reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		tag, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if tag == name || tag == "" && strings.EqualFold(f.Name, name) {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// isNumericKind reports whether values of kind compare as numbers.
+func isNumericKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// match reports whether the expression holds for s.
+func (e whereExpr) match(s RepositorySummary) bool {
+	v := reflect.ValueOf(s)
+	for _, i := range e.path {
+		for v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return e.Op == "!="
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return e.Op == "!="
+		}
+		v = v.Elem()
+	}
+	if !e.list {
+		return e.compare(v)
+	}
+	// A list matches = and =~ if any element does, and != if none is equal.
+	for i := 0; i < v.Len(); i++ {
+		if e.Op == "!=" {
+			if !e.compare(v.Index(i)) {
+				return false
+			}
+		} else if e.compare(v.Index(i)) {
+			return true
+		}
+	}
+	return e.Op == "!="
+}
+
+// compare applies the operator to one value.
+func (e whereExpr) compare(v reflect.Value) bool {
+	if e.re != nil {
+		return e.re.MatchString(fmt.Sprint(v.Interface()))
+	}
+	var c int
+	switch {
+	case v.Kind() == reflect.Bool:
+		if want, _ := strconv.ParseBool(e.Value); v.Bool() != want {
+			c = 1
+		}
+	case isNumericKind(v.Kind()):
+		n, _ := strconv.ParseFloat(fmt.Sprint(v.Interface()), 64)
+		c = cmp.Compare(n, e.num)
+	default:
+		c = compareText(v.String(), e.Value)
+	}
+	switch e.Op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	}
+	return false
+}
+
+// compareText compares timestamps as times and other text ignoring case.
+func compareText(a, b string) int {
+	ta, okA := parseTimestamp(a)
+	tb, okB := parseTimestamp(b)
+	if okA && okB {
+		return ta.Compare(tb)
+	}
+	if strings.EqualFold(a, b) {
+		return 0
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// matchAll reports whether every expression holds for s.
+func (l whereList) matchAll(s RepositorySummary) bool {
+	for _, e := range l {
+		if !e.match(s) {
+			return false
+		}
+	}
+	return true
+}
+
+// highlights returns the regular expressions matched against the names and
+// descriptions, for the console highlighter.
+func (l whereList) highlights() []*regexp.Regexp {
+	var res []*regexp.Regexp
+	for _, e := range l {
+		if e.re != nil && (e.Field == "name" || e.Field == "full_name" || e.Field == "description") {
+			res = append(res, e.re)
+		}
+	}
+	return res
+}
@@ -0,0 +1,96 @@
+package main
+
+import "testing"
+
+func TestParseWhereOperators(t *testing.T) {
+	for _, tc := range []struct{ expr, field, op, value string }{
+		{"forks>=10", "forks", ">=", "10"},
+		{"forks>10", "forks", ">", "10"},
+		{"forks <= 10", "forks", "<=", "10"},
+		{"language!=Go", "language", "!=", "Go"},
+		{"language==Go", "language", "=", "Go"},
+		{"name=~^go-", "name", "=~", "^go-"},
+		{`description="a=b>=c"`, "description", "=", "a=b>=c"},
+		{"package.monthly_downloads>1000", "package.monthly_downloads", ">", "1000"},
+	} {
+		e, err := parseWhere(tc.expr)
+		if err != nil {
+			t.Errorf("%s: %v", tc.expr, err)
+			continue
+		}
+		if e.Field != tc.field || e.Op != tc.op || e.Value != tc.value {
+			t.Errorf("%s: parsed %q %q %q, want %q %q %q", tc.expr, e.Field, e.Op, e.Value, tc.field, tc.op, tc.value)
+		}
+	}
+}
+
+func TestParseWhereRejects(t *testing.T) {
+	for _, expr := range []string{
+		"forks",          // No operator
+		">=10",           // No field
+		"nope=1",         // Unknown field
+		"package.nope=1", // Unknown nested field
+		"forks>=many",    // Not a number
+		"is_fork=maybe",  // Not a bool
+		"is_fork>true",   // Bools only compare for equality
+		"topics>cli",     // Lists only compare for equality
+		"package=x",      // A struct, not a value
+		"name=~(",        // Bad regular expression
+	} {
+		if _, err := parseWhere(expr); err == nil {
+			t.Errorf("%s: parsed, want an error", expr)
+		}
+	}
+}
+
+func TestWhereMatch(t *testing.T) {
+	repo := RepositorySummary{
+		Name: "go-cli", Forks: 10, Language: "Go", IsFork: true,
+		Topics:    []string{"cli", "TUI"},
+		UpdatedAt: "2024-03-01T12:00:00Z",
+		Package:   &PackageInfo{Registry: "npm", MonthlyDownloads: 5000},
+	}
+	bare := RepositorySummary{Name: "bare"}
+	for _, tc := range []struct {
+		expr string
+		repo RepositorySummary
+		want bool
+	}{
+		{"forks>=10", repo, true},
+		{"forks>10", repo, false},
+		{"forks<10.5", repo, true},
+		{"language=go", repo, true}, // Text ignores case
+		{"language!=Go", repo, false},
+		{"name=~^GO-", repo, true},
+		{"is_fork=true", repo, true},
+		{"is_fork!=true", repo, false},
+		{"is_fork=false", bare, true},
+		// Lists: = and =~ match any element, != holds if none is equal.
+		{"topics=tui", repo, true},
+		{"topics=web", repo, false},
+		{"topics!=cli", repo, false},
+		{"topics!=web", repo, true},
+		{"topics=~^c", repo, true},
+		{"topics=cli", bare, false},
+		{"topics!=cli", bare, true},
+		// Timestamps compare as times, whatever their layout.
+		{"updated_at>=2024-01-01", repo, true},
+		{"updated_at<2024-03-01", repo, false},
+		{"updated_at>2024-03-01T11:00:00+00:00", repo, true},
+		// A nil pointer is a missing field: only != holds.
+		{"package.monthly_downloads>1000", repo, true},
+		{"package.monthly_downloads>1000", bare, false},
+		{"package.monthly_downloads<1000", bare, false},
+		{"package.monthly_downloads!=1000", bare, true},
+		{"package.registry=npm", bare, false},
+	} {
+		e, err := parseWhere(tc.expr)
+		if err != nil {
+			t.Errorf("%s: %v", tc.expr, err)
+			continue
+		}
+		if got := e.match(tc.repo); got != tc.want {
+			t.Errorf("%s on %s = %v, want %v", tc.expr, tc.repo.Name, got, tc.want)
+		}
+	}
+}